@@ -0,0 +1,83 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultModelCatalog lists the models known for each provider. Providers
+// without an entry, such as bedrock and passthrough, accept any model.
+var defaultModelCatalog = map[string][]string{
+	"gemini": {
+		"gemini-pro", "gemini-1.0-pro",
+		"gemini-1.5-pro", "gemini-1.5-flash", "gemini-1.5-flash-8b",
+		"gemini-2.0-flash", "gemini-2.0-flash-lite",
+		"gemini-2.5-pro", "gemini-2.5-flash", "gemini-2.5-flash-lite",
+	},
+	"openai": {
+		"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o", "gpt-4o-mini",
+		"gpt-4.1", "gpt-4.1-mini", "gpt-4.1-nano", "o1", "o1-mini", "o3", "o3-mini", "o4-mini",
+	},
+	"anthropic": {
+		"claude-3-haiku", "claude-3-sonnet", "claude-3-opus",
+		"claude-3-5-haiku", "claude-3-5-sonnet", "claude-3-7-sonnet",
+		"claude-sonnet-4", "claude-opus-4",
+	},
+}
+
+var modelCatalog = struct {
+	sync.RWMutex
+	models map[string][]string
+}{models: defaultModelCatalog}
+
+// SetKnownModels replaces the catalog entry for a provider, e.g. with a list
+// refreshed from the provider's model listing API. An empty list removes the
+// entry so any model is accepted.
+func SetKnownModels(provider string, models []string) {
+	modelCatalog.Lock()
+	defer modelCatalog.Unlock()
+
+	updated := make(map[string][]string, len(modelCatalog.models)+1)
+	for p, m := range modelCatalog.models {
+		updated[p] = m
+	}
+	if len(models) == 0 {
+		delete(updated, provider)
+	} else {
+		updated[provider] = append([]string(nil), models...)
+	}
+	modelCatalog.models = updated
+}
+
+// KnownModels returns the sorted catalog entry for a provider, or nil when the provider has none
+func KnownModels(provider string) []string {
+	modelCatalog.RLock()
+	defer modelCatalog.RUnlock()
+
+	models := append([]string(nil), modelCatalog.models[provider]...)
+	if len(models) == 0 {
+		return nil
+	}
+	sort.Strings(models)
+	return models
+}
+
+// IsKnownModel reports whether the provider's catalog lists the model. Pinned
+// versions of a listed model (gemini-1.5-pro-002, claude-3-opus-20240229,
+// text-bison@001) count as known. Providers without a catalog accept any model.
+func IsKnownModel(provider, model string) bool {
+	modelCatalog.RLock()
+	defer modelCatalog.RUnlock()
+
+	models, ok := modelCatalog.models[provider]
+	if !ok {
+		return true
+	}
+	for _, known := range models {
+		if model == known || strings.HasPrefix(model, known+"-") || strings.HasPrefix(model, known+"@") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsKnownModel(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		want     bool
+	}{
+		{"gemini", "gemini-1.5-pro", true},
+		{"gemini", "gemini-1.5-pro-002", true},
+		{"gemini", "gemini-1.5-prooo", false},
+		{"anthropic", "claude-3-opus-20240229", true},
+		{"openai", "gpt-4o-mini", true},
+		{"openai", "gpt-5o", false},
+		{"bedrock", "anything", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsKnownModel(tt.provider, tt.model); got != tt.want {
+			t.Errorf("Expected IsKnownModel(%s, %s) = %v, got %v", tt.provider, tt.model, tt.want, got)
+		}
+	}
+}
+
+func TestValidateWithWarnings_UnknownModel(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Evaluation.Model = "gemini-1.5-prooo"
+
+	warnings, err := NewValidator().ValidateWithWarnings(cfg)
+	if err != nil {
+		t.Fatalf("Expected unknown model to be non-fatal, got %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "model gemini-1.5-prooo is not in the gemini model catalog") {
+		t.Errorf("Expected unknown model warning, got %v", warnings)
+	}
+
+	validator := NewValidator()
+	validator.SetStrictModels(true)
+	if err := validator.Validate(cfg); err == nil || !strings.Contains(err.Error(), "unknown gemini model") {
+		t.Errorf("Expected strict validation to reject the model, got %v", err)
+	}
+}
+
+func TestSetKnownModels(t *testing.T) {
+	original := KnownModels("gemini")
+	t.Cleanup(func() { SetKnownModels("gemini", original) })
+
+	SetKnownModels("gemini", []string{"gemini-3.0-ultra"})
+	if !IsKnownModel("gemini", "gemini-3.0-ultra") {
+		t.Error("Expected refreshed model to be known")
+	}
+	if IsKnownModel("gemini", "gemini-1.5-pro") {
+		t.Error("Expected refresh to replace the previous models")
+	}
+
+	SetKnownModels("gemini", nil)
+	if !IsKnownModel("gemini", "gemini-1.5-prooo") {
+		t.Error("Expected a provider without a catalog to accept any model")
+	}
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Compatibility issue kinds
+const (
+	IssueTypeMismatch = "type_mismatch" // the value's type differs from the output schema type
+	IssueUnmapped     = "unmapped"      // nothing produces a declared output field
+	IssueMissingInput = "missing_input" // a mappings.input path names a field the input schema lacks
+)
+
+// FieldTrace follows one field from where its value comes from to where it is written
+type FieldTrace struct {
+	Input      string // input ID
+	Output     string // output ID; empty for mappings.input traces
+	Field      string // output field, or template variable for mappings.input traces
+	Source     string // input.<field>, an evaluator output path such as $.label, or stamp.<field>
+	SourceType string // type of the source value; empty when only known at run time
+	TargetType string // declared type of the field it is written to
+	Issue      string // one of the Issue kinds, or empty when compatible
+	Message    string // describes the issue
+}
+
+// CompatibilityResult holds the traces of a CompatibilityReport, in config order
+type CompatibilityResult struct {
+	Traces []FieldTrace
+}
+
+// Issues returns the traces that report an issue
+func (r *CompatibilityResult) Issues() []FieldTrace {
+	var issues []FieldTrace
+	for _, trace := range r.Traces {
+		if trace.Issue != "" {
+			issues = append(issues, trace)
+		}
+	}
+	return issues
+}
+
+// CompatibilityReport traces, for every input and each output it is routed to,
+// how each output schema field is produced: through an output mapping into the
+// evaluator output, by passing the input field through, or by a stamp. Each
+// trace carries the source and target types, and reports a likely type
+// mismatch or an output field nothing produces. mappings.input paths are
+// checked against the input schema. Unlike Validate, it collects every
+// finding instead of stopping at the first.
+func CompatibilityReport(cfg *Config) *CompatibilityResult {
+	result := &CompatibilityResult{}
+	if cfg == nil {
+		return result
+	}
+
+	for _, input := range cfg.Inputs {
+		eval := cfg.EvaluationFor(input)
+		result.Traces = append(result.Traces, traceInputMappings(input, eval)...)
+
+		for _, output := range cfg.Outputs {
+			if routed := cfg.RoutedInput(output); routed != "" && routed != input.ID {
+				continue
+			}
+			for _, field := range output.Schema.Fields {
+				trace := traceOutputField(cfg, input, eval, output, field)
+				checkTraceTypes(&trace)
+				result.Traces = append(result.Traces, trace)
+			}
+		}
+	}
+	return result
+}
+
+// traceInputMappings checks that each mappings.input path starts at a field of the input schema
+func traceInputMappings(input InputConfig, eval EvaluationConfig) []FieldTrace {
+	variables := make([]string, 0, len(eval.Mappings.Input))
+	for variable := range eval.Mappings.Input {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+
+	var traces []FieldTrace
+	for _, variable := range variables {
+		path := strings.TrimPrefix(eval.Mappings.Input[variable], "$.")
+		name, _, _ := strings.Cut(path, ".")
+		trace := FieldTrace{Input: input.ID, Field: variable, Source: "input." + path}
+		if field, ok := schemaField(input.Schema, name); ok {
+			if name == path {
+				trace.SourceType = fieldType(input.Schema, field)
+			}
+		} else if len(input.Schema.Fields) > 0 {
+			trace.Issue = IssueMissingInput
+			trace.Message = fmt.Sprintf("mappings.input %s reads %s, which input %s does not declare", variable, name, input.ID)
+		}
+		traces = append(traces, trace)
+	}
+	return traces
+}
+
+// traceOutputField finds what produces an output field. The controller copies
+// the input fields, then applies output mappings, then stamps, so the last of
+// these that sets the field wins.
+func traceOutputField(cfg *Config, input InputConfig, eval EvaluationConfig, output OutputConfig, field FieldConfig) FieldTrace {
+	trace := FieldTrace{
+		Input:      input.ID,
+		Output:     output.ID,
+		Field:      field.Name,
+		TargetType: fieldType(output.Schema, field),
+	}
+
+	if contains(cfg.Controls.Stamp, field.Name) || field.Name == cfg.Controls.InputIDField {
+		trace.Source, trace.SourceType = "stamp."+field.Name, "string"
+		return trace
+	}
+
+	if path, ok := outputMapping(eval.Mappings.Output, output.ID, field.Name); ok {
+		trace.Source = path
+		trace.SourceType = evaluatorOutputType(input, eval, strings.TrimPrefix(path, "$."))
+		return trace
+	}
+
+	if inputField, ok := schemaField(input.Schema, field.Name); ok {
+		trace.Source, trace.SourceType = "input."+field.Name, fieldType(input.Schema, inputField)
+		return trace
+	}
+
+	// Optional fields may be left out of the written records
+	if field.Optional {
+		return trace
+	}
+	trace.Issue = IssueUnmapped
+	trace.Message = fmt.Sprintf("output %s field %s is not produced by an output mapping, an input %s field, or a stamp", output.ID, field.Name, input.ID)
+	return trace
+}
+
+// outputMapping returns the mapping path for an output field; a key scoped to the output ID wins
+func outputMapping(mappings map[string]string, outputID, field string) (string, bool) {
+	var path string
+	found := false
+	for key, value := range mappings {
+		key = strings.TrimSpace(key)
+		if key == outputID+"."+field {
+			return value, true
+		}
+		if key == field {
+			path, found = value, true
+		}
+	}
+	return path, found
+}
+
+// evaluatorOutputType returns the type of an evaluator output path, or "" when
+// it is only known at run time, such as a field of an unstructured JSON response
+func evaluatorOutputType(input InputConfig, eval EvaluationConfig, path string) string {
+	name, rest, nested := strings.Cut(path, ".")
+	switch name {
+	case "response", "_provider", "_model":
+		if !nested {
+			return "string"
+		}
+		return ""
+	case "parsed":
+		if !nested {
+			return "object"
+		}
+		name, _, nested = strings.Cut(rest, ".")
+	}
+
+	if eval.OutputSchema != nil {
+		if field, ok := schemaField(*eval.OutputSchema, name); ok {
+			if nested {
+				return ""
+			}
+			return fieldType(*eval.OutputSchema, field)
+		}
+	}
+
+	// The passthrough provider returns the record's own fields
+	if eval.Provider == "passthrough" {
+		if field, ok := schemaField(input.Schema, name); ok && !nested {
+			return fieldType(input.Schema, field)
+		}
+	}
+	return ""
+}
+
+// checkTraceTypes reports a mismatch when both types are known and cannot match.
+// Format types such as email are strings, so they are compatible with string.
+func checkTraceTypes(trace *FieldTrace) {
+	if trace.Issue != "" || trace.SourceType == "" || trace.TargetType == "" {
+		return
+	}
+	source, target := baseType(trace.SourceType), baseType(trace.TargetType)
+	if source == "" || target == "" || source == target {
+		return
+	}
+	trace.Issue = IssueTypeMismatch
+	trace.Message = fmt.Sprintf("output %s field %s is %s, but its source %s is %s for input %s",
+		trace.Output, trace.Field, trace.TargetType, trace.Source, trace.SourceType, trace.Input)
+}
+
+// baseType returns the JSON type a field type is stored as, or "" for custom types
+func baseType(fieldType string) string {
+	switch fieldType {
+	case "string", "email", "url", "uuid":
+		return "string"
+	case "integer":
+		return "number"
+	case "number", "boolean", "array", "object":
+		return fieldType
+	}
+	return ""
+}
+
+// schemaField returns the field of schema named name
+func schemaField(schema SchemaConfig, name string) (FieldConfig, bool) {
+	for _, field := range schema.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return FieldConfig{}, false
+}
+
+// fieldType returns a field's type, falling back to the schema's default_field_type
+func fieldType(schema SchemaConfig, field FieldConfig) string {
+	if field.Type != "" {
+		return field.Type
+	}
+	return schema.DefaultFieldType
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newCompatTestConfig() *Config {
+	return &Config{
+		Experiment: ExperimentConfig{Name: "compat", Version: "0.1"},
+		Inputs: []InputConfig{
+			{
+				ID:     "reviews",
+				Format: "json",
+				Schema: SchemaConfig{Fields: []FieldConfig{
+					{Name: "text", Type: "string"},
+					{Name: "rating", Type: "number"},
+					{Name: "user", Type: "object"},
+				}},
+			},
+			{
+				ID:     "tickets",
+				Format: "json",
+				Schema: SchemaConfig{Fields: []FieldConfig{{Name: "body", Type: "string"}}},
+			},
+		},
+		Outputs: []OutputConfig{
+			{
+				ID: "scored",
+				Schema: SchemaConfig{Fields: []FieldConfig{
+					{Name: "text", Type: "string"},
+					{Name: "rating", Type: "string"},
+					{Name: "label", Type: "string"},
+					{Name: "score", Type: "number"},
+					{Name: "summary", Type: "url"},
+					{Name: "model", Type: "string"},
+					{Name: "run_id", Type: "string"},
+					{Name: "confidence", Type: "number"},
+				}},
+			},
+			{
+				ID:     "tickets_triaged",
+				Schema: SchemaConfig{Fields: []FieldConfig{{Name: "body", Type: "string"}}},
+			},
+		},
+		Evaluation: EvaluationConfig{
+			Provider: "gemini",
+			Strategy: "classification",
+			Prompt:   "Review: {{review}} by {{author}}",
+			Mappings: MappingsConfig{
+				Input: map[string]string{"review": "$.text", "author": "user.name", "missing": "$.comment"},
+				Output: map[string]string{
+					"label":   "$.label",
+					"score":   "$.parsed.score",
+					"summary": "$.response",
+					"model":   "$._model",
+				},
+			},
+			OutputSchema: &SchemaConfig{Fields: []FieldConfig{
+				{Name: "label", Type: "string"},
+				{Name: "score", Type: "string"},
+			}},
+		},
+		Controls: ControlsConfig{Concurrency: 1, OnError: "skip", Stamp: []string{"run_id"}},
+	}
+}
+
+func TestCompatibilityReport_Traces(t *testing.T) {
+	report := CompatibilityReport(newCompatTestConfig())
+
+	type key struct{ input, output, field string }
+	traces := make(map[key]FieldTrace)
+	for _, trace := range report.Traces {
+		traces[key{trace.Input, trace.Output, trace.Field}] = trace
+	}
+
+	tests := []struct {
+		key        key
+		source     string
+		sourceType string
+		issue      string
+	}{
+		{key{"reviews", "", "author"}, "input.user.name", "", ""},
+		{key{"reviews", "", "missing"}, "input.comment", "", IssueMissingInput},
+		{key{"reviews", "", "review"}, "input.text", "string", ""},
+		{key{"reviews", "scored", "text"}, "input.text", "string", ""},
+		{key{"reviews", "scored", "rating"}, "input.rating", "number", IssueTypeMismatch},
+		{key{"reviews", "scored", "label"}, "$.label", "string", ""},
+		{key{"reviews", "scored", "score"}, "$.parsed.score", "string", IssueTypeMismatch},
+		{key{"reviews", "scored", "summary"}, "$.response", "string", ""},
+		{key{"reviews", "scored", "model"}, "$._model", "string", ""},
+		{key{"reviews", "scored", "run_id"}, "stamp.run_id", "string", ""},
+		{key{"reviews", "scored", "confidence"}, "", "", IssueUnmapped},
+		{key{"tickets", "scored", "text"}, "", "", IssueUnmapped},
+		{key{"tickets", "tickets_triaged", "body"}, "input.body", "string", ""},
+	}
+
+	for _, tt := range tests {
+		trace, ok := traces[tt.key]
+		if !ok {
+			t.Errorf("Expected a trace for %+v", tt.key)
+			continue
+		}
+		if trace.Source != tt.source || trace.SourceType != tt.sourceType || trace.Issue != tt.issue {
+			t.Errorf("Expected %+v from %q (%q) with issue %q, got %+v", tt.key, tt.source, tt.sourceType, tt.issue, trace)
+		}
+		if trace.Issue != "" && trace.Message == "" {
+			t.Errorf("Expected a message for the %s issue on %+v", trace.Issue, tt.key)
+		}
+	}
+
+	// tickets_triaged is routed to tickets only by its ID
+	if _, ok := traces[key{"reviews", "tickets_triaged", "body"}]; ok {
+		t.Errorf("Expected no trace of reviews into tickets_triaged")
+	}
+}
+
+func TestCompatibilityReport_Issues(t *testing.T) {
+	report := CompatibilityReport(newCompatTestConfig())
+
+	var got []string
+	for _, issue := range report.Issues() {
+		got = append(got, issue.Input+"/"+issue.Output+"/"+issue.Field+": "+issue.Issue)
+	}
+	want := []string{
+		"reviews//missing: missing_input",
+		"reviews/scored/rating: type_mismatch",
+		"reviews/scored/score: type_mismatch",
+		"reviews/scored/confidence: unmapped",
+		"tickets//author: missing_input",
+		"tickets//missing: missing_input",
+		"tickets//review: missing_input",
+		"tickets/scored/text: unmapped",
+		"tickets/scored/rating: unmapped",
+		"tickets/scored/score: type_mismatch",
+		"tickets/scored/confidence: unmapped",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected issues\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestCompatibilityReport_PassthroughAndOverrides(t *testing.T) {
+	cfg := newCompatTestConfig()
+	cfg.Evaluation.Provider = "passthrough"
+	cfg.Evaluation.OutputSchema = nil
+	cfg.Evaluation.Mappings.Input = nil
+	cfg.Evaluation.Mappings.Output = map[string]string{"rating": "$.rating", "scored.score": "$.rating"}
+	cfg.Inputs = cfg.Inputs[:1]
+
+	report := CompatibilityReport(cfg)
+	for _, trace := range report.Traces {
+		switch trace.Field {
+		case "rating":
+			// The passthrough provider returns the record, so the input type carries through
+			if trace.SourceType != "number" || trace.Issue != IssueTypeMismatch {
+				t.Errorf("Expected the number rating to mismatch the string field, got %+v", trace)
+			}
+		case "score":
+			if trace.Source != "$.rating" || trace.Issue != "" {
+				t.Errorf("Expected the output-scoped mapping to produce score, got %+v", trace)
+			}
+		}
+	}
+
+	if got := CompatibilityReport(nil); len(got.Traces) != 0 {
+		t.Errorf("Expected an empty report for a nil config, got %+v", got)
+	}
+}
+
+func TestCompatibilityReport_OptionalFields(t *testing.T) {
+	cfg := newCompatTestConfig()
+	cfg.Inputs = cfg.Inputs[:1]
+	cfg.Outputs[0].Schema.Fields[7].Optional = true // confidence
+
+	for _, trace := range CompatibilityReport(cfg).Traces {
+		if trace.Field == "confidence" && trace.Issue != "" {
+			t.Errorf("Expected the optional confidence field not to be an issue, got %+v", trace)
+		}
+	}
+}
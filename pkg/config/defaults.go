@@ -0,0 +1,62 @@
+package config
+
+import "os"
+
+// Environment variables consulted during the defaults pass
+const (
+	EnvProvider = "MEVAL_PROVIDER"
+	EnvModel    = "MEVAL_MODEL"
+)
+
+// DefaultProvider is used when neither the config nor the environment sets a provider
+const DefaultProvider = "gemini"
+
+// DefaultErrorField holds the error of failed records written with controls.include_errors_inline
+const DefaultErrorField = "_error"
+
+// ApplyDefaults fills unset configuration values.
+// Precedence is explicit config > environment override > built-in default.
+func ApplyDefaults(config *Config) {
+	if config == nil {
+		return
+	}
+
+	if config.Evaluation.Provider == "" {
+		config.Evaluation.Provider = envOrDefault(EnvProvider, DefaultProvider)
+	}
+
+	if config.Evaluation.Model == "" {
+		config.Evaluation.Model = os.Getenv(EnvModel)
+	}
+
+	for i := range config.Inputs {
+		applySchemaDefaults(&config.Inputs[i].Schema)
+		if config.Inputs[i].Evaluation != nil {
+			applySchemaDefaults(config.Inputs[i].Evaluation.OutputSchema)
+		}
+	}
+	for i := range config.Outputs {
+		applySchemaDefaults(&config.Outputs[i].Schema)
+	}
+	applySchemaDefaults(config.Evaluation.OutputSchema)
+}
+
+// applySchemaDefaults gives fields without a type the schema's default_field_type
+func applySchemaDefaults(schema *SchemaConfig) {
+	if schema == nil || schema.DefaultFieldType == "" {
+		return
+	}
+	for i := range schema.Fields {
+		if schema.Fields[i].Type == "" {
+			schema.Fields[i].Type = schema.DefaultFieldType
+		}
+	}
+}
+
+// envOrDefault returns the value of the environment variable or the fallback if unset
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
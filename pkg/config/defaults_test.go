@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyDefaults_EnvProviderOverride(t *testing.T) {
+	t.Setenv(EnvProvider, "openai")
+	t.Setenv(EnvModel, "gpt-4o-mini")
+
+	yamlContent := `experiment:
+  name: env-override
+  version: 0.1
+evaluation:
+  auth:
+    api_key_env: OPENAI_API_KEY
+  strategy: classification
+  prompt: "Text: {{text}}"
+`
+
+	config, err := NewReader().Read(strings.NewReader(yamlContent))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	if config.Evaluation.Provider != "openai" {
+		t.Errorf("Expected provider openai from env, got %s", config.Evaluation.Provider)
+	}
+
+	if config.Evaluation.Model != "gpt-4o-mini" {
+		t.Errorf("Expected model gpt-4o-mini from env, got %s", config.Evaluation.Model)
+	}
+}
+
+func TestApplyDefaults_Precedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		explicit string
+		env      string
+		expected string
+	}{
+		{"explicit wins over env", "anthropic", "openai", "anthropic"},
+		{"env wins over default", "", "openai", "openai"},
+		{"default when unset", "", "", DefaultProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvProvider, tt.env)
+
+			config := &Config{Evaluation: EvaluationConfig{Provider: tt.explicit}}
+			ApplyDefaults(config)
+
+			if config.Evaluation.Provider != tt.expected {
+				t.Errorf("Expected provider %s, got %s", tt.expected, config.Evaluation.Provider)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_DefaultFieldType(t *testing.T) {
+	yamlContent := `experiment:
+  name: default-types
+  version: 0.1
+inputs:
+  - id: reviews
+    format: json
+    config:
+      path: reviews.json
+    schema:
+      default_field_type: string
+      fields:
+        - name: text
+        - name: author
+        - name: rating
+          type: number
+`
+
+	config, err := NewReader().Read(strings.NewReader(yamlContent))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	expected := map[string]string{"text": "string", "author": "string", "rating": "number"}
+	for _, field := range config.Inputs[0].Schema.Fields {
+		if field.Type != expected[field.Name] {
+			t.Errorf("Expected field %s to have type %s, got %s", field.Name, expected[field.Name], field.Type)
+		}
+	}
+
+	// An unsupported default is rejected by the validator
+	cfg := newLintTestConfig()
+	cfg.Inputs[0].Schema.DefaultFieldType = "text"
+	if err := NewValidator().Validate(cfg); err == nil || !strings.Contains(err.Error(), "unsupported default_field_type") {
+		t.Errorf("Expected unsupported default_field_type error, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BuiltinFieldTypes are the schema field types every source understands
+var BuiltinFieldTypes = []string{"string", "number", "integer", "boolean", "array", "object", "email", "url", "uuid"}
+
+// FieldTypeFunc validates a decoded value against a custom field type
+type FieldTypeFunc func(value interface{}) error
+
+var (
+	fieldTypesMu sync.RWMutex
+	fieldTypes   = make(map[string]FieldTypeFunc)
+)
+
+// RegisterFieldType makes a custom schema field type available to the config
+// validator and to sources. Like database/sql.Register, it panics if name is
+// empty, fn is nil, or the type is already registered or built in.
+func RegisterFieldType(name string, fn FieldTypeFunc) {
+	if name == "" {
+		panic("config: RegisterFieldType name is empty")
+	}
+	if fn == nil {
+		panic("config: RegisterFieldType validator is nil for " + name)
+	}
+	if contains(BuiltinFieldTypes, name) {
+		panic("config: RegisterFieldType cannot override built-in type " + name)
+	}
+
+	fieldTypesMu.Lock()
+	defer fieldTypesMu.Unlock()
+	if _, dup := fieldTypes[name]; dup {
+		panic(fmt.Sprintf("config: RegisterFieldType called twice for %s", name))
+	}
+	fieldTypes[name] = fn
+}
+
+// LookupFieldType returns the validator for a registered custom field type
+func LookupFieldType(name string) (FieldTypeFunc, bool) {
+	fieldTypesMu.RLock()
+	defer fieldTypesMu.RUnlock()
+	fn, ok := fieldTypes[name]
+	return fn, ok
+}
+
+// IsSupportedFieldType reports whether a field type is built in or registered
+func IsSupportedFieldType(name string) bool {
+	if contains(BuiltinFieldTypes, name) {
+		return true
+	}
+	_, ok := LookupFieldType(name)
+	return ok
+}
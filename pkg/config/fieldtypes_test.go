@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var registerEvenOnce sync.Once
+
+func TestRegisterFieldType(t *testing.T) {
+	// The registry is global, so register once per test binary
+	registerEvenOnce.Do(func() {
+		RegisterFieldType("even", func(value interface{}) error {
+			n, ok := value.(float64)
+			if !ok || int(n)%2 != 0 {
+				return fmt.Errorf("expected even number, got %v", value)
+			}
+			return nil
+		})
+	})
+
+	validate, ok := LookupFieldType("even")
+	if !ok {
+		t.Fatal("Expected registered type to be found")
+	}
+	if err := validate(3.0); err == nil {
+		t.Error("Expected odd number to fail validation, got nil")
+	}
+
+	cfg := newLintTestConfig()
+	cfg.Inputs[0].Schema.Fields = append(cfg.Inputs[0].Schema.Fields, FieldConfig{Name: "count", Type: "even"})
+	if err := NewValidator().Validate(cfg); err != nil {
+		t.Errorf("Expected registered type to be supported, got %v", err)
+	}
+
+	cfg.Inputs[0].Schema.Fields[len(cfg.Inputs[0].Schema.Fields)-1].Type = "odd"
+	err := NewValidator().Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "unsupported type odd") {
+		t.Errorf("Expected unsupported type error, got %v", err)
+	}
+}
+
+func TestRegisterFieldType_Panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeName string
+		fn       FieldTypeFunc
+	}{
+		{"empty name", "", func(interface{}) error { return nil }},
+		{"nil func", "nothing", nil},
+		{"built-in", "string", func(interface{}) error { return nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic, got none")
+				}
+			}()
+			RegisterFieldType(tt.typeName, tt.fn)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// templateVariablePattern matches prompt template variables like {{field_name}}
+var templateVariablePattern = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// ValidateWithWarnings validates the configuration and also runs lint checks.
+// Lint findings are returned as warnings and never fail validation.
+func (v *Validator) ValidateWithWarnings(config *Config) ([]string, error) {
+	if err := v.Validate(config); err != nil {
+		return nil, err
+	}
+
+	return v.lint(config), nil
+}
+
+// lint reports likely mistakes such as inputs nothing consumes and outputs nothing produces
+func (v *Validator) lint(config *Config) []string {
+	var warnings []string
+
+	// References an input can be consumed through: mappings and prompt variables
+	consumed := make(map[string]bool)
+	for key, value := range config.Evaluation.Mappings.Input {
+		consumed[key] = true
+		consumed[value] = true
+	}
+	for _, name := range promptVariables(config.Evaluation.Prompt) {
+		consumed[name] = true
+	}
+
+	inputFields := make(map[string]bool)
+	for _, input := range config.Inputs {
+		used := false
+		for _, field := range input.Schema.Fields {
+			inputFields[field.Name] = true
+			if consumed[field.Name] || consumed[input.ID+"."+field.Name] {
+				used = true
+			}
+		}
+		if !used {
+			warnings = append(warnings, fmt.Sprintf("input %s is not referenced by any mapping or prompt variable", input.ID))
+		}
+	}
+
+	// Outputs are produced by output mappings or by passing input fields through
+	for _, output := range config.Outputs {
+		produced := false
+		for _, field := range output.Schema.Fields {
+			if _, ok := config.Evaluation.Mappings.Output[field.Name]; ok {
+				produced = true
+			}
+			if _, ok := config.Evaluation.Mappings.Output[output.ID+"."+field.Name]; ok {
+				produced = true
+			}
+			if inputFields[field.Name] {
+				produced = true
+			}
+		}
+		if !produced {
+			warnings = append(warnings, fmt.Sprintf("output %s has no field produced by an output mapping or input", output.ID))
+		}
+	}
+
+	warnings = append(warnings, v.lintStrategies(config)...)
+
+	if config.Controls.Deterministic {
+		for _, field := range config.Controls.Stamp {
+			if field == "run_id" || field == "timestamp" {
+				warnings = append(warnings, fmt.Sprintf("controls.stamp field %s differs on every run, so controls.deterministic outputs will not match", field))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// lintStrategies reports models missing from the provider's catalog, settings
+// the provider ignores, and configs that miss what their evaluation strategy
+// expects: classification should constrain an output field with an enum, and
+// extraction should declare an output_schema
+func (v *Validator) lintStrategies(config *Config) []string {
+	evaluations := []EvaluationConfig{config.Evaluation}
+	for _, input := range config.Inputs {
+		if input.Evaluation != nil {
+			evaluations = append(evaluations, config.EvaluationFor(input))
+		}
+	}
+
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, eval := range evaluations {
+		if !IsKnownModel(eval.Provider, eval.Model) {
+			warning := fmt.Sprintf("model %s is not in the %s model catalog; check for a typo", eval.Model, eval.Provider)
+			if !seen[warning] {
+				seen[warning] = true
+				warnings = append(warnings, warning)
+			}
+		}
+
+		if len(eval.SafetySettings) > 0 && eval.Provider != "gemini" {
+			warning := fmt.Sprintf("evaluation.safety_settings only applies to the gemini provider and is ignored by %s", eval.Provider)
+			if !seen[warning] {
+				seen[warning] = true
+				warnings = append(warnings, warning)
+			}
+		}
+
+		var warning string
+		switch eval.Strategy {
+		case "classification":
+			if !hasEnumField(config, eval) {
+				warning = "classification run has no output field with an enum of allowed labels"
+			}
+		case "extraction":
+			if eval.OutputSchema == nil {
+				warning = "extraction run has no evaluation.output_schema describing the fields to extract"
+			}
+		}
+		if warning != "" && !seen[warning] {
+			seen[warning] = true
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// hasEnumField reports whether any output or output_schema field declares an enum
+func hasEnumField(config *Config, eval EvaluationConfig) bool {
+	schemas := make([]SchemaConfig, 0, len(config.Outputs)+1)
+	for _, output := range config.Outputs {
+		schemas = append(schemas, output.Schema)
+	}
+	if eval.OutputSchema != nil {
+		schemas = append(schemas, *eval.OutputSchema)
+	}
+
+	for _, schema := range schemas {
+		for _, field := range schema.Fields {
+			if len(field.Enum) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// promptVariables returns the template variable names used in a prompt
+func promptVariables(prompt string) []string {
+	var names []string
+	for _, match := range templateVariablePattern.FindAllStringSubmatch(prompt, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func newLintTestConfig() *Config {
+	return &Config{
+		Experiment: ExperimentConfig{Name: "lint", Version: "0.1"},
+		Inputs: []InputConfig{
+			{
+				ID:     "predictions",
+				Format: "json",
+				Config: map[string]interface{}{"path": "input.json"},
+				Schema: SchemaConfig{Fields: []FieldConfig{{Name: "text", Type: "string"}}},
+			},
+		},
+		Outputs: []OutputConfig{
+			{
+				ID:     "eval-results",
+				Format: "json",
+				Config: map[string]interface{}{"path": "output.json"},
+				Schema: SchemaConfig{Fields: []FieldConfig{
+					{Name: "label", Type: "string", Enum: []string{"positive", "negative", "neutral"}},
+				}},
+			},
+		},
+		Evaluation: EvaluationConfig{
+			Provider: "gemini",
+			Model:    "gemini-pro",
+			Auth:     AuthConfig{APIKeyEnv: "GEMINI_API_KEY"},
+			Strategy: "classification",
+			Prompt:   "Text: {{text}}",
+			Mappings: MappingsConfig{
+				Output: map[string]string{"label": "$.label"},
+			},
+		},
+		Controls: ControlsConfig{Concurrency: 1, OnError: "fail"},
+	}
+}
+
+func TestValidateWithWarnings_Clean(t *testing.T) {
+	warnings, err := NewValidator().ValidateWithWarnings(newLintTestConfig())
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestValidateWithWarnings_UnusedInput(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Inputs = append(cfg.Inputs, InputConfig{
+		ID:     "references",
+		Format: "json",
+		Config: map[string]interface{}{"path": "references.json"},
+		Schema: SchemaConfig{Fields: []FieldConfig{{Name: "gold_label", Type: "string"}}},
+	})
+
+	warnings, err := NewValidator().ValidateWithWarnings(cfg)
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "input references") {
+		t.Errorf("Expected a single warning about input references, got %v", warnings)
+	}
+}
+
+func TestValidateWithWarnings_UnproducedOutput(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Evaluation.Mappings.Output = nil
+
+	warnings, err := NewValidator().ValidateWithWarnings(cfg)
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "output eval-results") {
+		t.Errorf("Expected a single warning about output eval-results, got %v", warnings)
+	}
+}
+
+func TestValidateWithWarnings_Strategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		enum     bool
+		schema   bool
+		want     string
+	}{
+		{"classification with enum", "classification", true, false, ""},
+		{"classification without enum", "classification", false, false, "no output field with an enum"},
+		{"classification with enum in output_schema", "classification", false, true, ""},
+		{"extraction with output_schema", "extraction", false, true, ""},
+		{"extraction without output_schema", "extraction", false, false, "no evaluation.output_schema"},
+		{"generation", "generation", false, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Evaluation.Strategy = tt.strategy
+			if !tt.enum {
+				cfg.Outputs[0].Schema.Fields[0].Enum = nil
+			}
+			if tt.schema {
+				cfg.Evaluation.OutputSchema = &SchemaConfig{Fields: []FieldConfig{
+					{Name: "label", Type: "string", Enum: []string{"yes", "no"}},
+				}}
+			}
+
+			warnings, err := NewValidator().ValidateWithWarnings(cfg)
+			if err != nil {
+				t.Fatalf("Validation failed: %v", err)
+			}
+
+			if tt.want == "" {
+				if len(warnings) != 0 {
+					t.Errorf("Expected no warnings, got %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.want) {
+				t.Errorf("Expected a single warning containing %q, got %v", tt.want, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateWithWarnings_SafetySettingsIgnored(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Evaluation.Provider = "openai"
+	cfg.Evaluation.Model = "gpt-4o"
+	cfg.Evaluation.SafetySettings = map[string]string{"HARM_CATEGORY_HARASSMENT": "BLOCK_NONE"}
+
+	warnings, err := NewValidator().ValidateWithWarnings(cfg)
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "ignored by openai") {
+		t.Errorf("Expected a warning that openai ignores safety_settings, got %v", warnings)
+	}
+}
+
+func TestValidateWithWarnings_DeterministicStamp(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Controls.Deterministic = true
+	cfg.Controls.Stamp = []string{"experiment_name", "run_id"}
+
+	warnings, err := NewValidator().ValidateWithWarnings(cfg)
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "run_id differs on every run") {
+		t.Errorf("Expected a warning about stamping run_id, got %v", warnings)
+	}
+}
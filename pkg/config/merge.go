@@ -0,0 +1,101 @@
+package config
+
+// EvaluationFor returns the evaluation config for an input: the global
+// evaluation with the input's override merged over it. Set fields in the
+// override win, and map fields are merged key by key.
+func (c *Config) EvaluationFor(input InputConfig) EvaluationConfig {
+	return MergeEvaluation(c.Evaluation, input.Evaluation)
+}
+
+// MergeEvaluation merges override over base without modifying either
+func MergeEvaluation(base EvaluationConfig, override *EvaluationConfig) EvaluationConfig {
+	merged := base
+	merged.Params = mergeMaps(base.Params, nil)
+	merged.RawParams = mergeMaps(base.RawParams, nil)
+	merged.Mappings = MappingsConfig{
+		Input:  mergeStringMaps(base.Mappings.Input, nil),
+		Output: mergeStringMaps(base.Mappings.Output, nil),
+	}
+
+	if override == nil {
+		return merged
+	}
+
+	if override.Provider != "" {
+		merged.Provider = override.Provider
+	}
+	if override.Model != "" {
+		merged.Model = override.Model
+	}
+	if override.Auth.APIKeyEnv != "" {
+		merged.Auth.APIKeyEnv = override.Auth.APIKeyEnv
+	}
+	if override.Strategy != "" {
+		merged.Strategy = override.Strategy
+	}
+	if override.Prompt != "" {
+		merged.Prompt = override.Prompt
+	}
+	if override.BatchSize != 0 {
+		merged.BatchSize = override.BatchSize
+	}
+	if override.OutputSchema != nil {
+		merged.OutputSchema = override.OutputSchema
+	}
+	if override.OnEmptyResponse != "" {
+		merged.OnEmptyResponse = override.OnEmptyResponse
+	}
+	if override.EmptyResponseDefault != "" {
+		merged.EmptyResponseDefault = override.EmptyResponseDefault
+	}
+	if override.TemplateValueFormat != "" {
+		merged.TemplateValueFormat = override.TemplateValueFormat
+	}
+	if override.StrictTemplate {
+		merged.StrictTemplate = true
+	}
+	if override.OnContextOverflow != "" {
+		merged.OnContextOverflow = override.OnContextOverflow
+	}
+	if override.ContextWindow != 0 {
+		merged.ContextWindow = override.ContextWindow
+	}
+
+	merged.Params = mergeMaps(merged.Params, override.Params)
+	merged.RawParams = mergeMaps(merged.RawParams, override.RawParams)
+	merged.Mappings.Input = mergeStringMaps(merged.Mappings.Input, override.Mappings.Input)
+	merged.Mappings.Output = mergeStringMaps(merged.Mappings.Output, override.Mappings.Output)
+	merged.SafetySettings = mergeStringMaps(merged.SafetySettings, override.SafetySettings)
+
+	return merged
+}
+
+// mergeMaps returns a copy of base with override's keys set over it
+func mergeMaps(base, override map[string]interface{}) map[string]interface{} {
+	if base == nil && override == nil {
+		return nil
+	}
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergeStringMaps returns a copy of base with override's keys set over it
+func mergeStringMaps(base, override map[string]string) map[string]string {
+	if base == nil && override == nil {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
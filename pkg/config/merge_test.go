@@ -0,0 +1,106 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluationFor_PerInputPrompts(t *testing.T) {
+	yamlContent := `experiment:
+  name: multi-input
+  version: 0.1
+
+inputs:
+  - id: reviews
+    format: json
+    config:
+      path: ./data/reviews.json
+    schema:
+      fields:
+        - name: text
+          type: string
+  - id: tweets
+    format: json
+    config:
+      path: ./data/tweets.json
+    schema:
+      fields:
+        - name: text
+          type: string
+    evaluation:
+      prompt: "Classify this tweet: {{text}}"
+      params:
+        temperature: 0.7
+
+outputs:
+  - id: eval-results
+    format: json
+    config:
+      path: ./data/output.json
+    schema:
+      fields:
+        - name: text
+          type: string
+
+evaluation:
+  provider: gemini
+  model: gemini-pro
+  params:
+    temperature: 0.2
+    max_tokens: 64
+  auth:
+    api_key_env: GEMINI_API_KEY
+  strategy: classification
+  prompt: "Classify this review: {{text}}"
+
+controls:
+  concurrency: 2
+  on_error: skip
+`
+
+	config, err := NewReader().Read(strings.NewReader(yamlContent))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	if err := NewValidator().Validate(config); err != nil {
+		t.Fatalf("Config validation failed: %v", err)
+	}
+
+	reviews := config.EvaluationFor(config.Inputs[0])
+	tweets := config.EvaluationFor(config.Inputs[1])
+
+	if reviews.Prompt != "Classify this review: {{text}}" {
+		t.Errorf("Expected global prompt for reviews, got %s", reviews.Prompt)
+	}
+
+	if tweets.Prompt != "Classify this tweet: {{text}}" {
+		t.Errorf("Expected override prompt for tweets, got %s", tweets.Prompt)
+	}
+
+	if tweets.Params["temperature"] != 0.7 {
+		t.Errorf("Expected override temperature 0.7, got %v", tweets.Params["temperature"])
+	}
+
+	if tweets.Params["max_tokens"] != 64 {
+		t.Errorf("Expected inherited max_tokens 64, got %v", tweets.Params["max_tokens"])
+	}
+
+	if tweets.Model != "gemini-pro" {
+		t.Errorf("Expected inherited model gemini-pro, got %s", tweets.Model)
+	}
+
+	if config.Evaluation.Params["temperature"] != 0.2 {
+		t.Errorf("Expected global params to be left unmodified, got %v", config.Evaluation.Params["temperature"])
+	}
+}
+
+func TestValidate_InvalidEvaluationOverride(t *testing.T) {
+	config := newLintTestConfig()
+	config.Inputs[0].Evaluation = &EvaluationConfig{Strategy: "summarization"}
+
+	err := NewValidator().Validate(config)
+	if err == nil || !strings.Contains(err.Error(), "input[0]") {
+		t.Errorf("Expected input[0] evaluation override error, got %v", err)
+	}
+}
@@ -4,10 +4,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// ExtensionPrefix marks top-level keys that only hold YAML anchors, e.g.
+//
+//	x-review-schema: &review-schema
+//	  fields: ...
+//
+// so blocks shared by several inputs or outputs can be referenced with *review-schema
+const ExtensionPrefix = "x-"
+
 // ReaderInterface defines the interface for configuration readers
 type ReaderInterface interface {
 	Read(r io.Reader) (*Config, error)
@@ -37,6 +46,15 @@ func (r *Reader) Read(reader io.Reader) (*Config, error) {
 		return nil, fmt.Errorf("failed to decode yaml: %w", err)
 	}
 
+	// Unknown top-level keys land in Extensions; only x- keys are allowed there
+	for key := range config.Extensions {
+		if !strings.HasPrefix(key, ExtensionPrefix) {
+			return nil, fmt.Errorf("failed to decode yaml: field %s not found in type config.Config (prefix it with %s to define shared anchors)", key, ExtensionPrefix)
+		}
+	}
+
+	ApplyDefaults(&config)
+
 	return &config, nil
 }
 
@@ -49,4 +67,4 @@ func (r *Reader) ReadFile(path string) (*Config, error) {
 	defer file.Close()
 
 	return r.Read(file)
-}
\ No newline at end of file
+}
@@ -105,4 +105,72 @@ controls:
 	if err := validator.Validate(config); err != nil {
 		t.Errorf("Config validation failed: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestReader_ReadAnchors(t *testing.T) {
+	yamlContent := `x-review-schema: &review-schema
+  fields:
+    - name: text
+      type: string
+x-json-config: &json-config
+  mode: lines
+
+experiment:
+  name: anchors
+  version: "1"
+inputs:
+  - id: reviews
+    format: json
+    config:
+      <<: *json-config
+      path: reviews.jsonl
+    schema: *review-schema
+  - id: tweets
+    format: json
+    config:
+      <<: *json-config
+      path: tweets.jsonl
+    schema: *review-schema
+`
+
+	config, err := NewReader().Read(strings.NewReader(yamlContent))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	if len(config.Inputs) != 2 {
+		t.Fatalf("Expected 2 inputs, got %d", len(config.Inputs))
+	}
+	for _, input := range config.Inputs {
+		if len(input.Schema.Fields) != 1 || input.Schema.Fields[0].Name != "text" {
+			t.Errorf("Expected input %s to use the shared schema, got %+v", input.ID, input.Schema)
+		}
+		if input.Config["mode"] != "lines" {
+			t.Errorf("Expected input %s to merge the shared config, got %v", input.ID, input.Config)
+		}
+	}
+	if config.Inputs[1].Config["path"] != "tweets.jsonl" {
+		t.Errorf("Expected merged keys to be overridable, got %v", config.Inputs[1].Config["path"])
+	}
+
+	// Aliased blocks are decoded into independent values
+	config.Inputs[0].Schema.Fields[0].Type = "email"
+	if config.Inputs[1].Schema.Fields[0].Type != "string" {
+		t.Error("Expected aliased schemas not to share fields")
+	}
+}
+
+func TestReader_RejectsUnknownTopLevelKeys(t *testing.T) {
+	_, err := NewReader().Read(strings.NewReader("experiment:\n  name: a\n  version: \"1\"\nshared: &s\n  a: 1\n"))
+	if err == nil || !strings.Contains(err.Error(), "field shared not found") {
+		t.Errorf("Expected unknown field error, got %v", err)
+	}
+}
+
+func TestReader_RejectsDuplicateOutputMappings(t *testing.T) {
+	yaml := "evaluation:\n  mappings:\n    output:\n      label: $.label\n      label: $.sentiment\n"
+	_, err := NewReader().Read(strings.NewReader(yaml))
+	if err == nil || !strings.Contains(err.Error(), `mapping key "label" already defined`) {
+		t.Errorf("Expected duplicate mapping key error, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import "strings"
+
+// RoutedInput returns the ID of the only input whose results an output
+// receives, or "" when it receives every input. An explicit output input wins;
+// otherwise an input ID the output ID equals, or starts with followed by "_"
+// (e.g. reviews_scored for reviews), with the longest such ID winning.
+func (c *Config) RoutedInput(output OutputConfig) string {
+	if output.Input != "" {
+		return output.Input
+	}
+
+	match := ""
+	for _, input := range c.Inputs {
+		if (output.ID == input.ID || strings.HasPrefix(output.ID, input.ID+"_")) && len(input.ID) > len(match) {
+			match = input.ID
+		}
+	}
+	return match
+}
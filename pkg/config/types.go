@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // Config represents the meval.yaml configuration
 type Config struct {
 	Experiment ExperimentConfig `yaml:"experiment"`
@@ -7,6 +9,9 @@ type Config struct {
 	Outputs    []OutputConfig   `yaml:"outputs"`
 	Evaluation EvaluationConfig `yaml:"evaluation"`
 	Controls   ControlsConfig   `yaml:"controls"`
+
+	// Extensions holds top-level x- keys, which exist only to define anchors for shared blocks
+	Extensions map[string]interface{} `yaml:",inline" json:"-"`
 }
 
 // ExperimentConfig represents experiment metadata
@@ -18,10 +23,11 @@ type ExperimentConfig struct {
 
 // InputConfig represents input source configuration
 type InputConfig struct {
-	ID     string                 `yaml:"id"`
-	Format string                 `yaml:"format"`
-	Config map[string]interface{} `yaml:"config"`
-	Schema SchemaConfig           `yaml:"schema"`
+	ID         string                 `yaml:"id"`
+	Format     string                 `yaml:"format"`
+	Config     map[string]interface{} `yaml:"config"`
+	Schema     SchemaConfig           `yaml:"schema"`
+	Evaluation *EvaluationConfig      `yaml:"evaluation,omitempty"` // merged over the global evaluation
 }
 
 // OutputConfig represents output source configuration
@@ -30,28 +36,51 @@ type OutputConfig struct {
 	Format string                 `yaml:"format"`
 	Config map[string]interface{} `yaml:"config"`
 	Schema SchemaConfig           `yaml:"schema"`
+	Input  string                 `yaml:"input,omitempty"` // route only this input's results here
 }
 
 // SchemaConfig represents schema configuration
 type SchemaConfig struct {
-	Fields []FieldConfig `yaml:"fields"`
+	Fields           []FieldConfig `yaml:"fields"`
+	DefaultFieldType string        `yaml:"default_field_type,omitempty"` // type for fields that omit one
 }
 
 // FieldConfig represents a field in the schema
 type FieldConfig struct {
-	Name string `yaml:"name"`
-	Type string `yaml:"type"`
+	Name      string      `yaml:"name"`
+	Type      string      `yaml:"type"`
+	Normalize []string    `yaml:"normalize,omitempty"`  // string transforms applied on read
+	Enum      []string    `yaml:"enum,omitempty"`       // allowed values, e.g. classification labels
+	PII       bool        `yaml:"pii,omitempty"`        // redacted from record previews in errors
+	Optional  bool        `yaml:"optional,omitempty"`   // may be absent from records; type-checked when present
+	Default   interface{} `yaml:"default,omitempty"`    // filled in on read when the field is absent
+	Nullable  bool        `yaml:"nullable,omitempty"`   // an explicit null passes the type check
+	Pattern   string      `yaml:"pattern,omitempty"`    // regular expression string values must match
+	Min       *float64    `yaml:"min,omitempty"`        // smallest allowed number
+	Max       *float64    `yaml:"max,omitempty"`        // largest allowed number
+	MinLength *int        `yaml:"min_length,omitempty"` // fewest characters in a string
+	MaxLength *int        `yaml:"max_length,omitempty"` // most characters in a string
 }
 
 // EvaluationConfig represents evaluation configuration
 type EvaluationConfig struct {
-	Provider string                 `yaml:"provider"`
-	Model    string                 `yaml:"model"`
-	Params   map[string]interface{} `yaml:"params"`
-	Auth     AuthConfig             `yaml:"auth"`
-	Strategy string                 `yaml:"strategy"`
-	Prompt   string                 `yaml:"prompt"`
-	Mappings MappingsConfig         `yaml:"mappings"`
+	Provider             string                 `yaml:"provider"`
+	Model                string                 `yaml:"model"`
+	Params               map[string]interface{} `yaml:"params"`
+	RawParams            map[string]interface{} `yaml:"raw_params,omitempty"` // merged into the request body verbatim
+	Auth                 AuthConfig             `yaml:"auth"`
+	Strategy             string                 `yaml:"strategy"`
+	Prompt               string                 `yaml:"prompt"`
+	Mappings             MappingsConfig         `yaml:"mappings"`
+	BatchSize            int                    `yaml:"batch_size,omitempty"`             // records packed into one prompt
+	OutputSchema         *SchemaConfig          `yaml:"output_schema,omitempty"`          // structure the model is asked to return
+	OnEmptyResponse      string                 `yaml:"on_empty_response,omitempty"`      // error, skip, retry, or default
+	EmptyResponseDefault string                 `yaml:"empty_response_default,omitempty"` // response used by on_empty_response: default
+	TemplateValueFormat  string                 `yaml:"template_value_format,omitempty"`  // json, yaml, or go rendering of object/array variables
+	StrictTemplate       bool                   `yaml:"strict_template,omitempty"`        // fail records whose prompt variables do not resolve
+	OnContextOverflow    string                 `yaml:"on_context_overflow,omitempty"`    // error, skip, or truncate prompts exceeding the context window
+	ContextWindow        int                    `yaml:"context_window,omitempty"`         // context window in tokens, overriding the model's known window
+	SafetySettings       map[string]string      `yaml:"safety_settings,omitempty"`        // Gemini harm category to block threshold
 }
 
 // AuthConfig represents authentication configuration
@@ -67,6 +96,35 @@ type MappingsConfig struct {
 
 // ControlsConfig represents execution controls
 type ControlsConfig struct {
-	Concurrency int    `yaml:"concurrency"`
-	OnError     string `yaml:"on_error"`
-}
\ No newline at end of file
+	Concurrency         int           `yaml:"concurrency"`
+	OnError             string        `yaml:"on_error"`
+	ManifestPath        string        `yaml:"manifest_path,omitempty"`         // enables incremental runs; rows are reused per input, evaluation, and record
+	Manifest            string        `yaml:"manifest,omitempty"`              // run summary index written after a run
+	OrderedOutput       bool          `yaml:"ordered_output,omitempty"`        // write outputs in input order
+	ReorderWindow       int           `yaml:"reorder_window,omitempty"`        // max results buffered ahead of the next index
+	Deterministic       bool          `yaml:"deterministic,omitempty"`         // read records in a stable order and reject order-changing options
+	Retries             int           `yaml:"retries,omitempty"`               // retry transient evaluator errors
+	RetryIdempotentOnly bool          `yaml:"retry_idempotent_only,omitempty"` // never retry unseeded generation sampled above temperature 0
+	RateLimit           float64       `yaml:"rate_limit,omitempty"`            // max evaluator requests per second
+	Cache               bool          `yaml:"cache,omitempty"`                 // memoize identical evaluations
+	LogRequests         bool          `yaml:"log_requests,omitempty"`          // log each evaluator call
+	Metrics             bool          `yaml:"metrics,omitempty"`               // collect evaluator call metrics
+	InputIDField        string        `yaml:"input_id_field,omitempty"`        // stamp the originating input ID onto results
+	Stamp               []string      `yaml:"stamp,omitempty"`                 // run-level fields added to every output record
+	MaxFailures         float64       `yaml:"max_failures,omitempty"`          // abort after this many failures, or fraction of records when below 1
+	PerRecordTimeout    time.Duration `yaml:"per_record_timeout,omitempty"`    // worst-case latency of one evaluation, e.g. 2s
+	MaxConcurrency      int           `yaml:"max_concurrency,omitempty"`       // cap on the concurrency derived from rate_limit and per_record_timeout
+	UsageReport         string        `yaml:"usage_report,omitempty"`          // per-record token usage written here, as CSV for .csv paths and JSON otherwise
+	UsageKeyField       string        `yaml:"usage_key_field,omitempty"`       // record field identifying rows in the usage report; the record hash when unset
+	Fsync               bool          `yaml:"fsync,omitempty"`                 // sync output files to disk after every write
+	IncludeErrorsInline bool          `yaml:"include_errors_inline,omitempty"` // with on_error: skip, write failed records too, with their error in error_field
+	ErrorField          string        `yaml:"error_field,omitempty"`           // output field holding the error with include_errors_inline; _error when unset
+}
+
+// InlineErrorField returns the output field failed records carry their error in
+func (c ControlsConfig) InlineErrorField() string {
+	if c.ErrorField != "" {
+		return c.ErrorField
+	}
+	return DefaultErrorField
+}
@@ -2,17 +2,29 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // Validator implements configuration validation
-type Validator struct{}
+type Validator struct {
+	strictModels bool // reject models missing from the provider's catalog instead of warning
+}
 
 // NewValidator creates a new config validator
 func NewValidator() *Validator {
 	return &Validator{}
 }
 
+// SetStrictModels makes models missing from the provider's catalog a validation
+// error rather than a lint warning
+func (v *Validator) SetStrictModels(strict bool) {
+	v.strictModels = strict
+}
+
 // Validate validates the configuration
 func (v *Validator) Validate(config *Config) error {
 	if config == nil {
@@ -46,16 +58,45 @@ func (v *Validator) Validate(config *Config) error {
 		}
 	}
 
+	if err := v.validateOutputPaths(config.Outputs); err != nil {
+		return err
+	}
+
+	if err := v.validateOutputRoutes(config); err != nil {
+		return err
+	}
+
 	// Validate evaluation
 	if err := v.validateEvaluation(config.Evaluation); err != nil {
 		return err
 	}
 
+	if err := v.validateOutputMappings(config.Evaluation.Mappings.Output, config.Outputs); err != nil {
+		return err
+	}
+
+	// Validate per-input evaluation overrides after merging
+	for i, input := range config.Inputs {
+		if input.Evaluation == nil {
+			continue
+		}
+		if err := v.validateEvaluation(config.EvaluationFor(input)); err != nil {
+			return fmt.Errorf("input[%d]: %w", i, err)
+		}
+		if err := v.validateOutputMappings(config.EvaluationFor(input).Mappings.Output, config.Outputs); err != nil {
+			return fmt.Errorf("input[%d]: %w", i, err)
+		}
+	}
+
 	// Validate controls
 	if err := v.validateControls(config.Controls); err != nil {
 		return err
 	}
 
+	if err := v.validateInlineErrors(config); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -121,11 +162,122 @@ func (v *Validator) validateOutput(output OutputConfig, index int) error {
 	return v.validateSchema(output.Schema, fmt.Sprintf("output[%d]", index))
 }
 
+// validateOutputRoutes checks that outputs routed by input reference a declared input
+func (v *Validator) validateOutputRoutes(config *Config) error {
+	for i, output := range config.Outputs {
+		if output.Input == "" {
+			continue
+		}
+		found := false
+		for _, input := range config.Inputs {
+			if input.ID == output.Input {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("output[%d]: input %s does not match any input id", i, output.Input)
+		}
+	}
+	return nil
+}
+
+// validateOutputMappings rejects mappings.output entries that write the same
+// output field, such as "label" and " label", or "label" and "<output_id>.label"
+// for an output with that ID. YAML decoding already rejects identical keys.
+func (v *Validator) validateOutputMappings(mappings map[string]string, outputs []OutputConfig) error {
+	outputIDs := make(map[string]bool, len(outputs))
+	for _, output := range outputs {
+		outputIDs[output.ID] = true
+	}
+
+	// Keys writing each field, per output ID; "" holds keys that apply to every output
+	writers := make(map[string]map[string][]string)
+	for key := range mappings {
+		scope, field := "", strings.TrimSpace(key)
+		if id, rest, ok := strings.Cut(field, "."); ok && outputIDs[id] {
+			scope, field = id, rest
+		}
+		if field == "" {
+			return fmt.Errorf("evaluation.mappings.output: key %q does not name an output field", key)
+		}
+		if writers[scope] == nil {
+			writers[scope] = make(map[string][]string)
+		}
+		writers[scope][field] = append(writers[scope][field], key)
+	}
+
+	var duplicates []string
+	for scope, fields := range writers {
+		for field, keys := range fields {
+			if scope != "" {
+				// Unscoped keys write this output's field too
+				keys = append(keys, writers[""][field]...)
+			}
+			if len(keys) < 2 {
+				continue
+			}
+			sort.Strings(keys)
+			quoted := make([]string, len(keys))
+			for i, key := range keys {
+				quoted[i] = fmt.Sprintf("%q", key)
+			}
+			target := field
+			if scope != "" {
+				target = scope + "." + field
+			}
+			duplicates = append(duplicates, fmt.Sprintf("%s write output field %s", strings.Join(quoted, ", "), target))
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return fmt.Errorf("evaluation.mappings.output has duplicate output fields: %s", strings.Join(duplicates, "; "))
+	}
+	return nil
+}
+
+// validateOutputPaths rejects outputs that resolve to the same file unless
+// every colliding output opts in with config.merge: true and shares a format
+func (v *Validator) validateOutputPaths(outputs []OutputConfig) error {
+	seen := make(map[string]int)
+	for i, output := range outputs {
+		path, _ := output.Config["path"].(string)
+		key := filepath.Clean(path)
+
+		first, exists := seen[key]
+		if !exists {
+			seen[key] = i
+			continue
+		}
+
+		other := outputs[first]
+		if !isMergeOutput(output) || !isMergeOutput(other) {
+			return fmt.Errorf("output[%d]: path %s collides with output[%d]; set config.merge: true on both to combine them", i, path, first)
+		}
+
+		if output.Format != other.Format {
+			return fmt.Errorf("output[%d]: cannot merge into %s with output[%d]: formats %s and %s differ", i, path, first, output.Format, other.Format)
+		}
+	}
+
+	return nil
+}
+
+// isMergeOutput reports whether an output opts in to sharing its path with other outputs
+func isMergeOutput(output OutputConfig) bool {
+	merge, _ := output.Config["merge"].(bool)
+	return merge
+}
+
 func (v *Validator) validateSchema(schema SchemaConfig, prefix string) error {
 	if len(schema.Fields) == 0 {
 		return fmt.Errorf("%s: schema must have at least one field", prefix)
 	}
 
+	if schema.DefaultFieldType != "" && !IsSupportedFieldType(schema.DefaultFieldType) {
+		return fmt.Errorf("%s.schema: unsupported default_field_type %s", prefix, schema.DefaultFieldType)
+	}
+
 	for i, field := range schema.Fields {
 		if field.Name == "" {
 			return fmt.Errorf("%s.schema.fields[%d]: name is required", prefix, i)
@@ -135,31 +287,129 @@ func (v *Validator) validateSchema(schema SchemaConfig, prefix string) error {
 			return fmt.Errorf("%s.schema.fields[%d]: type is required", prefix, i)
 		}
 
-		supportedTypes := []string{"string", "number", "boolean", "array", "object"}
-		if !contains(supportedTypes, field.Type) {
+		if !IsSupportedFieldType(field.Type) {
 			return fmt.Errorf("%s.schema.fields[%d]: unsupported type %s", prefix, i, field.Type)
 		}
+
+		supportedTransforms := []string{"trim", "lower", "upper", "collapse_spaces"}
+		for _, transform := range field.Normalize {
+			if !contains(supportedTransforms, transform) {
+				return fmt.Errorf("%s.schema.fields[%d]: unsupported normalize transform %s", prefix, i, transform)
+			}
+		}
+
+		if err := validateEnum(field); err != nil {
+			return fmt.Errorf("%s.schema.fields[%d]: %w", prefix, i, err)
+		}
+
+		if err := validatePattern(field); err != nil {
+			return fmt.Errorf("%s.schema.fields[%d]: %w", prefix, i, err)
+		}
+
+		if err := validateBounds(field); err != nil {
+			return fmt.Errorf("%s.schema.fields[%d]: %w", prefix, i, err)
+		}
+
+		if err := validateLengths(field); err != nil {
+			return fmt.Errorf("%s.schema.fields[%d]: %w", prefix, i, err)
+		}
 	}
 
 	return nil
 }
 
+// validateEnum checks that an enum is declared on a string or number field,
+// and that a number field's entries are numbers
+func validateEnum(field FieldConfig) error {
+	if len(field.Enum) == 0 {
+		return nil
+	}
+	switch baseType(field.Type) {
+	case "string":
+	case "number":
+		for _, entry := range field.Enum {
+			if _, err := strconv.ParseFloat(entry, 64); err != nil {
+				return fmt.Errorf("enum entry %q is not a number", entry)
+			}
+		}
+	default:
+		return fmt.Errorf("enum is only supported for string and number fields, not %s", field.Type)
+	}
+	return nil
+}
+
+// validatePattern checks that a pattern is declared on a string field and compiles
+func validatePattern(field FieldConfig) error {
+	if field.Pattern == "" {
+		return nil
+	}
+	if baseType(field.Type) != "string" {
+		return fmt.Errorf("pattern is only supported for string fields, not %s", field.Type)
+	}
+	if _, err := regexp.Compile(field.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern %s: %w", field.Pattern, err)
+	}
+	return nil
+}
+
+// validateBounds checks that min and max are declared on a number field and form a range
+func validateBounds(field FieldConfig) error {
+	if field.Min == nil && field.Max == nil {
+		return nil
+	}
+	if baseType(field.Type) != "number" {
+		return fmt.Errorf("min and max are only supported for number and integer fields, not %s", field.Type)
+	}
+	if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+		return fmt.Errorf("min %v is greater than max %v", *field.Min, *field.Max)
+	}
+	return nil
+}
+
+// validateLengths checks that min_length and max_length are declared on a
+// string field, are not negative, and form a range
+func validateLengths(field FieldConfig) error {
+	if field.MinLength == nil && field.MaxLength == nil {
+		return nil
+	}
+	if baseType(field.Type) != "string" {
+		return fmt.Errorf("min_length and max_length are only supported for string fields, not %s", field.Type)
+	}
+	if field.MinLength != nil && *field.MinLength < 0 {
+		return fmt.Errorf("min_length must not be negative")
+	}
+	if field.MaxLength != nil && *field.MaxLength < 0 {
+		return fmt.Errorf("max_length must not be negative")
+	}
+	if field.MinLength != nil && field.MaxLength != nil && *field.MinLength > *field.MaxLength {
+		return fmt.Errorf("min_length %d is greater than max_length %d", *field.MinLength, *field.MaxLength)
+	}
+	return nil
+}
+
 func (v *Validator) validateEvaluation(eval EvaluationConfig) error {
 	if eval.Provider == "" {
 		return fmt.Errorf("evaluation.provider is required")
 	}
 
-	supportedProviders := []string{"openai", "anthropic", "gemini", "bedrock"}
+	supportedProviders := []string{"openai", "anthropic", "gemini", "bedrock", "passthrough"}
 	if !contains(supportedProviders, eval.Provider) {
 		return fmt.Errorf("evaluation: unsupported provider %s", eval.Provider)
 	}
 
-	if eval.Model == "" {
-		return fmt.Errorf("evaluation.model is required")
-	}
+	// The passthrough provider calls no model, so it needs neither a model nor credentials
+	if eval.Provider != "passthrough" {
+		if eval.Model == "" {
+			return fmt.Errorf("evaluation.model is required")
+		}
+
+		if v.strictModels && !IsKnownModel(eval.Provider, eval.Model) {
+			return fmt.Errorf("evaluation: unknown %s model %s", eval.Provider, eval.Model)
+		}
 
-	if eval.Auth.APIKeyEnv == "" {
-		return fmt.Errorf("evaluation.auth.api_key_env is required")
+		if eval.Auth.APIKeyEnv == "" {
+			return fmt.Errorf("evaluation.auth.api_key_env is required")
+		}
 	}
 
 	if eval.Strategy == "" {
@@ -179,12 +429,94 @@ func (v *Validator) validateEvaluation(eval EvaluationConfig) error {
 		return fmt.Errorf("evaluation.prompt must contain at least one template variable")
 	}
 
+	if eval.BatchSize < 0 {
+		return fmt.Errorf("evaluation.batch_size must not be negative")
+	}
+
+	if eval.OutputSchema != nil {
+		if err := v.validateSchema(*eval.OutputSchema, "evaluation.output"); err != nil {
+			return err
+		}
+	}
+
+	if eval.OnEmptyResponse != "" && !contains([]string{"error", "skip", "retry", "default"}, eval.OnEmptyResponse) {
+		return fmt.Errorf("evaluation.on_empty_response must be one of error, skip, retry, default, got %s", eval.OnEmptyResponse)
+	}
+
+	if eval.OnEmptyResponse == "default" && eval.EmptyResponseDefault == "" {
+		return fmt.Errorf("evaluation.empty_response_default is required when on_empty_response is default")
+	}
+
+	if eval.TemplateValueFormat != "" && !contains([]string{"json", "yaml", "go"}, eval.TemplateValueFormat) {
+		return fmt.Errorf("evaluation.template_value_format must be one of json, yaml, go, got %s", eval.TemplateValueFormat)
+	}
+
+	if eval.OnContextOverflow != "" && !contains([]string{"error", "skip", "truncate"}, eval.OnContextOverflow) {
+		return fmt.Errorf("evaluation.on_context_overflow must be one of error, skip, truncate, got %s", eval.OnContextOverflow)
+	}
+
+	if eval.ContextWindow < 0 {
+		return fmt.Errorf("evaluation.context_window must not be negative")
+	}
+
+	if err := validateSafetySettings(eval.SafetySettings); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SafetyCategories are the Gemini harm categories safety_settings can adjust
+var SafetyCategories = []string{
+	"HARM_CATEGORY_HARASSMENT",
+	"HARM_CATEGORY_HATE_SPEECH",
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"HARM_CATEGORY_DANGEROUS_CONTENT",
+	"HARM_CATEGORY_CIVIC_INTEGRITY",
+}
+
+// SafetyThresholds are the Gemini block thresholds, from most to least permissive
+var SafetyThresholds = []string{
+	"BLOCK_NONE",
+	"OFF",
+	"BLOCK_ONLY_HIGH",
+	"BLOCK_MEDIUM_AND_ABOVE",
+	"BLOCK_LOW_AND_ABOVE",
+	"HARM_BLOCK_THRESHOLD_UNSPECIFIED",
+}
+
+// validateSafetySettings checks every category and threshold name, in category order
+func validateSafetySettings(settings map[string]string) error {
+	categories := make([]string, 0, len(settings))
+	for category := range settings {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		if !contains(SafetyCategories, category) {
+			return fmt.Errorf("evaluation.safety_settings: unknown category %s, must be one of %s", category, strings.Join(SafetyCategories, ", "))
+		}
+		if threshold := settings[category]; !contains(SafetyThresholds, threshold) {
+			return fmt.Errorf("evaluation.safety_settings.%s: unknown threshold %s, must be one of %s", category, threshold, strings.Join(SafetyThresholds, ", "))
+		}
+	}
 	return nil
 }
 
 func (v *Validator) validateControls(controls ControlsConfig) error {
-	if controls.Concurrency <= 0 {
-		return fmt.Errorf("controls.concurrency must be greater than 0")
+	if controls.PerRecordTimeout < 0 {
+		return fmt.Errorf("controls.per_record_timeout must not be negative")
+	}
+
+	if controls.MaxConcurrency < 0 {
+		return fmt.Errorf("controls.max_concurrency must not be negative")
+	}
+
+	// Concurrency may be left unset when it can be derived from the rate limit
+	derived := controls.RateLimit > 0 && controls.PerRecordTimeout > 0
+	if controls.Concurrency < 0 || (controls.Concurrency == 0 && !derived) {
+		return fmt.Errorf("controls.concurrency must be greater than 0, or set rate_limit and per_record_timeout to derive it")
 	}
 
 	if controls.OnError == "" {
@@ -196,6 +528,65 @@ func (v *Validator) validateControls(controls ControlsConfig) error {
 		return fmt.Errorf("controls: unsupported on_error value %s", controls.OnError)
 	}
 
+	if controls.ReorderWindow < 0 {
+		return fmt.Errorf("controls.reorder_window must not be negative")
+	}
+
+	if controls.Retries < 0 {
+		return fmt.Errorf("controls.retries must not be negative")
+	}
+
+	if controls.RateLimit < 0 {
+		return fmt.Errorf("controls.rate_limit must not be negative")
+	}
+
+	if controls.MaxFailures < 0 {
+		return fmt.Errorf("controls.max_failures must not be negative")
+	}
+
+	supportedStampFields := []string{"experiment_name", "version", "run_id", "timestamp", "_provider", "_model"}
+	for _, field := range controls.Stamp {
+		if !contains(supportedStampFields, field) {
+			return fmt.Errorf("controls: unsupported stamp field %s", field)
+		}
+	}
+
+	return nil
+}
+
+// validateInlineErrors checks controls.include_errors_inline: failed records are
+// only written with on_error: skip, and the error field must not overwrite a
+// field the records already carry
+func (v *Validator) validateInlineErrors(config *Config) error {
+	controls := config.Controls
+	if !controls.IncludeErrorsInline {
+		if controls.ErrorField != "" {
+			return fmt.Errorf("controls.error_field requires include_errors_inline")
+		}
+		return nil
+	}
+
+	if controls.OnError != "skip" {
+		return fmt.Errorf("controls.include_errors_inline requires on_error: skip, got %s", controls.OnError)
+	}
+
+	field := controls.InlineErrorField()
+	if strings.TrimSpace(field) == "" {
+		return fmt.Errorf("controls.error_field must not be blank")
+	}
+	for i, input := range config.Inputs {
+		if _, ok := schemaField(input.Schema, field); ok {
+			return fmt.Errorf("controls.error_field %s collides with a field of input[%d] schema; set error_field to another name", field, i)
+		}
+	}
+	for i, output := range config.Outputs {
+		if _, ok := schemaField(output.Schema, field); ok {
+			return fmt.Errorf("controls.error_field %s collides with a field of output[%d] schema; set error_field to another name", field, i)
+		}
+	}
+	if field == controls.InputIDField || contains(controls.Stamp, field) {
+		return fmt.Errorf("controls.error_field %s collides with a stamped field; set error_field to another name", field)
+	}
 	return nil
 }
 
@@ -206,4 +597,4 @@ func contains(slice []string, item string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,437 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate_DuplicateOutputPaths(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Outputs = append(cfg.Outputs, OutputConfig{
+		ID:     "eval-copy",
+		Format: "json",
+		Config: map[string]interface{}{"path": "./output.json"},
+		Schema: SchemaConfig{Fields: []FieldConfig{{Name: "label", Type: "string"}}},
+	})
+
+	err := NewValidator().Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "collides with output[0]") {
+		t.Fatalf("Expected colliding output path error, got %v", err)
+	}
+
+	// Opting both outputs in to merging makes the collision explicit
+	cfg.Outputs[0].Config["merge"] = true
+	cfg.Outputs[1].Config["merge"] = true
+
+	if err := NewValidator().Validate(cfg); err != nil {
+		t.Errorf("Expected merged outputs to validate, got %v", err)
+	}
+
+	cfg.Outputs[1].Format = "csv"
+	if err := NewValidator().Validate(cfg); err == nil {
+		t.Error("Expected error merging outputs with different formats, got nil")
+	}
+}
+
+func TestValidate_OutputInputRoute(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Outputs[0].Input = cfg.Inputs[0].ID
+
+	if err := NewValidator().Validate(cfg); err != nil {
+		t.Errorf("Expected output routed to a declared input to validate, got %v", err)
+	}
+
+	cfg.Outputs[0].Input = "missing"
+	err := NewValidator().Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "does not match any input id") {
+		t.Errorf("Expected unknown input route error, got %v", err)
+	}
+}
+
+func TestValidate_OnEmptyResponse(t *testing.T) {
+	tests := []struct {
+		policy  string
+		def     string
+		wantErr string
+	}{
+		{"", "", ""},
+		{"retry", "", ""},
+		{"default", "neutral", ""},
+		{"default", "", "empty_response_default is required"},
+		{"ignore", "", "must be one of"},
+	}
+
+	for _, tt := range tests {
+		cfg := newLintTestConfig()
+		cfg.Evaluation.OnEmptyResponse = tt.policy
+		cfg.Evaluation.EmptyResponseDefault = tt.def
+
+		err := NewValidator().Validate(cfg)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Expected policy %q to validate, got %v", tt.policy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Expected error containing %q for policy %q, got %v", tt.wantErr, tt.policy, err)
+		}
+	}
+}
+
+func TestValidate_OnContextOverflow(t *testing.T) {
+	tests := []struct {
+		policy  string
+		window  int
+		wantErr string
+	}{
+		{"", 0, ""},
+		{"truncate", 8192, ""},
+		{"shrink", 0, "must be one of"},
+		{"skip", -1, "context_window must not be negative"},
+	}
+
+	for _, tt := range tests {
+		cfg := newLintTestConfig()
+		cfg.Evaluation.OnContextOverflow = tt.policy
+		cfg.Evaluation.ContextWindow = tt.window
+
+		err := NewValidator().Validate(cfg)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Expected policy %q to validate, got %v", tt.policy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Expected error containing %q for policy %q, got %v", tt.wantErr, tt.policy, err)
+		}
+	}
+}
+
+func TestValidate_SafetySettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		wantErr  string
+	}{
+		{"unset", nil, ""},
+		{"relaxed", map[string]string{"HARM_CATEGORY_HARASSMENT": "BLOCK_NONE", "HARM_CATEGORY_HATE_SPEECH": "BLOCK_ONLY_HIGH"}, ""},
+		{"unknown category", map[string]string{"HARM_CATEGORY_SPAM": "BLOCK_NONE"}, "unknown category HARM_CATEGORY_SPAM"},
+		{"unknown threshold", map[string]string{"HARM_CATEGORY_HARASSMENT": "block_none"}, "safety_settings.HARM_CATEGORY_HARASSMENT: unknown threshold block_none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Evaluation.SafetySettings = tt.settings
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected settings to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_DuplicateOutputMappings(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings map[string]string
+		wantErr  string
+	}{
+		{"distinct fields", map[string]string{"label": "$.label", "explanation": "$.explanation"}, ""},
+		{"other output id", map[string]string{"label": "$.label", "other.label": "$.sentiment"}, ""},
+		{"padded key", map[string]string{"label": "$.label", " label": "$.sentiment"}, `" label", "label" write output field label`},
+		{"output-scoped key", map[string]string{"label": "$.label", "eval-results.label": "$.sentiment"}, `"eval-results.label", "label" write output field eval-results.label`},
+		{"empty key", map[string]string{" ": "$.label"}, "does not name an output field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Evaluation.Mappings.Output = tt.mappings
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected mappings to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_DuplicateOutputMappingsPerInput(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Inputs[0].Evaluation = &EvaluationConfig{
+		Mappings: MappingsConfig{Output: map[string]string{"eval-results.label": "$.sentiment"}},
+	}
+
+	err := NewValidator().Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "input[0]: evaluation.mappings.output has duplicate output fields") {
+		t.Errorf("Expected duplicate error from the merged per-input mappings, got %v", err)
+	}
+}
+
+func TestValidate_DerivedConcurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		controls ControlsConfig
+		wantErr  string
+	}{
+		{"explicit", ControlsConfig{Concurrency: 4, OnError: "fail"}, ""},
+		{"derived", ControlsConfig{RateLimit: 10, PerRecordTimeout: 2 * time.Second, MaxConcurrency: 8, OnError: "fail"}, ""},
+		{"missing timeout", ControlsConfig{RateLimit: 10, OnError: "fail"}, "controls.concurrency must be greater than 0"},
+		{"negative timeout", ControlsConfig{Concurrency: 1, PerRecordTimeout: -time.Second, OnError: "fail"}, "per_record_timeout must not be negative"},
+		{"negative cap", ControlsConfig{Concurrency: 1, MaxConcurrency: -1, OnError: "fail"}, "max_concurrency must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Controls = tt.controls
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected controls to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_PassthroughProvider(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Evaluation.Provider = "passthrough"
+	cfg.Evaluation.Model = ""
+	cfg.Evaluation.Auth = AuthConfig{}
+
+	if err := NewValidator().Validate(cfg); err != nil {
+		t.Errorf("Expected passthrough provider without model or auth to validate, got %v", err)
+	}
+
+	cfg.Evaluation.Provider = "gemini"
+	if err := NewValidator().Validate(cfg); err == nil {
+		t.Error("Expected gemini provider without model to fail, got nil")
+	}
+}
+
+func TestValidate_StampFields(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Controls.Stamp = []string{"experiment_name", "version", "run_id", "timestamp", "_provider", "_model"}
+
+	if err := NewValidator().Validate(cfg); err != nil {
+		t.Errorf("Expected supported stamp fields to validate, got %v", err)
+	}
+
+	cfg.Controls.Stamp = []string{"hostname"}
+	err := NewValidator().Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "unsupported stamp field hostname") {
+		t.Errorf("Expected unsupported stamp field error, got %v", err)
+	}
+}
+
+func TestValidate_MaxFailures(t *testing.T) {
+	cfg := newLintTestConfig()
+	cfg.Controls.MaxFailures = 0.05
+
+	if err := NewValidator().Validate(cfg); err != nil {
+		t.Errorf("Expected fractional max_failures to validate, got %v", err)
+	}
+
+	cfg.Controls.MaxFailures = -1
+	if err := NewValidator().Validate(cfg); err == nil {
+		t.Error("Expected error for negative max_failures, got nil")
+	}
+}
+
+func TestValidate_IncludeErrorsInline(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"default field", func(c *Config) {}, ""},
+		{"custom field", func(c *Config) { c.Controls.ErrorField = "failure" }, ""},
+		{"requires skip", func(c *Config) { c.Controls.OnError = "fail" }, "requires on_error: skip"},
+		{"input collision", func(c *Config) { c.Controls.ErrorField = "text" }, "collides with a field of input[0] schema"},
+		{"output collision", func(c *Config) { c.Controls.ErrorField = "label" }, "collides with a field of output[0] schema"},
+		{"stamp collision", func(c *Config) {
+			c.Controls.Stamp = []string{"run_id"}
+			c.Controls.ErrorField = "run_id"
+		}, "collides with a stamped field"},
+		{"error field without inline", func(c *Config) {
+			c.Controls.IncludeErrorsInline = false
+			c.Controls.ErrorField = "failure"
+		}, "requires include_errors_inline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Controls.OnError = "skip"
+			cfg.Controls.IncludeErrorsInline = true
+			tt.modify(cfg)
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected config to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Enum(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   FieldConfig
+		wantErr string
+	}{
+		{"string", FieldConfig{Name: "label", Type: "string", Enum: []string{"positive", "negative"}}, ""},
+		{"number", FieldConfig{Name: "stars", Type: "number", Enum: []string{"1", "2.5", "3"}}, ""},
+		{"format type", FieldConfig{Name: "source", Type: "url", Enum: []string{"https://example.com"}}, ""},
+		{"number with a word", FieldConfig{Name: "stars", Type: "number", Enum: []string{"1", "five"}}, `enum entry "five" is not a number`},
+		{"boolean", FieldConfig{Name: "flag", Type: "boolean", Enum: []string{"true"}}, "enum is only supported for string and number fields, not boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Outputs[0].Schema.Fields = []FieldConfig{tt.field}
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected config to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Pattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   FieldConfig
+		wantErr string
+	}{
+		{"string", FieldConfig{Name: "id", Type: "string", Pattern: "^[a-z0-9-]+$"}, ""},
+		{"format type", FieldConfig{Name: "contact", Type: "email", Pattern: "@example\\.com$"}, ""},
+		{"number", FieldConfig{Name: "score", Type: "number", Pattern: "^[0-9]+$"}, "pattern is only supported for string fields, not number"},
+		{"invalid", FieldConfig{Name: "id", Type: "string", Pattern: "[a-z"}, "invalid pattern [a-z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Outputs[0].Schema.Fields = []FieldConfig{tt.field}
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected config to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Bounds(t *testing.T) {
+	zero, one := 0.0, 1.0
+
+	tests := []struct {
+		name    string
+		field   FieldConfig
+		wantErr string
+	}{
+		{"range", FieldConfig{Name: "score", Type: "number", Min: &zero, Max: &one}, ""},
+		{"min only", FieldConfig{Name: "votes", Type: "integer", Min: &zero}, ""},
+		{"equal bounds", FieldConfig{Name: "score", Type: "number", Min: &one, Max: &one}, ""},
+		{"min above max", FieldConfig{Name: "score", Type: "number", Min: &one, Max: &zero}, "min 1 is greater than max 0"},
+		{"string", FieldConfig{Name: "label", Type: "string", Max: &one}, "min and max are only supported for number and integer fields, not string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Outputs[0].Schema.Fields = []FieldConfig{tt.field}
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected config to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Lengths(t *testing.T) {
+	negative, one, ten := -1, 1, 10
+
+	tests := []struct {
+		name    string
+		field   FieldConfig
+		wantErr string
+	}{
+		{"range", FieldConfig{Name: "summary", Type: "string", MinLength: &one, MaxLength: &ten}, ""},
+		{"max only", FieldConfig{Name: "summary", Type: "string", MaxLength: &ten}, ""},
+		{"negative min", FieldConfig{Name: "summary", Type: "string", MinLength: &negative}, "min_length must not be negative"},
+		{"negative max", FieldConfig{Name: "summary", Type: "string", MaxLength: &negative}, "max_length must not be negative"},
+		{"min above max", FieldConfig{Name: "summary", Type: "string", MinLength: &ten, MaxLength: &one}, "min_length 10 is greater than max_length 1"},
+		{"number", FieldConfig{Name: "score", Type: "number", MaxLength: &ten}, "min_length and max_length are only supported for string fields, not number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newLintTestConfig()
+			cfg.Outputs[0].Schema.Fields = []FieldConfig{tt.field}
+
+			err := NewValidator().Validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected config to validate, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
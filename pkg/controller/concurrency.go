@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// DeriveConcurrency applies Little's law: to sustain rateLimit requests per
+// second when each takes up to latency, ceil(rateLimit × latency) requests must
+// be in flight. More would only queue behind the rate limiter. The result is at
+// least 1 and, when maxConcurrency is positive, at most maxConcurrency.
+func DeriveConcurrency(rateLimit float64, latency time.Duration, maxConcurrency int) int {
+	concurrency := int(math.Ceil(rateLimit * latency.Seconds()))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if maxConcurrency > 0 && concurrency > maxConcurrency {
+		concurrency = maxConcurrency
+	}
+	return concurrency
+}
+
+// resolveConcurrency fills controls.concurrency from rate_limit and
+// per_record_timeout when it is unset; an explicit concurrency always wins
+func resolveConcurrency(controls config.ControlsConfig) config.ControlsConfig {
+	if controls.Concurrency > 0 || controls.RateLimit <= 0 || controls.PerRecordTimeout <= 0 {
+		return controls
+	}
+
+	controls.Concurrency = DeriveConcurrency(controls.RateLimit, controls.PerRecordTimeout, controls.MaxConcurrency)
+	log.Printf("using concurrency %d derived from rate_limit %g/s and per_record_timeout %s",
+		controls.Concurrency, controls.RateLimit, controls.PerRecordTimeout)
+	return controls
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestDeriveConcurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit float64
+		latency   time.Duration
+		max       int
+		want      int
+	}{
+		{"rate times latency", 10, 2 * time.Second, 0, 20},
+		{"rounds up", 3, 1500 * time.Millisecond, 0, 5},
+		{"fast calls need one worker", 0.5, 100 * time.Millisecond, 0, 1},
+		{"capped by max_concurrency", 50, 4 * time.Second, 32, 32},
+		{"below the cap", 5, time.Second, 32, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeriveConcurrency(tt.rateLimit, tt.latency, tt.max); got != tt.want {
+				t.Errorf("Expected concurrency %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveConcurrency(t *testing.T) {
+	derived := resolveConcurrency(config.ControlsConfig{RateLimit: 4, PerRecordTimeout: 3 * time.Second})
+	if derived.Concurrency != 12 {
+		t.Errorf("Expected derived concurrency 12, got %d", derived.Concurrency)
+	}
+
+	explicit := resolveConcurrency(config.ControlsConfig{Concurrency: 2, RateLimit: 4, PerRecordTimeout: 3 * time.Second})
+	if explicit.Concurrency != 2 {
+		t.Errorf("Expected explicit concurrency 2 to win, got %d", explicit.Concurrency)
+	}
+}
+
+func TestExecute_DerivesConcurrency(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}, {"text": "awful"}})
+	cfg.Controls.Concurrency = 0
+	cfg.Controls.RateLimit = 100
+	cfg.Controls.PerRecordTimeout = 50 * time.Millisecond
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+	if cfg.Controls.Concurrency != 0 {
+		t.Errorf("Expected the caller's config to be left unchanged, got concurrency %d", cfg.Controls.Concurrency)
+	}
+	if rows := readOutput(t, outputPath); len(rows) != 2 {
+		t.Errorf("Expected 2 output rows, got %v", rows)
+	}
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// DefaultController runs evaluation pipelines using the default source and evaluator factories
+type DefaultController struct {
+	sources    sources.Factory
+	evaluators evaluators.Factory // replaces the factory built from controls when set
+	tokenizer  evaluators.Tokenizer
+	prices     map[string]ModelPrice
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // cancels the running Execute, nil when idle
+}
+
+// NewDefaultController creates a controller using the default factories and the approximate tokenizer
+func NewDefaultController() *DefaultController {
+	prices := make(map[string]ModelPrice, len(DefaultModelPrices))
+	for model, price := range DefaultModelPrices {
+		prices[model] = price
+	}
+
+	return &DefaultController{
+		sources:   sources.NewDefaultFactory(),
+		tokenizer: evaluators.NewApproximateTokenizer(),
+		prices:    prices,
+	}
+}
+
+// SetTokenizer replaces the tokenizer used for estimates
+func (c *DefaultController) SetTokenizer(tokenizer evaluators.Tokenizer) {
+	c.tokenizer = tokenizer
+}
+
+// SetEvaluatorFactory replaces the evaluator factory Execute builds from the
+// run controls, e.g. to plug in custom providers. The factory's evaluators are
+// used as is, without the controls middleware.
+func (c *DefaultController) SetEvaluatorFactory(factory evaluators.Factory) {
+	c.evaluators = factory
+}
+
+// SetModelPrice sets or overrides the price used to estimate a model's cost
+func (c *DefaultController) SetModelPrice(model string, price ModelPrice) {
+	c.prices[model] = price
+}
+
+// Execute runs the evaluation pipeline: every input is read and evaluated, and
+// its results are stamped and written to the outputs it routes to. Unchanged
+// records reuse their rows under controls.manifest_path, rows are written as
+// they complete under controls.ordered_output, and controls.manifest and
+// controls.metrics report on the run once it ends, even when it fails.
+func (c *DefaultController) Execute(ctx context.Context, cfg *config.Config) (err error) {
+	if err := Preflight(cfg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.cancel = nil
+		c.mu.Unlock()
+	}()
+
+	// The run manifest hashes the config as given and is written even when the
+	// run fails, once the outputs it checksums are closed
+	var summary RunSummary
+	var metrics *evaluators.Metrics
+	var manifest *RunManifest
+	written := make(map[string]int, len(cfg.Outputs))
+	if path := cfg.Controls.Manifest; path != "" {
+		if manifest, err = NewRunManifest(cfg); err != nil {
+			return err
+		}
+		defer func() {
+			manifest.finishRun(cfg, summary, metrics, written, err)
+			if saveErr := manifest.Save(path); saveErr != nil {
+				err = errors.Join(err, saveErr)
+			}
+		}()
+	}
+
+	// Work on a copy so the derived concurrency does not leak into the caller's config
+	resolved := *cfg
+	resolved.Controls = resolveConcurrency(cfg.Controls)
+	cfg = &resolved
+
+	run, err := NewRun()
+	if err != nil {
+		return err
+	}
+	stampValues := run.StampValues(cfg)
+
+	outputs := make(map[string]sources.Source, len(cfg.Outputs))
+	opened := make([]sources.Source, 0, len(cfg.Outputs))
+	defer func() {
+		if closeErr := sources.CloseAll(opened...); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close outputs: %w", closeErr))
+		}
+	}()
+	for i, output := range cfg.Outputs {
+		// Outputs are created even when no record is routed to them
+		outputCfg, schema := withDefault(output.Config, "write_empty", true), output.Schema
+		if cfg.Controls.Fsync {
+			outputCfg = withFsync(outputCfg)
+		}
+		if cfg.Controls.IncludeErrorsInline {
+			outputCfg, schema = withErrorField(outputCfg, schema, cfg.Controls.InlineErrorField())
+		}
+		dst, err := c.sources.CreateSource(outputCfg, output.Format, schema)
+		if err != nil {
+			return fmt.Errorf("output[%d]: %w", i, err)
+		}
+		outputs[output.ID] = dst
+		opened = append(opened, dst)
+	}
+
+	// The usage report covers every record evaluated, even when the run fails partway
+	usage := newUsageReport(cfg.Controls, c.prices)
+	if usage != nil {
+		defer func() {
+			if saveErr := usage.Save(cfg.Controls.UsageReport); saveErr != nil {
+				err = errors.Join(err, saveErr)
+			}
+		}()
+	}
+
+	// Records evaluated by a previous run are reused; the manifest is saved even
+	// when the run fails so completed records are not evaluated again
+	var incremental *incrementalRun
+	if path := cfg.Controls.ManifestPath; path != "" {
+		if incremental, err = loadIncrementalRun(path); err != nil {
+			return err
+		}
+		defer func() {
+			if saveErr := incremental.save(path, err != nil); saveErr != nil {
+				err = errors.Join(err, saveErr)
+			}
+		}()
+	}
+
+	var factory evaluators.Factory = evaluators.NewFactoryWithControls(cfg.Controls)
+	if c.evaluators != nil {
+		factory = c.evaluators
+	}
+	// Factories collecting controls.metrics report them once the run ends
+	if metered, ok := factory.(interface{ Metrics() *evaluators.Metrics }); ok {
+		metrics = metered.Metrics()
+	}
+	if metrics != nil {
+		defer func() {
+			log.Printf("evaluator metrics: %d calls, %d records, %d errors, %s total latency",
+				metrics.Calls(), metrics.Records(), metrics.Errors(), metrics.Latency())
+		}()
+	}
+	router := NewRouter(cfg)
+	for _, input := range cfg.Inputs {
+		sink := &routedOutputs{input: input.ID, router: router, outputs: outputs, stampValues: stampValues, written: written}
+		var ordered *OrderedWriter
+		if cfg.Controls.OrderedOutput {
+			ordered = NewOrderedWriter(sink, cfg.Controls.ReorderWindow)
+		}
+		read := summary.Records
+		rows, evalErr := c.evaluateInput(ctx, cfg, input, factory, &summary, usage, incremental, ordered)
+		if manifest != nil {
+			manifest.AddInput(input.ID, inputFiles(input), summary.Records-read)
+		}
+
+		// Rows completed before a stop are still written; a cancelled run uses
+		// a fresh context so the flush itself is not cancelled
+		writeCtx := ctx
+		if evalErr != nil {
+			writeCtx = context.WithoutCancel(ctx)
+		}
+		if err := sink.Write(writeCtx, rows); err != nil {
+			return &RunError{Input: input.ID, Err: errors.Join(evalErr, err), Summary: summary}
+		}
+
+		if evalErr != nil {
+			var tooMany *TooManyFailuresError
+			if errors.As(evalErr, &tooMany) {
+				tooMany.Summary = summary
+			}
+			return &RunError{Input: input.ID, Err: evalErr, Summary: summary}
+		}
+	}
+
+	return nil
+}
+
+// routedOutputs writes the rows of an input, stamped with the run fields, to
+// the outputs it routes to
+type routedOutputs struct {
+	sources.BaseSource
+	input       string
+	router      *Router
+	outputs     map[string]sources.Source
+	stampValues map[string]interface{}
+	written     map[string]int // rows written, by output ID
+}
+
+func (r *routedOutputs) Read(ctx context.Context) ([]sources.Record, error) {
+	return nil, fmt.Errorf("input %s outputs are write-only", r.input)
+}
+
+func (r *routedOutputs) Write(ctx context.Context, rows []sources.Record) error {
+	stamp(rows, r.stampValues)
+	for outputID, records := range r.router.Route(r.input, rows) {
+		if len(records) == 0 {
+			continue
+		}
+		if err := r.outputs[outputID].Write(ctx, records); err != nil {
+			return fmt.Errorf("output %s: %w", outputID, err)
+		}
+		r.written[outputID] += len(records)
+	}
+	return nil
+}
+
+// Close leaves the outputs open; Execute closes them once every input is written
+func (r *routedOutputs) Close() error {
+	return nil
+}
+
+// withFsync returns a copy of an output config with fsync enabled, unless the
+// output sets fsync itself
+func withFsync(cfg map[string]interface{}) map[string]interface{} {
+	return withDefault(cfg, "fsync", true)
+}
+
+// withDefault returns a copy of a source config with key set to value, unless
+// the config sets key itself
+func withDefault(cfg map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if _, ok := cfg[key]; ok {
+		return cfg
+	}
+	merged := make(map[string]interface{}, len(cfg)+1)
+	for k, v := range cfg {
+		merged[k] = v
+	}
+	merged[key] = value
+	return merged
+}
+
+// withErrorField returns copies of an output config and schema that carry the
+// inline error field: the schema gains it as a string column, and the config
+// names it so failed records may be written without their model outputs
+func withErrorField(cfg map[string]interface{}, schema config.SchemaConfig, field string) (map[string]interface{}, config.SchemaConfig) {
+	merged := make(map[string]interface{}, len(cfg)+1)
+	for k, v := range cfg {
+		merged[k] = v
+	}
+	merged["error_field"] = field
+
+	fields := make([]config.FieldConfig, len(schema.Fields), len(schema.Fields)+1)
+	copy(fields, schema.Fields)
+	schema.Fields = append(fields, config.FieldConfig{Name: field, Type: "string"})
+	return merged, schema
+}
+
+// Stop gracefully stops a running Execute: no further records are dispatched,
+// in-flight requests are cancelled, completed results are written, and outputs
+// are closed before Execute returns a RunError wrapping context.Canceled
+func (c *DefaultController) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
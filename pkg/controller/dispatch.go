@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// resultCheck accounts for the completed result of record i and returns an
+// error when the run must stop
+type resultCheck func(i int, result evaluators.Result) error
+
+// resultEmit hands on the result of record i once its unit finished, with done
+// reporting whether it completed, and returns an error when the run must stop
+type resultEmit func(i int, result evaluators.Result, done bool) error
+
+// evaluateUnits evaluates records in units of unitSize (one request each, or
+// one packed request) on up to concurrency workers. Each completed result is
+// passed to check, which decides whether the run must stop; once it fails, or
+// ctx ends, no further units are dispatched and in-flight requests are
+// cancelled. When emit is set, every record of a dispatched unit is then passed
+// to it outside the lock check runs under, so emit may block. It returns the
+// results with done[i] reporting whether record i completed, and the error that
+// stopped the run. Every worker has exited when it returns.
+func evaluateUnits(ctx context.Context, evaluator evaluators.Evaluator, records []sources.Record, prompt string, unitSize, concurrency int, check resultCheck, emit resultEmit) ([]evaluators.Result, []bool, error) {
+	if unitSize < 1 {
+		unitSize = 1
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]evaluators.Result, len(records))
+	done := make([]bool, len(records))
+
+	var mu sync.Mutex
+	var stopErr error
+	stop := func(err error) {
+		if stopErr == nil {
+			stopErr = err
+			cancel()
+		}
+	}
+
+	units := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range units {
+				end := min(start+unitSize, len(records))
+				batch, err := evaluator.BatchEvaluate(ctx, records[start:end], prompt)
+				if err == nil {
+					err = evaluators.CheckAlignment(records[start:end], batch)
+				}
+
+				mu.Lock()
+				switch {
+				case err != nil && ctx.Err() != nil:
+					// Cancelled along with the run; the unit never completed
+				case err != nil:
+					stop(fmt.Errorf("evaluation failed: %w", err))
+				default:
+					for k, result := range batch {
+						// Requests cut short by the cancellation are not results
+						if result.Error != nil && ctx.Err() != nil && errors.Is(result.Error, ctx.Err()) {
+							continue
+						}
+						results[start+k], done[start+k] = result, true
+						// Results finishing while the run stops are still accounted for
+						if err := check(start+k, result); err != nil {
+							stop(err)
+						}
+					}
+				}
+				mu.Unlock()
+
+				if emit == nil {
+					continue
+				}
+				for i := start; i < end; i++ {
+					// done[i] is only written by this worker
+					if err := emit(i, results[i], done[i]); err != nil {
+						mu.Lock()
+						stop(err)
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for start := 0; start < len(records) && ctx.Err() == nil; start += unitSize {
+		select {
+		case units <- start:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(units)
+	wg.Wait()
+
+	if stopErr == nil && ctx.Err() != nil {
+		// The caller cancelled, e.g. through Stop
+		stopErr = ctx.Err()
+	}
+	return results, done, stopErr
+}
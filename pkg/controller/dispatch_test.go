@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+var errFake = errors.New("fake evaluator failure")
+
+// fakeEvaluator answers records before failAt immediately, fails record
+// failAt, and holds every later record until its context is cancelled
+type fakeEvaluator struct {
+	evaluators.BaseEvaluator
+	failAt    int // negative to never fail
+	held      atomic.Int64
+	cancelled atomic.Int64
+}
+
+func (f *fakeEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (evaluators.Result, error) {
+	var n int
+	fmt.Sscanf(record["text"].(string), "review %d", &n)
+
+	switch {
+	case f.failAt >= 0 && n < f.failAt:
+		return evaluators.Result{Input: record, Output: map[string]interface{}{"response": record["text"]}}, nil
+	case n == f.failAt:
+		return evaluators.Result{Input: record, Error: errFake}, errFake
+	}
+
+	f.held.Add(1)
+	select {
+	case <-ctx.Done():
+		f.cancelled.Add(1)
+		return evaluators.Result{Input: record, Error: ctx.Err()}, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return evaluators.Result{Input: record, Output: map[string]interface{}{"response": record["text"]}}, nil
+	}
+}
+
+func (f *fakeEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]evaluators.Result, error) {
+	results := make([]evaluators.Result, len(records))
+	for i, record := range records {
+		results[i], _ = f.Evaluate(ctx, record, prompt)
+	}
+	return results, nil
+}
+
+// fakeFactory hands out the same evaluator for every provider
+type fakeFactory struct {
+	evaluator evaluators.Evaluator
+}
+
+func (f fakeFactory) CreateEvaluator(provider string, cfg config.EvaluationConfig) (evaluators.Evaluator, error) {
+	return f.evaluator, nil
+}
+
+// waitForGoroutines fails the test unless the goroutine count drops back to baseline
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("Expected no leaked goroutines, got %d over baseline:\n%s",
+				runtime.NumGoroutine()-baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func fakeRecords(n int) []sources.Record {
+	records := make([]sources.Record, n)
+	for i := range records {
+		records[i] = sources.Record{"text": fmt.Sprintf("review %d", i)}
+	}
+	return records
+}
+
+func TestExecute_FailFastDrains(t *testing.T) {
+	const failAt = 5
+	cfg, outputPath := executeConfig(t, fakeRecords(50))
+	cfg.Controls.Concurrency = 4
+
+	evaluator := &fakeEvaluator{failAt: failAt}
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{evaluator})
+
+	baseline := runtime.NumGoroutine()
+	start := time.Now()
+	err := controller.Execute(context.Background(), cfg)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected in-flight requests to be cancelled, Execute took %s", elapsed)
+	}
+
+	var runErr *RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("Expected RunError, got %v", err)
+	}
+	if !errors.Is(err, errFake) || runErr.Input != "reviews" {
+		t.Errorf("Expected the record error for input reviews, got %v", err)
+	}
+	if runErr.Summary.Evaluated != failAt+1 || runErr.Summary.Failed != 1 {
+		t.Errorf("Expected %d records evaluated and 1 failed, got %+v", failAt+1, runErr.Summary)
+	}
+	if got := evaluator.held.Load(); got > int64(cfg.Controls.Concurrency) {
+		t.Errorf("Expected no records dispatched after the failure, got %d held", got)
+	}
+	if held, cancelled := evaluator.held.Load(), evaluator.cancelled.Load(); held != cancelled {
+		t.Errorf("Expected every in-flight record cancelled, got %d of %d", cancelled, held)
+	}
+
+	rows := readOutput(t, outputPath)
+	if len(rows) != failAt {
+		t.Fatalf("Expected the %d completed rows flushed, got %d", failAt, len(rows))
+	}
+	for i, row := range rows {
+		if want := fmt.Sprintf("review %d", i); row["text"] != want {
+			t.Errorf("Expected row %d to be %q, got %v", i, want, row["text"])
+		}
+	}
+
+	waitForGoroutines(t, baseline)
+}
+
+func TestExecute_Stop(t *testing.T) {
+	cfg, _ := executeConfig(t, fakeRecords(10))
+
+	evaluator := &fakeEvaluator{failAt: -1}
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{evaluator})
+
+	baseline := runtime.NumGoroutine()
+	done := make(chan error, 1)
+	go func() { done <- controller.Execute(context.Background(), cfg) }()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if evaluator.held.Load() == int64(cfg.Controls.Concurrency) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d records in flight", cfg.Controls.Concurrency)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := controller.Stop(); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected Execute to return after Stop")
+	}
+	var runErr *RunError
+	if !errors.As(err, &runErr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a RunError wrapping context.Canceled, got %v", err)
+	}
+	if runErr.Summary.Evaluated != 0 {
+		t.Errorf("Expected no records evaluated, got %+v", runErr.Summary)
+	}
+	if got := evaluator.cancelled.Load(); got != int64(cfg.Controls.Concurrency) {
+		t.Errorf("Expected the in-flight records cancelled, got %d", got)
+	}
+
+	waitForGoroutines(t, baseline)
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+)
+
+// ModelPrice is a model's price in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// DefaultModelPrices holds list prices for common models; override them with SetModelPrice
+var DefaultModelPrices = map[string]ModelPrice{
+	"gemini-pro":       {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+	"gemini-1.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+	"gemini-1.5-flash": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+}
+
+// Estimate projects the size and cost of a run without calling any API
+type Estimate struct {
+	Records      int
+	Requests     int
+	PromptTokens int
+	OutputTokens int     // upper bound from params.max_tokens; zero when unset
+	Cost         float64 // USD
+	Priced       bool    // false when a model had no known price, so Cost is incomplete
+}
+
+// Estimate reads every input and projects request count, token usage, and
+// cost. Prompt tokens are extrapolated from the first record of each input.
+func (c *DefaultController) Estimate(ctx context.Context, cfg *config.Config) (Estimate, error) {
+	if cfg == nil {
+		return Estimate{}, fmt.Errorf("config is nil")
+	}
+
+	estimate := Estimate{Priced: true}
+	for i, input := range cfg.Inputs {
+		src, err := c.sources.CreateSource(input.Config, input.Format, input.Schema)
+		if err != nil {
+			return Estimate{}, fmt.Errorf("input[%d]: %w", i, err)
+		}
+		records, err := src.Read(ctx)
+		closeErr := src.Close()
+		if err != nil {
+			return Estimate{}, fmt.Errorf("input[%d]: %w", i, err)
+		}
+		if closeErr != nil {
+			return Estimate{}, fmt.Errorf("input[%d]: %w", i, closeErr)
+		}
+		if len(records) == 0 {
+			continue
+		}
+
+		eval := cfg.EvaluationFor(input)
+		prompt, err := evaluators.RenderPrompt(eval.Prompt, records[0], evaluators.NewRenderOptions(eval))
+		if err != nil {
+			return Estimate{}, fmt.Errorf("input[%d]: %w", i, err)
+		}
+		sampleTokens, err := c.tokenizer.CountTokens(prompt, eval.Model)
+		if err != nil {
+			return Estimate{}, fmt.Errorf("input[%d]: failed to count tokens: %w", i, err)
+		}
+
+		batchSize := eval.BatchSize
+		if batchSize < 1 {
+			batchSize = 1
+		}
+		samples := intParam(eval.Params, "n", 1)
+
+		promptTokens := sampleTokens * len(records)
+		outputTokens := intParam(eval.Params, "max_tokens", 0) * len(records) * samples
+
+		estimate.Records += len(records)
+		estimate.Requests += int(math.Ceil(float64(len(records)) / float64(batchSize)))
+		estimate.PromptTokens += promptTokens
+		estimate.OutputTokens += outputTokens
+
+		price, ok := c.prices[eval.Model]
+		if !ok {
+			estimate.Priced = false
+			continue
+		}
+		estimate.Cost += float64(promptTokens)/1e6*price.InputPerMillion +
+			float64(outputTokens)/1e6*price.OutputPerMillion
+	}
+
+	return estimate, nil
+}
+
+// intParam reads an integer evaluation param, accepting YAML ints and JSON floats
+func intParam(params map[string]interface{}, key string, def int) int {
+	switch v := params[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return def
+	}
+}
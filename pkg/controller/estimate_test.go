@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func writeEstimateInput(t *testing.T, n int) string {
+	t.Helper()
+
+	records := make([]sources.Record, n)
+	for i := range records {
+		records[i] = sources.Record{"text": "the service was quick and friendly"}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		t.Fatalf("Failed to encode records: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	return path
+}
+
+func estimateConfig(path string) *config.Config {
+	return &config.Config{
+		Inputs: []config.InputConfig{{
+			ID:     "reviews",
+			Format: "json",
+			Config: map[string]interface{}{"path": path, "mode": "array"},
+			Schema: config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}},
+		}},
+		Evaluation: config.EvaluationConfig{
+			Provider:  "gemini",
+			Model:     "gemini-1.5-flash",
+			Prompt:    "Classify the sentiment of: {{text}}",
+			Params:    map[string]interface{}{"max_tokens": 10},
+			BatchSize: 4,
+		},
+	}
+}
+
+func TestEstimate_ScalesWithRecordCount(t *testing.T) {
+	controller := NewDefaultController()
+	ctx := context.Background()
+
+	small, err := controller.Estimate(ctx, estimateConfig(writeEstimateInput(t, 8)))
+	if err != nil {
+		t.Fatalf("Failed to estimate: %v", err)
+	}
+	large, err := controller.Estimate(ctx, estimateConfig(writeEstimateInput(t, 16)))
+	if err != nil {
+		t.Fatalf("Failed to estimate: %v", err)
+	}
+
+	if small.Records != 8 || large.Records != 16 {
+		t.Errorf("Expected 8 and 16 records, got %d and %d", small.Records, large.Records)
+	}
+	if small.Requests != 2 || large.Requests != 4 {
+		t.Errorf("Expected 2 and 4 batched requests, got %d and %d", small.Requests, large.Requests)
+	}
+	if small.PromptTokens == 0 || large.PromptTokens != 2*small.PromptTokens {
+		t.Errorf("Expected prompt tokens to double, got %d and %d", small.PromptTokens, large.PromptTokens)
+	}
+	if large.OutputTokens != 160 {
+		t.Errorf("Expected 160 output tokens, got %d", large.OutputTokens)
+	}
+	if !small.Priced || small.Cost <= 0 {
+		t.Fatalf("Expected a priced estimate, got %+v", small)
+	}
+	if diff := large.Cost - 2*small.Cost; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("Expected cost to double, got %v and %v", small.Cost, large.Cost)
+	}
+
+	// Unknown models are still counted but flagged as unpriced
+	cfg := estimateConfig(writeEstimateInput(t, 8))
+	cfg.Evaluation.Model = "custom-model"
+	unpriced, err := controller.Estimate(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to estimate: %v", err)
+	}
+	if unpriced.Priced || unpriced.Cost != 0 || unpriced.PromptTokens != small.PromptTokens {
+		t.Errorf("Expected an unpriced estimate with the same tokens, got %+v", unpriced)
+	}
+}
@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// evaluateInput reads an input, evaluates its records, and returns the output rows.
+// Skipped results are dropped; failed results are dropped with controls.on_error: skip
+// (or written with their error under controls.include_errors_inline) and stop the
+// run otherwise. With controls.max_failures set, the run stops with a
+// TooManyFailuresError once failures exceed it. A stopped run cancels in-flight
+// requests and returns the rows of the records that completed along with the error.
+// Each result's token usage is added to usage when it is not nil. With
+// incremental set, records found in its previous manifest are not evaluated
+// again; their earlier rows are returned in their place. With ordered set, rows
+// are added to it by input position as they complete, nil for records without
+// one, and none are returned.
+func (c *DefaultController) evaluateInput(ctx context.Context, cfg *config.Config, input config.InputConfig, factory evaluators.Factory, summary *RunSummary, usage *UsageReport, incremental *incrementalRun, ordered *OrderedWriter) ([]sources.Record, error) {
+	src, err := c.sources.CreateSource(input.Config, input.Format, input.Schema)
+	if err != nil {
+		return nil, err
+	}
+	var records []sources.Record
+	if cfg.Controls.Deterministic {
+		records, err = sources.OrderedRead(ctx, src, input.Config)
+	} else {
+		records, err = src.Read(ctx)
+	}
+	if closeErr := src.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	eval := cfg.EvaluationFor(input)
+	evaluator, err := factory.CreateEvaluator(eval.Provider, eval)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Records += len(records)
+	maxFailures := cfg.Controls.MaxFailures
+	limit := failureLimit(maxFailures, summary.Records)
+
+	// indexes maps each record to evaluate to its position in the input; rows
+	// holds the row for each position, starting with the reused ones
+	rows := make([]sources.Record, len(records))
+	pending, indexes := records, make([]int, len(records))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var keys []string
+	var reused []int
+	if incremental != nil {
+		prefix, err := incrementalPrefix(input.ID, eval)
+		if err != nil {
+			return nil, err
+		}
+		keys, _, indexes, err = incremental.previous.partition(prefix, records)
+		if err != nil {
+			return nil, err
+		}
+		pending = make([]sources.Record, len(indexes))
+		for k, i := range indexes {
+			pending[k] = records[i]
+		}
+		for i, key := range keys {
+			if row, ok := incremental.previous.Records[key]; ok {
+				rows[i] = copyRecord(row)
+				incremental.current.Records[key] = row
+				reused = append(reused, i)
+			}
+		}
+		summary.Reused += len(records) - len(pending)
+	}
+
+	// Called for each completed result, one at a time
+	check := func(k int, result evaluators.Result) error {
+		i := indexes[k]
+		summary.Evaluated++
+		switch {
+		case result.Skipped:
+			summary.Skipped++
+		case result.Error != nil:
+			summary.Failed++
+			if cfg.Controls.OnError != sources.ErrorPolicySkip {
+				return fmt.Errorf("record %d: %w", i, result.Error)
+			}
+			if cfg.Controls.IncludeErrorsInline {
+				log.Printf("warning: input %s: record %d failed: %v", input.ID, i, result.Error)
+			} else {
+				log.Printf("warning: input %s: skipping record %d: %v", input.ID, i, result.Error)
+			}
+			if maxFailures > 0 && summary.Failed > limit {
+				return &TooManyFailuresError{Limit: limit, Summary: *summary}
+			}
+		}
+		return nil
+	}
+
+	// With ordered output, rows are written as they complete. Every position
+	// is added, even without a row, or the window would never move past it;
+	// writes go on through a stop so completed rows are still written.
+	var emit resultEmit
+	var emitted []bool
+	var reusedErr chan error
+	writeCtx := context.WithoutCancel(ctx)
+	if ordered != nil {
+		emitted = make([]bool, len(pending))
+		emit = func(k int, result evaluators.Result, done bool) error {
+			emitted[k] = true
+			var row sources.Record
+			if done {
+				row = resultRow(cfg, eval, result)
+			}
+			rows[indexes[k]] = row
+			return ordered.Add(writeCtx, indexes[k], copyRecord(row))
+		}
+
+		// Reused rows can wait on the window too, so they are added alongside the workers
+		if len(reused) > 0 {
+			reusedErr = make(chan error, 1)
+			go func() {
+				var addErr error
+				for _, i := range reused {
+					if err := ordered.Add(writeCtx, i, rows[i]); err != nil && addErr == nil {
+						addErr = err
+					}
+				}
+				reusedErr <- addErr
+			}()
+		}
+	}
+
+	results, done, stopErr := evaluateUnits(ctx, evaluator, pending, eval.Prompt, eval.BatchSize, cfg.Controls.Concurrency, check, emit)
+
+	if ordered != nil {
+		// Records never dispatched after a stop
+		for k := range pending {
+			if emitted[k] {
+				continue
+			}
+			if err := ordered.Add(writeCtx, indexes[k], nil); err != nil {
+				stopErr = errors.Join(stopErr, err)
+			}
+		}
+		if reusedErr != nil {
+			if err := <-reusedErr; err != nil {
+				stopErr = errors.Join(stopErr, err)
+			}
+		}
+	}
+
+	for k, result := range results {
+		if !done[k] {
+			continue
+		}
+		i := indexes[k]
+		if usage != nil {
+			if err := usage.Add(input.ID, result, eval); err != nil {
+				return unwritten(rows, ordered), errors.Join(stopErr, fmt.Errorf("record %d: %w", i, err))
+			}
+		}
+		if ordered == nil {
+			rows[i] = resultRow(cfg, eval, result)
+		}
+		// Failed records are evaluated again by the next run
+		if incremental != nil && result.Error == nil {
+			incremental.current.Records[keys[i]] = copyRecord(rows[i])
+		}
+	}
+
+	return unwritten(rows, ordered), stopErr
+}
+
+// resultRow builds the row written for a result, or returns nil when the
+// result is not written: it was skipped, or failed without include_errors_inline
+func resultRow(cfg *config.Config, eval config.EvaluationConfig, result evaluators.Result) sources.Record {
+	if result.Skipped || (result.Error != nil && !cfg.Controls.IncludeErrorsInline) {
+		return nil
+	}
+	row := outputRow(result, eval)
+	served := servedBy(result, eval)
+	for _, field := range cfg.Controls.Stamp {
+		if value, ok := served[field]; ok {
+			row[field] = value
+		}
+	}
+	if cfg.Controls.IncludeErrorsInline {
+		message := ""
+		if result.Error != nil {
+			message = result.Error.Error()
+		}
+		row[cfg.Controls.InlineErrorField()] = message
+	}
+	return row
+}
+
+// unwritten returns the rows left for the caller to write: none when ordered
+// already wrote them, otherwise those of every position that has one, in input order
+func unwritten(rows []sources.Record, ordered *OrderedWriter) []sources.Record {
+	if ordered != nil {
+		return nil
+	}
+	compacted := make([]sources.Record, 0, len(rows))
+	for _, row := range rows {
+		if row != nil {
+			compacted = append(compacted, row)
+		}
+	}
+	return compacted
+}
+
+// outputRow builds the record written for a result: the input fields plus the
+// evaluator output, or only the mapped output fields when mappings.output is set
+func outputRow(result evaluators.Result, eval config.EvaluationConfig) sources.Record {
+	row := make(sources.Record, len(result.Input)+len(result.Output))
+	for k, v := range result.Input {
+		row[k] = v
+	}
+
+	mappings := eval.Mappings.Output
+	if len(mappings) == 0 {
+		for k, v := range result.Output {
+			row[k] = v
+		}
+		return row
+	}
+
+	served := servedBy(result, eval)
+	for field, path := range mappings {
+		if value, ok := served[strings.TrimPrefix(path, "$.")]; ok {
+			row[field] = value
+			continue
+		}
+		if value, ok := lookupOutput(result.Output, path); ok {
+			row[field] = value
+		}
+	}
+	return row
+}
+
+// lookupOutput resolves a mapping path such as $.label or $.scores.overall against
+// the evaluator output, falling back to the parsed JSON response
+func lookupOutput(output map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(strings.TrimPrefix(path, "$."), ".")
+	if value, ok := lookupKeys(output, keys); ok {
+		return value, true
+	}
+	if parsed, ok := output["parsed"].(map[string]interface{}); ok {
+		return lookupKeys(parsed, keys)
+	}
+	return nil, false
+}
+
+// lookupKeys walks nested maps along keys
+func lookupKeys(m map[string]interface{}, keys []string) (interface{}, bool) {
+	var value interface{} = m
+	for _, key := range keys {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = current[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
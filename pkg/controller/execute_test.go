@@ -0,0 +1,715 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// executeConfig returns a passthrough config reading the records from a temp
+// input file, along with the path its output is written to
+func executeConfig(t *testing.T, records []sources.Record) (*config.Config, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	data, err := json.Marshal(records)
+	if err != nil {
+		t.Fatalf("Failed to encode records: %v", err)
+	}
+	inputPath := filepath.Join(dir, "input.json")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	outputPath := filepath.Join(dir, "output.json")
+
+	return &config.Config{
+		Experiment: config.ExperimentConfig{Name: "sentiment", Version: "1.2"},
+		Inputs: []config.InputConfig{{
+			ID:     "reviews",
+			Format: "json",
+			Config: map[string]interface{}{"path": inputPath},
+			Schema: config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}},
+		}},
+		Outputs: []config.OutputConfig{{
+			ID:     "scored",
+			Format: "json",
+			Config: map[string]interface{}{"path": outputPath},
+			Schema: config.SchemaConfig{Fields: []config.FieldConfig{
+				{Name: "text", Type: "string"},
+				{Name: "prompt", Type: "string"},
+			}},
+		}},
+		Evaluation: config.EvaluationConfig{
+			Provider: "passthrough",
+			Strategy: "generation",
+			Prompt:   "Review: {{text}}",
+			Mappings: config.MappingsConfig{Output: map[string]string{"prompt": "$.response"}},
+		},
+		Controls: config.ControlsConfig{Concurrency: 2, OnError: "fail"},
+	}, outputPath
+}
+
+func readOutput(t *testing.T, path string) []sources.Record {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	var records []sources.Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	return records
+}
+
+func TestExecute_WritesMappedRows(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}, {"text": "awful"}})
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+
+	rows := readOutput(t, outputPath)
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 output rows, got %d", len(rows))
+	}
+	if rows[0]["text"] != "great" || rows[0]["prompt"] != "Review: great" {
+		t.Errorf("Expected input field and mapped prompt, got %v", rows[0])
+	}
+	if _, ok := rows[0]["response"]; ok {
+		t.Errorf("Expected unmapped output fields to be dropped, got %v", rows[0])
+	}
+}
+
+func TestExecute_StampsRunFields(t *testing.T) {
+	records := []sources.Record{{"text": "great"}, {"text": "awful"}}
+	cfg, outputPath := executeConfig(t, records)
+	cfg.Controls.Stamp = []string{StampExperimentName, StampVersion, StampRunID, StampTimestamp}
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+
+	rows := readOutput(t, outputPath)
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 output rows, got %d", len(rows))
+	}
+	for i, row := range rows {
+		if row[StampExperimentName] != "sentiment" || row[StampVersion] != "1.2" {
+			t.Errorf("Row %d: expected experiment name and version, got %v", i, row)
+		}
+		timestamp, _ := row[StampTimestamp].(string)
+		if _, err := time.Parse(time.RFC3339, timestamp); err != nil {
+			t.Errorf("Row %d: expected RFC 3339 timestamp, got %q", i, timestamp)
+		}
+	}
+
+	runID, _ := rows[0][StampRunID].(string)
+	if runID == "" || rows[1][StampRunID] != runID {
+		t.Errorf("Expected one run_id shared by every row, got %v and %v", rows[0][StampRunID], rows[1][StampRunID])
+	}
+
+	// A second Execute is a new run
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+	if rerun := readOutput(t, outputPath); rerun[0][StampRunID] == runID {
+		t.Errorf("Expected a new run_id per Execute, got %v again", runID)
+	}
+}
+
+func TestExecute_AbortsOnTooManyFailures(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, `{"error": {"message": "API key not valid"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GEMINI_API_KEY", "bad-key")
+
+	records := make([]sources.Record, 20)
+	for i := range records {
+		records[i] = sources.Record{"text": fmt.Sprintf("review %d", i)}
+	}
+	cfg, _ := executeConfig(t, records)
+	cfg.Evaluation.Provider = "gemini"
+	cfg.Evaluation.Model = "gemini-1.5-flash"
+	cfg.Evaluation.Auth.APIKeyEnv = "TEST_GEMINI_API_KEY"
+	cfg.Evaluation.Params = map[string]interface{}{"base_url": server.URL}
+	cfg.Controls.OnError = "skip"
+	cfg.Controls.MaxFailures = 3
+
+	err := NewDefaultController().Execute(context.Background(), cfg)
+
+	var failures *TooManyFailuresError
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected TooManyFailuresError, got %v", err)
+	}
+	if failures.Limit != 3 || failures.Summary.Failed <= 3 {
+		t.Errorf("Expected more than 3 failures against a limit of 3, got %+v", failures)
+	}
+	if failures.Summary.Records != 20 {
+		t.Errorf("Expected summary of 20 records read, got %d", failures.Summary.Records)
+	}
+	if got := requests.Load(); got >= 20 {
+		t.Errorf("Expected the run to abort before evaluating every record, got %d requests", got)
+	}
+}
+
+func TestFailureLimit(t *testing.T) {
+	tests := []struct {
+		maxFailures float64
+		records     int
+		want        int
+	}{
+		{5, 100, 5},
+		{1, 100, 1},
+		{0.1, 100, 10},
+		{0.25, 10, 2},
+	}
+
+	for _, tt := range tests {
+		if got := failureLimit(tt.maxFailures, tt.records); got != tt.want {
+			t.Errorf("Expected limit %d for max_failures %v over %d records, got %d", tt.want, tt.maxFailures, tt.records, got)
+		}
+	}
+}
+
+func TestExecute_StampsServingModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"candidates": []interface{}{map[string]interface{}{
+				"content": map[string]interface{}{"parts": []interface{}{map[string]interface{}{"text": "positive"}}},
+			}},
+			"modelVersion": "gemini-1.5-flash-002",
+		})
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+
+	cfg, geminiPath := executeConfig(t, []sources.Record{{"text": "great"}, {"text": "awful"}})
+	cfg.Evaluation.Provider = "gemini"
+	cfg.Evaluation.Model = "gemini-1.5-flash"
+	cfg.Evaluation.Auth.APIKeyEnv = "TEST_GEMINI_API_KEY"
+	cfg.Evaluation.Params = map[string]interface{}{"base_url": server.URL}
+	cfg.Evaluation.Mappings.Output = map[string]string{"prompt": "$.response", "served_by": "$._provider"}
+	cfg.Controls.Stamp = []string{StampModel}
+	cfg.Outputs[0].ID = "reviews_scored"
+
+	// A second input overrides the evaluation with a passthrough model
+	passthroughCfg, passthroughPath := executeConfig(t, []sources.Record{{"text": "meh"}})
+	passthroughInput := passthroughCfg.Inputs[0]
+	passthroughInput.ID = "tweets"
+	passthroughInput.Evaluation = &config.EvaluationConfig{Provider: "passthrough", Model: "echo-1"}
+	cfg.Inputs = append(cfg.Inputs, passthroughInput)
+	passthroughOutput := passthroughCfg.Outputs[0]
+	passthroughOutput.ID = "tweets_scored"
+	cfg.Outputs = append(cfg.Outputs, passthroughOutput)
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+
+	for _, row := range readOutput(t, geminiPath) {
+		if row[StampModel] != "gemini-1.5-flash-002" || row["served_by"] != "gemini" {
+			t.Errorf("Expected gemini rows to record the served model, got %v", row)
+		}
+	}
+	for _, row := range readOutput(t, passthroughPath) {
+		if row[StampModel] != "echo-1" || row["served_by"] != "passthrough" {
+			t.Errorf("Expected passthrough rows to record their model, got %v", row)
+		}
+	}
+}
+
+func TestExecute_DeterministicIsReproducible(t *testing.T) {
+	records := fakeRecords(40)
+
+	run := func() []byte {
+		cfg, outputPath := executeConfig(t, records)
+		cfg.Inputs[0].Config["shuffle"] = true
+		cfg.Inputs[0].Config["seed"] = 11
+		cfg.Controls.Deterministic = true
+		cfg.Controls.Concurrency = 8
+
+		if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+			t.Fatalf("Failed to execute: %v", err)
+		}
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return data
+	}
+
+	first, second := run(), run()
+	if string(first) != string(second) {
+		t.Errorf("Expected byte-identical outputs, got:\n%s\nand:\n%s", first, second)
+	}
+}
+
+func TestExecute_DeterministicRejectsUnseededShuffle(t *testing.T) {
+	cfg, outputPath := executeConfig(t, fakeRecords(3))
+	cfg.Inputs[0].Config["shuffle"] = true
+	cfg.Controls.Deterministic = true
+
+	err := NewDefaultController().Execute(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "shuffle requires a seed") {
+		t.Errorf("Expected an unseeded shuffle to be rejected, got %v", err)
+	}
+	if _, statErr := os.Stat(outputPath); statErr == nil {
+		t.Errorf("Expected no output before the run starts")
+	}
+}
+
+func TestExecute_Fsync(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}})
+	cfg.Controls.Fsync = true
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+	if rows := readOutput(t, outputPath); len(rows) != 1 {
+		t.Errorf("Expected 1 row, got %d", len(rows))
+	}
+	if _, ok := cfg.Outputs[0].Config["fsync"]; ok {
+		t.Errorf("Expected the caller's output config to be left unchanged")
+	}
+}
+
+func TestWithFsync(t *testing.T) {
+	if got := withFsync(map[string]interface{}{"path": "out.json"}); got["fsync"] != true || got["path"] != "out.json" {
+		t.Errorf("Expected fsync enabled, got %v", got)
+	}
+	if got := withFsync(map[string]interface{}{"fsync": false}); got["fsync"] != false {
+		t.Errorf("Expected the output's own fsync to win, got %v", got)
+	}
+}
+
+// rejectingEvaluator fails records whose text is "reject" and echoes the rest
+type rejectingEvaluator struct {
+	evaluators.BaseEvaluator
+}
+
+func (r *rejectingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (evaluators.Result, error) {
+	if record["text"] == "reject" {
+		err := fmt.Errorf("model refused the request")
+		return evaluators.Result{Input: record, Error: err}, err
+	}
+	return evaluators.Result{Input: record, Output: map[string]interface{}{"response": "ok"}}, nil
+}
+
+func (r *rejectingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]evaluators.Result, error) {
+	results := make([]evaluators.Result, len(records))
+	for i, record := range records {
+		results[i], _ = r.Evaluate(ctx, record, prompt)
+	}
+	return results, nil
+}
+
+func TestExecute_IncludeErrorsInline(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}, {"text": "reject"}, {"text": "awful"}})
+	cfg.Controls.OnError = "skip"
+	cfg.Controls.IncludeErrorsInline = true
+
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{&rejectingEvaluator{}})
+	if err := controller.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+
+	rows := readOutput(t, outputPath)
+	if len(rows) != 3 {
+		t.Fatalf("Expected all 3 records written, got %d", len(rows))
+	}
+	for i, want := range []string{"", "model refused the request", ""} {
+		if rows[i][config.DefaultErrorField] != want {
+			t.Errorf("Expected row %d %s %q, got %v", i, config.DefaultErrorField, want, rows[i][config.DefaultErrorField])
+		}
+	}
+	if rows[0]["prompt"] != "ok" {
+		t.Errorf("Expected the successful row's output, got %v", rows[0])
+	}
+	if _, ok := rows[1]["prompt"]; ok || rows[1]["text"] != "reject" {
+		t.Errorf("Expected the failed row to carry its input without outputs, got %v", rows[1])
+	}
+	if len(cfg.Outputs[0].Schema.Fields) != 2 {
+		t.Errorf("Expected the caller's output schema to be left unchanged")
+	}
+}
+
+func TestExecute_IncludeErrorsInlineCustomField(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "reject"}})
+	cfg.Outputs[0].Format = "csv"
+	cfg.Outputs[0].Config["path"] = strings.TrimSuffix(outputPath, ".json") + ".csv"
+	cfg.Controls.OnError = "skip"
+	cfg.Controls.IncludeErrorsInline = true
+	cfg.Controls.ErrorField = "failure"
+
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{&rejectingEvaluator{}})
+	if err := controller.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.Outputs[0].Config["path"].(string))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	want := "text,prompt,failure\nreject,,model refused the request\n"
+	if string(data) != want {
+		t.Errorf("Expected CSV %q, got %q", want, string(data))
+	}
+}
+
+func TestExecute_WritesEmptyOutputs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"json", "[\n\n]"},
+		{"csv", "text,prompt\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}})
+			// Every record is filtered out before evaluation
+			cfg.Inputs[0].Config["filter"] = map[string]interface{}{"text": "missing"}
+			cfg.Outputs[0].Format = tt.format
+			cfg.Outputs[0].Config["path"] = strings.TrimSuffix(outputPath, ".json") + "." + tt.format
+
+			if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+				t.Fatalf("Failed to execute: %v", err)
+			}
+
+			data, err := os.ReadFile(cfg.Outputs[0].Config["path"].(string))
+			if err != nil {
+				t.Fatalf("Expected an empty output file, got %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, data)
+			}
+		})
+	}
+}
+
+func TestExecute_RetriesTransientFailures(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "positive"}]}}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}})
+	cfg.Evaluation.Provider = "gemini"
+	cfg.Evaluation.Model = "gemini-1.5-flash"
+	cfg.Evaluation.Strategy = "classification"
+	cfg.Evaluation.Auth.APIKeyEnv = "TEST_GEMINI_API_KEY"
+	cfg.Evaluation.Params = map[string]interface{}{"base_url": server.URL}
+	cfg.Controls.Retries = 1
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Expected the retry to recover the record, got %v", err)
+	}
+	rows := readOutput(t, outputPath)
+	if len(rows) != 1 || rows[0]["prompt"] != "positive" {
+		t.Errorf("Expected the retried record written, got %v", rows)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 requests, got %d", got)
+	}
+}
+
+// recordingEvaluator echoes each record's text and records the texts it evaluated
+type recordingEvaluator struct {
+	evaluators.BaseEvaluator
+	mu        sync.Mutex
+	evaluated []string
+}
+
+func (r *recordingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (evaluators.Result, error) {
+	r.mu.Lock()
+	r.evaluated = append(r.evaluated, record["text"].(string))
+	r.mu.Unlock()
+	return evaluators.Result{Input: record, Output: map[string]interface{}{"response": record["text"]}}, nil
+}
+
+func (r *recordingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]evaluators.Result, error) {
+	results := make([]evaluators.Result, len(records))
+	for i, record := range records {
+		results[i], _ = r.Evaluate(ctx, record, prompt)
+	}
+	return results, nil
+}
+
+func TestExecute_Incremental(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "one"}, {"text": "two"}, {"text": "three"}})
+	cfg.Controls.ManifestPath = filepath.Join(t.TempDir(), "incremental.json")
+
+	first := &recordingEvaluator{}
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{first})
+	if err := controller.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	if len(first.evaluated) != 3 {
+		t.Fatalf("Expected 3 records evaluated on the first run, got %v", first.evaluated)
+	}
+
+	// Change the second record only
+	data, err := json.Marshal([]sources.Record{{"text": "one"}, {"text": "two (edited)"}, {"text": "three"}})
+	if err != nil {
+		t.Fatalf("Failed to encode records: %v", err)
+	}
+	if err := os.WriteFile(cfg.Inputs[0].Config["path"].(string), data, 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	second := &recordingEvaluator{}
+	controller.SetEvaluatorFactory(fakeFactory{second})
+	if err := controller.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if len(second.evaluated) != 1 || second.evaluated[0] != "two (edited)" {
+		t.Errorf("Expected only the changed record re-evaluated, got %v", second.evaluated)
+	}
+
+	rows := readOutput(t, outputPath)
+	want := []string{"one", "two (edited)", "three"}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d output rows, got %d", len(want), len(rows))
+	}
+	for i, text := range want {
+		if rows[i]["text"] != text || rows[i]["prompt"] != text {
+			t.Errorf("Row %d: expected %q carried through in input order, got %v", i, text, rows[i])
+		}
+	}
+
+	manifest, err := LoadManifest(cfg.Controls.ManifestPath)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if len(manifest.Records) != 3 {
+		t.Errorf("Expected the manifest to keep only this run's 3 records, got %d", len(manifest.Records))
+	}
+
+	// A new prompt invalidates every saved row
+	cfg.Evaluation.Prompt = "Sentiment of: {{text}}"
+	third := &recordingEvaluator{}
+	controller.SetEvaluatorFactory(fakeFactory{third})
+	if err := controller.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Third run failed: %v", err)
+	}
+	if len(third.evaluated) != 3 {
+		t.Errorf("Expected every record re-evaluated after the prompt changed, got %v", third.evaluated)
+	}
+}
+
+// streamingEvaluator completes records in reverse order of their index and
+// holds the last one until the rows before it reach the output file
+type streamingEvaluator struct {
+	evaluators.BaseEvaluator
+	count      int
+	outputPath string
+	streamed   atomic.Bool
+}
+
+func (s *streamingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (evaluators.Result, error) {
+	var n int
+	fmt.Sscanf(record["text"].(string), "review %d", &n)
+	if n < s.count-1 {
+		time.Sleep(time.Duration(s.count-n) * 5 * time.Millisecond)
+	} else {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			data, _ := os.ReadFile(s.outputPath)
+			if strings.Count(string(data), "\n") == s.count-1 {
+				s.streamed.Store(true)
+				break
+			}
+		}
+	}
+	return evaluators.Result{Input: record, Output: map[string]interface{}{"response": record["text"]}}, nil
+}
+
+func (s *streamingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]evaluators.Result, error) {
+	results := make([]evaluators.Result, len(records))
+	for i, record := range records {
+		results[i], _ = s.Evaluate(ctx, record, prompt)
+	}
+	return results, nil
+}
+
+func TestExecute_OrderedOutput(t *testing.T) {
+	const count = 6
+	cfg, outputPath := executeConfig(t, fakeRecords(count))
+	cfg.Outputs[0].Config["mode"] = "lines"
+	cfg.Controls.Concurrency = 3
+	cfg.Controls.OrderedOutput = true
+	cfg.Controls.ReorderWindow = 2
+
+	evaluator := &streamingEvaluator{count: count, outputPath: outputPath}
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{evaluator})
+	if err := controller.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+
+	if !evaluator.streamed.Load() {
+		t.Error("Expected earlier rows written while the last record was still being evaluated")
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != count {
+		t.Fatalf("Expected %d output rows, got %d", count, len(lines))
+	}
+	for i, line := range lines {
+		var row sources.Record
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			t.Fatalf("Failed to decode row %d: %v", i, err)
+		}
+		if want := fmt.Sprintf("review %d", i); row["text"] != want {
+			t.Errorf("Expected %q at position %d, got %v", want, i, row["text"])
+		}
+	}
+}
+
+func TestExecute_OrderedOutputFailFast(t *testing.T) {
+	const failAt = 5
+	cfg, outputPath := executeConfig(t, fakeRecords(50))
+	cfg.Controls.Concurrency = 4
+	cfg.Controls.OrderedOutput = true
+	cfg.Controls.ReorderWindow = 2
+
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{&fakeEvaluator{failAt: failAt}})
+	if err := controller.Execute(context.Background(), cfg); !errors.Is(err, errFake) {
+		t.Fatalf("Expected the record error, got %v", err)
+	}
+
+	// Records completed before the failure are written in order
+	rows := readOutput(t, outputPath)
+	if len(rows) != failAt {
+		t.Fatalf("Expected %d output rows, got %d", failAt, len(rows))
+	}
+	for i, row := range rows {
+		if want := fmt.Sprintf("review %d", i); row["text"] != want {
+			t.Errorf("Expected %q at position %d, got %v", want, i, row["text"])
+		}
+	}
+}
+
+func TestExecute_RunControlsTogether(t *testing.T) {
+	cfg, outputPath := executeConfig(t, fakeRecords(4))
+	dir := t.TempDir()
+	cfg.Controls.ManifestPath = filepath.Join(dir, "incremental.json")
+	cfg.Controls.Manifest = filepath.Join(dir, "manifest.json")
+	cfg.Controls.OrderedOutput = true
+	cfg.Controls.ReorderWindow = 2
+	cfg.Controls.Metrics = true
+	cfg.Controls.Stamp = []string{StampRunID}
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	firstRunID := readOutput(t, outputPath)[0][StampRunID]
+
+	// Append a record, the only one the second run evaluates
+	data, err := json.Marshal(fakeRecords(5))
+	if err != nil {
+		t.Fatalf("Failed to encode records: %v", err)
+	}
+	if err := os.WriteFile(cfg.Inputs[0].Config["path"].(string), data, 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+
+	rows := readOutput(t, outputPath)
+	if len(rows) != 5 {
+		t.Fatalf("Expected 5 output rows, got %d", len(rows))
+	}
+	runID := rows[0][StampRunID]
+	for i, row := range rows {
+		if want := fmt.Sprintf("review %d", i); row["text"] != want || row["prompt"] != "Review: "+want {
+			t.Errorf("Expected %q at position %d, got %v", want, i, row)
+		}
+		// Reused rows are stamped by the run writing them
+		if row[StampRunID] != runID || runID == firstRunID {
+			t.Errorf("Row %d: expected this run's run_id %v, got %v", i, runID, row[StampRunID])
+		}
+	}
+
+	saved := readRunManifest(t, cfg.Controls.Manifest)
+	if saved.Metrics["reused"] != 4 || saved.Metrics["evaluated"] != 1 || saved.Metrics["evaluator_records"] != 1 {
+		t.Errorf("Expected 4 reused records and 1 evaluated, got %v", saved.Metrics)
+	}
+	if len(saved.Outputs) != 1 || saved.Outputs[0].Records != 5 {
+		t.Errorf("Expected 5 rows written to the output, got %+v", saved.Outputs)
+	}
+
+	// The incremental manifest keeps the rows unstamped
+	incremental, err := LoadManifest(cfg.Controls.ManifestPath)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	for hash, row := range incremental.Records {
+		if _, ok := row[StampRunID]; ok {
+			t.Errorf("Expected no run_id in the saved row for %s, got %v", hash, row)
+		}
+	}
+}
+
+func TestExecute_IncrementalPerInputEvaluation(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}})
+	cfg.Controls.ManifestPath = filepath.Join(t.TempDir(), "incremental.json")
+
+	// A second input reads the same records with its own prompt
+	other := cfg.Inputs[0]
+	other.ID = "other"
+	other.Evaluation = &config.EvaluationConfig{Prompt: "Other: {{text}}"}
+	cfg.Inputs = append(cfg.Inputs, other)
+	cfg.Outputs[0].ID = "reviews_scored"
+	otherOutput := cfg.Outputs[0]
+	otherOutput.ID = "other_scored"
+	otherPath := filepath.Join(filepath.Dir(outputPath), "other.json")
+	otherOutput.Config = map[string]interface{}{"path": otherPath}
+	cfg.Outputs = append(cfg.Outputs, otherOutput)
+
+	for run := 1; run <= 2; run++ {
+		if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+			t.Fatalf("Run %d failed: %v", run, err)
+		}
+		if rows := readOutput(t, outputPath); len(rows) != 1 || rows[0]["prompt"] != "Review: great" {
+			t.Errorf("Run %d: expected the reviews prompt, got %v", run, rows)
+		}
+		if rows := readOutput(t, otherPath); len(rows) != 1 || rows[0]["prompt"] != "Other: great" {
+			t.Errorf("Run %d: expected the other input's prompt, got %v", run, rows)
+		}
+	}
+}
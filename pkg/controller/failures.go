@@ -0,0 +1,50 @@
+package controller
+
+import "fmt"
+
+// RunSummary counts what happened to the records of a run
+type RunSummary struct {
+	Records   int // records read so far
+	Evaluated int // records with an evaluation result
+	Failed    int // results with an error
+	Skipped   int // results the evaluator asked not to write
+	Reused    int // records not evaluated again because controls.manifest_path had their output
+}
+
+// TooManyFailuresError reports a run aborted because failures exceeded controls.max_failures
+type TooManyFailuresError struct {
+	Limit   int        // failures allowed when the run aborted
+	Summary RunSummary // counts up to the abort
+}
+
+func (e *TooManyFailuresError) Error() string {
+	return fmt.Sprintf("too many failures: %d of %d evaluated records failed, exceeding max_failures of %d",
+		e.Summary.Failed, e.Summary.Evaluated, e.Limit)
+}
+
+// RunError reports a run stopped partway, by a fatal record error, a failed
+// write, or cancellation, with the counts up to the stop. Rows of records that
+// completed before the stop have been written.
+type RunError struct {
+	Input   string // input being evaluated when the run stopped
+	Err     error
+	Summary RunSummary // counts up to the stop
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("input %s: %v (%d of %d records evaluated, %d failed)",
+		e.Input, e.Err, e.Summary.Evaluated, e.Summary.Records, e.Summary.Failed)
+}
+
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// failureLimit returns how many failures are allowed among records: max_failures
+// itself when it is 1 or more, otherwise that fraction of the records
+func failureLimit(maxFailures float64, records int) int {
+	if maxFailures >= 1 {
+		return int(maxFailures)
+	}
+	return int(maxFailures * float64(records))
+}
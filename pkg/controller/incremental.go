@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// Manifest maps input record hashes, prefixed by the input and evaluation under
+// Execute, to the output records produced for them
+type Manifest struct {
+	Records map[string]sources.Record `json:"records"`
+}
+
+// EvaluateFunc evaluates input records and returns one output record per input, in order
+type EvaluateFunc func(ctx context.Context, records []sources.Record) ([]sources.Record, error)
+
+// NewManifest creates an empty manifest
+func NewManifest() *Manifest {
+	return &Manifest{Records: make(map[string]sources.Record)}
+}
+
+// LoadManifest reads a manifest from path, returning an empty manifest if the file does not exist
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	manifest := NewManifest()
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest %s: %w", path, err)
+	}
+	if manifest.Records == nil {
+		manifest.Records = make(map[string]sources.Record)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest to path, creating the directory if needed
+func (m *Manifest) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// HashRecord returns a stable content hash of a record
+func HashRecord(record sources.Record) (string, error) {
+	// encoding/json sorts map keys, so the encoding is canonical
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// EvaluateIncremental evaluates only records whose hash is not in the previous
+// manifest and passes prior outputs through for the rest. Outputs are returned
+// in input order together with the manifest for this run.
+func EvaluateIncremental(ctx context.Context, records []sources.Record, previous *Manifest, evaluate EvaluateFunc) ([]sources.Record, *Manifest, error) {
+	if previous == nil {
+		previous = NewManifest()
+	}
+
+	hashes, outputs, pendingIndexes, err := previous.partition("", records)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(pendingIndexes) > 0 {
+		pending := make([]sources.Record, len(pendingIndexes))
+		for i, index := range pendingIndexes {
+			pending[i] = records[index]
+		}
+		evaluated, err := evaluate(ctx, pending)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(evaluated) != len(pending) {
+			return nil, nil, fmt.Errorf("evaluate returned %d outputs for %d records", len(evaluated), len(pending))
+		}
+		for i, index := range pendingIndexes {
+			outputs[index] = evaluated[i]
+		}
+	}
+
+	// Only keep entries for records present in this run
+	current := NewManifest()
+	for i, hash := range hashes {
+		current.Records[hash] = outputs[i]
+	}
+
+	return outputs, current, nil
+}
+
+// partition hashes records and looks each one up in the manifest under prefix
+// plus its hash. It returns the keys, the prior outputs by input position, and
+// the indexes of the records with no prior output, in order.
+func (m *Manifest) partition(prefix string, records []sources.Record) ([]string, []sources.Record, []int, error) {
+	hashes := make([]string, len(records))
+	outputs := make([]sources.Record, len(records))
+	var pendingIndexes []int
+
+	for i, record := range records {
+		hash, err := HashRecord(record)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		hashes[i] = prefix + hash
+
+		if prior, ok := m.Records[hashes[i]]; ok {
+			outputs[i] = prior
+			continue
+		}
+		pendingIndexes = append(pendingIndexes, i)
+	}
+
+	return hashes, outputs, pendingIndexes, nil
+}
+
+// incrementalPrefix scopes the manifest keys of an input's records to the input
+// and its evaluation, so a record is evaluated again once its prompt, model,
+// strategy, params or mappings change, and identical records of inputs with
+// different evaluations do not share an output
+func incrementalPrefix(inputID string, eval config.EvaluationConfig) (string, error) {
+	data, err := json.Marshal(eval)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode evaluation: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return inputID + "/" + hex.EncodeToString(sum[:]) + "/", nil
+}
+
+// incrementalRun carries controls.manifest_path through an Execute: previous
+// is the manifest of the last run and current collects the entries of this one
+type incrementalRun struct {
+	previous *Manifest
+	current  *Manifest
+}
+
+// loadIncrementalRun starts an incremental run from the manifest at path
+func loadIncrementalRun(path string) (*incrementalRun, error) {
+	previous, err := LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	return &incrementalRun{previous: previous, current: NewManifest()}, nil
+}
+
+// save writes the manifest for the next run to path. A completed run keeps
+// only the records it saw; a failed one also keeps the previous entries, so
+// records it never reached are not re-evaluated next time.
+func (r *incrementalRun) save(path string, failed bool) error {
+	manifest := r.current
+	if failed {
+		manifest = NewManifest()
+		for hash, record := range r.previous.Records {
+			manifest.Records[hash] = record
+		}
+		for hash, record := range r.current.Records {
+			manifest.Records[hash] = record
+		}
+	}
+	return manifest.Save(path)
+}
+
+// copyRecord returns a shallow copy of a record, or nil for nil
+func copyRecord(record sources.Record) sources.Record {
+	if record == nil {
+		return nil
+	}
+	copied := make(sources.Record, len(record))
+	for k, v := range record {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestEvaluateIncremental_OnlyChangedRecords(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+
+	var evaluated []sources.Record
+	evaluate := func(ctx context.Context, records []sources.Record) ([]sources.Record, error) {
+		evaluated = append(evaluated, records...)
+		outputs := make([]sources.Record, len(records))
+		for i, record := range records {
+			outputs[i] = sources.Record{"text": record["text"], "label": "evaluated"}
+		}
+		return outputs, nil
+	}
+
+	firstRun := []sources.Record{
+		{"text": "one"},
+		{"text": "two"},
+		{"text": "three"},
+	}
+
+	previous, err := LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to load missing manifest: %v", err)
+	}
+
+	_, manifest, err := EvaluateIncremental(context.Background(), firstRun, previous, evaluate)
+	if err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	if len(evaluated) != 3 {
+		t.Fatalf("Expected 3 records evaluated on first run, got %d", len(evaluated))
+	}
+	if err := manifest.Save(manifestPath); err != nil {
+		t.Fatalf("Failed to save manifest: %v", err)
+	}
+
+	// Change the second record only
+	secondRun := []sources.Record{
+		{"text": "one"},
+		{"text": "two (edited)"},
+		{"text": "three"},
+	}
+
+	previous, err = LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	evaluated = nil
+	outputs, _, err := EvaluateIncremental(context.Background(), secondRun, previous, evaluate)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+
+	if len(evaluated) != 1 || evaluated[0]["text"] != "two (edited)" {
+		t.Fatalf("Expected only the changed record to be evaluated, got %v", evaluated)
+	}
+
+	if len(outputs) != 3 {
+		t.Fatalf("Expected 3 outputs, got %d", len(outputs))
+	}
+
+	for i, want := range []string{"one", "two (edited)", "three"} {
+		if outputs[i]["text"] != want {
+			t.Errorf("Expected output %d text %s, got %v", i, want, outputs[i]["text"])
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// DefaultReorderWindow is used when controls.reorder_window is not set
+const DefaultReorderWindow = 256
+
+// OrderedWriter writes results to a source in input order while they complete out of order.
+//
+// Results are buffered in a reorder window keyed by record index and flushed as
+// soon as the next expected index arrives. The window bounds memory: a result more
+// than window indexes ahead of the next expected one blocks its caller until the
+// head catches up. This is head-of-line blocking, so one slow record can stall the
+// workers holding later records once the window fills.
+type OrderedWriter struct {
+	mu       sync.Mutex
+	dst      sources.Source
+	window   int
+	next     int
+	pending  map[int]sources.Record
+	advanced chan struct{} // closed and replaced whenever next moves forward
+}
+
+// NewOrderedWriter creates an ordered writer over dst with the given reorder window
+func NewOrderedWriter(dst sources.Source, window int) *OrderedWriter {
+	if window <= 0 {
+		window = DefaultReorderWindow
+	}
+	return &OrderedWriter{
+		dst:      dst,
+		window:   window,
+		pending:  make(map[int]sources.Record),
+		advanced: make(chan struct{}),
+	}
+}
+
+// Add submits the result for the record at index, blocking while index is outside the window.
+// A nil record advances the window past index without writing anything.
+func (o *OrderedWriter) Add(ctx context.Context, index int, record sources.Record) error {
+	for {
+		o.mu.Lock()
+		if _, exists := o.pending[index]; exists || index < o.next {
+			o.mu.Unlock()
+			return fmt.Errorf("duplicate result for record %d", index)
+		}
+
+		if index < o.next+o.window {
+			o.pending[index] = record
+			err := o.flush(ctx)
+			o.mu.Unlock()
+			return err
+		}
+
+		advanced := o.advanced
+		o.mu.Unlock()
+
+		select {
+		case <-advanced:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Pending returns the number of results buffered waiting for earlier indexes
+func (o *OrderedWriter) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// flush writes every consecutive buffered result starting at next; callers must hold mu
+func (o *OrderedWriter) flush(ctx context.Context) error {
+	start := o.next
+	var batch []sources.Record
+	for {
+		record, ok := o.pending[o.next]
+		if !ok {
+			break
+		}
+		if record != nil {
+			batch = append(batch, record)
+		}
+		delete(o.pending, o.next)
+		o.next++
+	}
+
+	if o.next == start {
+		return nil
+	}
+
+	close(o.advanced)
+	o.advanced = make(chan struct{})
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return o.dst.Write(ctx, batch)
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+type recordingSource struct {
+	sources.BaseSource
+	mu      sync.Mutex
+	records []sources.Record
+}
+
+func (r *recordingSource) Read(ctx context.Context) ([]sources.Record, error) { return nil, nil }
+
+func (r *recordingSource) Write(ctx context.Context, records []sources.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, records...)
+	return nil
+}
+
+func (r *recordingSource) Close() error { return nil }
+
+func TestOrderedWriter_PreservesInputOrder(t *testing.T) {
+	dst := &recordingSource{}
+	writer := NewOrderedWriter(dst, 3)
+
+	const count = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, count)
+
+	// Later records complete first
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			time.Sleep(time.Duration(count-index) * time.Millisecond)
+			errs <- writer.Add(context.Background(), index, sources.Record{"index": index})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	if len(dst.records) != count {
+		t.Fatalf("Expected %d written records, got %d", count, len(dst.records))
+	}
+
+	for i, record := range dst.records {
+		if record["index"] != i {
+			t.Fatalf("Expected record %d at position %d, got %v", i, i, record["index"])
+		}
+	}
+
+	if writer.Pending() != 0 {
+		t.Errorf("Expected empty buffer, got %d pending", writer.Pending())
+	}
+}
+
+func TestOrderedWriter_BlocksOutsideWindow(t *testing.T) {
+	writer := NewOrderedWriter(&recordingSource{}, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := writer.Add(ctx, 5, sources.Record{}); err != context.DeadlineExceeded {
+		t.Errorf("Expected record outside the window to block until deadline, got %v", err)
+	}
+}
+
+func TestOrderedWriter_NilAdvancesWindow(t *testing.T) {
+	dst := &recordingSource{}
+	writer := NewOrderedWriter(dst, 1)
+
+	if err := writer.Add(context.Background(), 0, nil); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := writer.Add(context.Background(), 1, sources.Record{"index": 1}); err != nil {
+		t.Fatalf("Expected the window to move past a nil record, got %v", err)
+	}
+
+	if len(dst.records) != 1 || dst.records[0]["index"] != 1 {
+		t.Errorf("Expected only the non-nil record written, got %v", dst.records)
+	}
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// Preflight checks the configuration for problems that would otherwise only
+// surface deep into a run, such as output directories that are not writable
+// and, with controls.deterministic, inputs whose record order is not stable
+func Preflight(cfg *config.Config) error {
+	if cfg == nil {
+		return fmt.Errorf("config is nil")
+	}
+
+	if cfg.Controls.Deterministic {
+		for i, input := range cfg.Inputs {
+			if err := sources.CheckDeterministic(input.Config); err != nil {
+				return fmt.Errorf("input[%d]: controls.deterministic: %w", i, err)
+			}
+		}
+	}
+
+	for i, output := range cfg.Outputs {
+		path, ok := output.Config["path"].(string)
+		if !ok {
+			return fmt.Errorf("output[%d]: config.path is required", i)
+		}
+
+		if sources.IsHTTPPath(path) {
+			return fmt.Errorf("output[%d]: cannot write to %s: HTTP sources are read-only", i, path)
+		}
+		if sources.IsS3Path(path) || sources.IsStdioPath(path) {
+			// Checked by the upload itself, or not a file at all
+			continue
+		}
+
+		if split, _ := output.Config["split"].(string); split == sources.SplitPerRecord {
+			// path is the directory per-record files are written into
+			path = filepath.Join(path, sources.DefaultFilenameTemplate)
+		}
+
+		if err := sources.CheckWritable(path); err != nil {
+			return fmt.Errorf("output[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// Router decides which outputs receive the results of each input.
+//
+// Routing rules, in order of precedence:
+//  1. An output with an explicit `input` receives only that input's results.
+//  2. Otherwise an output whose ID equals an input ID, or starts with an input ID
+//     followed by "_" (e.g. `reviews_scored` for input `reviews`), receives only
+//     that input's results. When several input IDs match, the longest one wins.
+//  3. Outputs matched by neither rule receive the results of every input.
+type Router struct {
+	routes    map[string][]string // input ID -> output IDs
+	broadcast []string            // outputs receiving every input
+	idField   string
+}
+
+// NewRouter builds the routing table for a config
+func NewRouter(cfg *config.Config) *Router {
+	r := &Router{
+		routes:  make(map[string][]string),
+		idField: cfg.Controls.InputIDField,
+	}
+
+	for _, output := range cfg.Outputs {
+		inputID := cfg.RoutedInput(output)
+		if inputID == "" {
+			r.broadcast = append(r.broadcast, output.ID)
+			continue
+		}
+		r.routes[inputID] = append(r.routes[inputID], output.ID)
+	}
+
+	return r
+}
+
+// Outputs returns the IDs of the outputs that receive the input's results
+func (r *Router) Outputs(inputID string) []string {
+	outputs := make([]string, 0, len(r.routes[inputID])+len(r.broadcast))
+	outputs = append(outputs, r.routes[inputID]...)
+	return append(outputs, r.broadcast...)
+}
+
+// Route groups an input's results by destination output. When
+// controls.input_id_field is set, each result is stamped with the input ID.
+func (r *Router) Route(inputID string, records []sources.Record) map[string][]sources.Record {
+	if r.idField != "" {
+		stamped := make([]sources.Record, len(records))
+		for i, record := range records {
+			copied := make(sources.Record, len(record)+1)
+			for k, v := range record {
+				copied[k] = v
+			}
+			copied[r.idField] = inputID
+			stamped[i] = copied
+		}
+		records = stamped
+	}
+
+	routed := make(map[string][]sources.Record)
+	for _, outputID := range r.Outputs(inputID) {
+		routed[outputID] = records
+	}
+	return routed
+}
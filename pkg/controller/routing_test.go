@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestRouter_RoutesByInputID(t *testing.T) {
+	cfg := &config.Config{
+		Inputs: []config.InputConfig{
+			{ID: "reviews"},
+			{ID: "reviews_fr"},
+			{ID: "tickets"},
+		},
+		Outputs: []config.OutputConfig{
+			{ID: "reviews_scored"},
+			{ID: "reviews_fr_scored"},
+			{ID: "triage", Input: "tickets"},
+			{ID: "all_results"},
+		},
+		Controls: config.ControlsConfig{InputIDField: "source_input"},
+	}
+
+	router := NewRouter(cfg)
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"reviews", []string{"reviews_scored", "all_results"}},
+		{"reviews_fr", []string{"reviews_fr_scored", "all_results"}},
+		{"tickets", []string{"triage", "all_results"}},
+	}
+
+	for _, tt := range tests {
+		got := router.Outputs(tt.input)
+		if len(got) != len(tt.want) {
+			t.Errorf("Expected outputs %v for %s, got %v", tt.want, tt.input, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Expected outputs %v for %s, got %v", tt.want, tt.input, got)
+				break
+			}
+		}
+	}
+
+	records := []sources.Record{{"text": "great"}}
+	routed := router.Route("tickets", records)
+
+	if _, ok := routed["reviews_scored"]; ok {
+		t.Error("Expected tickets results not to reach reviews_scored")
+	}
+	triage := routed["triage"]
+	if len(triage) != 1 || triage[0]["source_input"] != "tickets" {
+		t.Errorf("Expected triage record stamped with source_input tickets, got %v", triage)
+	}
+	if _, ok := records[0]["source_input"]; ok {
+		t.Error("Expected input records to be left unstamped")
+	}
+}
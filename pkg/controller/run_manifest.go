@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// RunManifest summarizes a run so it can be audited and reproduced
+type RunManifest struct {
+	ConfigHash      string             `json:"config_hash"`
+	StartedAt       time.Time          `json:"started_at"`
+	FinishedAt      time.Time          `json:"finished_at"`
+	DurationSeconds float64            `json:"duration_seconds"`
+	Inputs          []InputSummary     `json:"inputs"`
+	Outputs         []OutputSummary    `json:"outputs"`
+	Metrics         map[string]float64 `json:"metrics,omitempty"`
+	Error           string             `json:"error,omitempty"` // why the run failed, empty when it completed
+}
+
+// InputSummary records the files and records processed for an input
+type InputSummary struct {
+	ID      string   `json:"id"`
+	Files   []string `json:"files"`
+	Records int      `json:"records"`
+}
+
+// OutputSummary records an output file and its checksum
+type OutputSummary struct {
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256,omitempty"` // empty for outputs that are not local files
+}
+
+// NewRunManifest starts a manifest for a run of cfg
+func NewRunManifest(cfg *config.Config) (*RunManifest, error) {
+	hash, err := HashConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RunManifest{
+		ConfigHash: hash,
+		StartedAt:  time.Now().UTC(),
+		Metrics:    make(map[string]float64),
+	}, nil
+}
+
+// AddInput records an input that was processed
+func (m *RunManifest) AddInput(id string, files []string, records int) {
+	m.Inputs = append(m.Inputs, InputSummary{ID: id, Files: files, Records: records})
+}
+
+// AddOutput records an output file, computing its checksum
+func (m *RunManifest) AddOutput(id, path string, records int) error {
+	checksum, err := FileChecksum(path)
+	if err != nil {
+		return err
+	}
+
+	m.Outputs = append(m.Outputs, OutputSummary{ID: id, Path: path, Records: records, SHA256: checksum})
+	return nil
+}
+
+// Finish records the end time and duration of the run
+func (m *RunManifest) Finish() {
+	m.FinishedAt = time.Now().UTC()
+	m.DurationSeconds = m.FinishedAt.Sub(m.StartedAt).Seconds()
+}
+
+// finishRun completes the manifest of an Execute: every output with the rows
+// written to it, the run summary and evaluator call metrics, when collected, as
+// metrics, and runErr when the run failed
+func (m *RunManifest) finishRun(cfg *config.Config, summary RunSummary, metrics *evaluators.Metrics, written map[string]int, runErr error) {
+	for _, output := range cfg.Outputs {
+		path, _ := output.Config["path"].(string)
+		if path == "" || sources.IsStdioPath(path) || sources.IsS3Path(path) || sources.IsHTTPPath(path) {
+			m.Outputs = append(m.Outputs, OutputSummary{ID: output.ID, Path: path, Records: written[output.ID]})
+			continue
+		}
+		if err := m.AddOutput(output.ID, path, written[output.ID]); err != nil {
+			log.Printf("warning: run manifest: output %s: %v", output.ID, err)
+			m.Outputs = append(m.Outputs, OutputSummary{ID: output.ID, Path: path, Records: written[output.ID]})
+		}
+	}
+
+	m.Metrics["records"] = float64(summary.Records)
+	m.Metrics["evaluated"] = float64(summary.Evaluated)
+	m.Metrics["failed"] = float64(summary.Failed)
+	m.Metrics["skipped"] = float64(summary.Skipped)
+	m.Metrics["reused"] = float64(summary.Reused)
+	if metrics != nil {
+		m.Metrics["evaluator_calls"] = float64(metrics.Calls())
+		m.Metrics["evaluator_records"] = float64(metrics.Records())
+		m.Metrics["evaluator_errors"] = float64(metrics.Errors())
+		m.Metrics["evaluator_latency_seconds"] = metrics.Latency().Seconds()
+	}
+	if runErr != nil {
+		m.Error = runErr.Error()
+	}
+	m.Finish()
+}
+
+// inputFiles lists the files an input reads, for the run manifest. Paths that
+// are not local files, such as stdin, S3, or HTTP(S), are listed as written.
+func inputFiles(input config.InputConfig) []string {
+	path, _ := input.Config["path"].(string)
+	if path == "" {
+		return nil
+	}
+	if sources.IsStdioPath(path) || sources.IsS3Path(path) || sources.IsHTTPPath(path) {
+		return []string{path}
+	}
+
+	recursive, _ := input.Config["recursive"].(bool)
+	sortBy, _ := input.Config["sort"].(string)
+	files, err := sources.ResolveFiles(path, sources.FileOptions{Recursive: recursive, Sort: sortBy, OnMissing: sources.MissingSkip})
+	if err != nil || len(files) == 0 {
+		return []string{path}
+	}
+	return files
+}
+
+// Save writes the manifest as JSON to path, creating the directory if needed
+func (m *RunManifest) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run manifest %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// HashConfig returns a stable hash of the configuration
+func HashConfig(cfg *config.Config) (string, error) {
+	if cfg == nil {
+		return "", fmt.Errorf("config is nil")
+	}
+
+	// encoding/json sorts map keys, so the encoding is canonical
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FileChecksum returns the hex SHA-256 of a file's contents
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
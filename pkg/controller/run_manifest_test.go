@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestRunManifest_MockRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputPath := filepath.Join(tmpDir, "output.json")
+	manifestPath := filepath.Join(tmpDir, "manifest.json")
+
+	cfg := &config.Config{
+		Experiment: config.ExperimentConfig{Name: "manifest", Version: "0.1"},
+		Controls:   config.ControlsConfig{Concurrency: 1, OnError: "fail", Manifest: manifestPath},
+	}
+
+	manifest, err := NewRunManifest(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create run manifest: %v", err)
+	}
+
+	// Mock run: read two files, write one output
+	manifest.AddInput("predictions", []string{"data/a.json", "data/b.json"}, 3)
+
+	output := []byte(`[{"label": "positive"}]`)
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
+		t.Fatalf("Failed to write output: %v", err)
+	}
+	if err := manifest.AddOutput("eval-results", outputPath, 1); err != nil {
+		t.Fatalf("Failed to add output: %v", err)
+	}
+
+	manifest.Metrics["accuracy"] = 0.5
+	manifest.Finish()
+
+	if err := manifest.Save(cfg.Controls.Manifest); err != nil {
+		t.Fatalf("Failed to save manifest: %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	var saved RunManifest
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+
+	expectedHash, _ := HashConfig(cfg)
+	if saved.ConfigHash == "" || saved.ConfigHash != expectedHash {
+		t.Errorf("Expected config hash %s, got %s", expectedHash, saved.ConfigHash)
+	}
+
+	if len(saved.Inputs) != 1 || saved.Inputs[0].Records != 3 || len(saved.Inputs[0].Files) != 2 {
+		t.Errorf("Expected input summary with 2 files and 3 records, got %+v", saved.Inputs)
+	}
+
+	sum := sha256.Sum256(output)
+	if len(saved.Outputs) != 1 || saved.Outputs[0].SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected output checksum, got %+v", saved.Outputs)
+	}
+
+	if saved.FinishedAt.Before(saved.StartedAt) || saved.DurationSeconds < 0 {
+		t.Errorf("Expected valid timing, got started %v finished %v", saved.StartedAt, saved.FinishedAt)
+	}
+
+	if saved.Metrics["accuracy"] != 0.5 {
+		t.Errorf("Expected accuracy metric 0.5, got %v", saved.Metrics["accuracy"])
+	}
+}
+
+// readRunManifest decodes the run manifest saved at path
+func readRunManifest(t *testing.T, path string) RunManifest {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest RunManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	return manifest
+}
+
+func TestExecute_WritesRunManifest(t *testing.T) {
+	cfg, outputPath := executeConfig(t, []sources.Record{{"text": "great"}, {"text": "awful"}})
+	cfg.Controls.Manifest = filepath.Join(t.TempDir(), "manifest.json")
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+	saved := readRunManifest(t, cfg.Controls.Manifest)
+
+	expectedHash, _ := HashConfig(cfg)
+	if saved.ConfigHash != expectedHash {
+		t.Errorf("Expected config hash %s, got %s", expectedHash, saved.ConfigHash)
+	}
+
+	inputPath := cfg.Inputs[0].Config["path"].(string)
+	if len(saved.Inputs) != 1 || saved.Inputs[0].ID != "reviews" || saved.Inputs[0].Records != 2 ||
+		len(saved.Inputs[0].Files) != 1 || saved.Inputs[0].Files[0] != inputPath {
+		t.Errorf("Expected input reviews with %s and 2 records, got %+v", inputPath, saved.Inputs)
+	}
+
+	checksum, err := FileChecksum(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to checksum output: %v", err)
+	}
+	if len(saved.Outputs) != 1 || saved.Outputs[0].Path != outputPath || saved.Outputs[0].Records != 2 || saved.Outputs[0].SHA256 != checksum {
+		t.Errorf("Expected output %s with 2 records and checksum %s, got %+v", outputPath, checksum, saved.Outputs)
+	}
+
+	if saved.Metrics["records"] != 2 || saved.Metrics["evaluated"] != 2 || saved.Metrics["failed"] != 0 {
+		t.Errorf("Expected summary metrics for 2 evaluated records, got %v", saved.Metrics)
+	}
+	if saved.Error != "" || saved.FinishedAt.Before(saved.StartedAt) {
+		t.Errorf("Expected a completed run with valid timing, got %+v", saved)
+	}
+}
+
+func TestExecute_WritesRunManifestOnFailure(t *testing.T) {
+	cfg, outputPath := executeConfig(t, fakeRecords(3))
+	cfg.Controls.Manifest = filepath.Join(t.TempDir(), "manifest.json")
+
+	controller := NewDefaultController()
+	controller.SetEvaluatorFactory(fakeFactory{&fakeEvaluator{failAt: 1}})
+	if err := controller.Execute(context.Background(), cfg); !errors.Is(err, errFake) {
+		t.Fatalf("Expected the record error, got %v", err)
+	}
+	saved := readRunManifest(t, cfg.Controls.Manifest)
+
+	if !strings.Contains(saved.Error, errFake.Error()) {
+		t.Errorf("Expected the run error in the manifest, got %q", saved.Error)
+	}
+	if saved.Metrics["failed"] != 1 {
+		t.Errorf("Expected 1 failed record, got %v", saved.Metrics)
+	}
+
+	// The rows written before the failure are checksummed
+	checksum, err := FileChecksum(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to checksum output: %v", err)
+	}
+	if len(saved.Outputs) != 1 || saved.Outputs[0].Records != 1 || saved.Outputs[0].SHA256 != checksum {
+		t.Errorf("Expected 1 written record with checksum %s, got %+v", checksum, saved.Outputs)
+	}
+}
+
+func TestExecute_RunManifestEvaluatorMetrics(t *testing.T) {
+	cfg, _ := executeConfig(t, []sources.Record{{"text": "great"}, {"text": "awful"}})
+	cfg.Controls.Manifest = filepath.Join(t.TempDir(), "manifest.json")
+	cfg.Controls.Metrics = true
+
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+	saved := readRunManifest(t, cfg.Controls.Manifest)
+
+	if saved.Metrics["evaluator_calls"] != 2 || saved.Metrics["evaluator_records"] != 2 || saved.Metrics["evaluator_errors"] != 0 {
+		t.Errorf("Expected evaluator metrics for 2 calls, got %v", saved.Metrics)
+	}
+	if _, ok := saved.Metrics["evaluator_latency_seconds"]; !ok {
+		t.Errorf("Expected evaluator latency in the metrics, got %v", saved.Metrics)
+	}
+
+	// Without controls.metrics only the run summary is recorded
+	cfg.Controls.Metrics = false
+	if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("Failed to execute: %v", err)
+	}
+	if saved := readRunManifest(t, cfg.Controls.Manifest); saved.Metrics["evaluator_calls"] != 0 {
+		t.Errorf("Expected no evaluator metrics, got %v", saved.Metrics)
+	}
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// Run-level fields controls.stamp can add to every output record
+const (
+	StampExperimentName = "experiment_name"
+	StampVersion        = "version"
+	StampRunID          = "run_id"
+	StampTimestamp      = "timestamp"
+)
+
+// Per-record fields naming the provider and model that produced each result.
+// They can be listed in controls.stamp or referenced by mappings.output as $._model.
+const (
+	StampProvider = "_provider"
+	StampModel    = "_model"
+)
+
+// Run identifies a single Execute call
+type Run struct {
+	ID        string    // random per Execute, shared by every record of the run
+	StartedAt time.Time // when Execute started, in UTC
+}
+
+// NewRun creates a run with a fresh ID starting now
+func NewRun() (Run, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Run{}, fmt.Errorf("failed to generate run id: %w", err)
+	}
+	return Run{ID: hex.EncodeToString(id), StartedAt: time.Now().UTC()}, nil
+}
+
+// StampValues returns the values of the run-level fields listed in controls.stamp
+func (r Run) StampValues(cfg *config.Config) map[string]interface{} {
+	values := make(map[string]interface{}, len(cfg.Controls.Stamp))
+	for _, field := range cfg.Controls.Stamp {
+		switch field {
+		case StampExperimentName:
+			values[field] = cfg.Experiment.Name
+		case StampVersion:
+			values[field] = cfg.Experiment.Version
+		case StampRunID:
+			values[field] = r.ID
+		case StampTimestamp:
+			values[field] = r.StartedAt.Format(time.RFC3339)
+		}
+	}
+	return values
+}
+
+// stamp adds the values to every record in place
+func stamp(records []sources.Record, values map[string]interface{}) {
+	for _, record := range records {
+		for k, v := range values {
+			record[k] = v
+		}
+	}
+}
+
+// servedBy returns the provider and model that produced a result, falling back
+// to the evaluation config when the evaluator does not report them
+func servedBy(result evaluators.Result, eval config.EvaluationConfig) map[string]interface{} {
+	provider, _ := result.Metadata["provider"].(string)
+	if provider == "" {
+		provider = eval.Provider
+	}
+	model, _ := result.Metadata["model"].(string)
+	if model == "" {
+		model = eval.Model
+	}
+	return map[string]interface{}{StampProvider: provider, StampModel: model}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+)
+
+// Sources of the token counts in a UsageRow
+const (
+	UsageReported  = "reported"  // counted by the provider
+	UsageEstimated = "estimated" // prompt tokens estimated locally; completion tokens unknown
+	UsageMissing   = "missing"   // no usage recorded, e.g. the record failed before a call
+)
+
+// UsageRow is one record's token usage in a usage report
+type UsageRow struct {
+	Input            string  `json:"input"`
+	Key              string  `json:"key"` // controls.usage_key_field, or the record hash
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`  // USD; zero when the model has no known price
+	Usage            string  `json:"usage"` // UsageReported, UsageEstimated, or UsageMissing
+}
+
+// UsageReport collects per-record token usage for controls.usage_report
+type UsageReport struct {
+	keyField string
+	prices   map[string]ModelPrice
+	unpriced map[string]bool // models already warned about
+	Rows     []UsageRow
+}
+
+// newUsageReport returns a report keyed by keyField, or nil when no report is configured
+func newUsageReport(controls config.ControlsConfig, prices map[string]ModelPrice) *UsageReport {
+	if controls.UsageReport == "" {
+		return nil
+	}
+	return &UsageReport{keyField: controls.UsageKeyField, prices: prices, unpriced: make(map[string]bool)}
+}
+
+// Add records the usage of one evaluated result. Packed batches report the
+// group's usage on every record, so it is split evenly across the group.
+func (u *UsageReport) Add(inputID string, result evaluators.Result, eval config.EvaluationConfig) error {
+	key, err := u.key(result)
+	if err != nil {
+		return err
+	}
+	row := UsageRow{Input: inputID, Key: key, Usage: UsageMissing}
+
+	if usage, ok := result.Metadata["usage"].(map[string]interface{}); ok {
+		row.PromptTokens = tokenCount(usage, "promptTokenCount", "prompt_tokens", "input_tokens")
+		row.CompletionTokens = tokenCount(usage, "candidatesTokenCount", "completion_tokens", "output_tokens")
+		row.Usage = UsageReported
+
+		size := intValue(result.Metadata["batchSize"])
+		if size > 1 {
+			index := intValue(result.Metadata["batchIndex"])
+			row.PromptTokens = share(row.PromptTokens, size, index)
+			row.CompletionTokens = share(row.CompletionTokens, size, index)
+		}
+	} else if tokens, ok := result.Metadata["estimatedPromptTokens"]; ok {
+		row.PromptTokens = intValue(tokens)
+		row.Usage = UsageEstimated
+	}
+
+	if row.Usage != UsageMissing {
+		if price, ok := u.prices[eval.Model]; ok {
+			row.Cost = float64(row.PromptTokens)/1e6*price.InputPerMillion +
+				float64(row.CompletionTokens)/1e6*price.OutputPerMillion
+		} else if !u.unpriced[eval.Model] {
+			u.unpriced[eval.Model] = true
+			log.Printf("warning: usage report: model %s has no known price; its cost is reported as 0", eval.Model)
+		}
+	}
+
+	u.Rows = append(u.Rows, row)
+	return nil
+}
+
+// key identifies a result's record by the configured key field, falling back to its hash
+func (u *UsageReport) key(result evaluators.Result) (string, error) {
+	if u.keyField != "" {
+		if value, ok := result.Input[u.keyField]; ok && value != nil {
+			return fmt.Sprintf("%v", value), nil
+		}
+	}
+	return HashRecord(result.Input)
+}
+
+// Save writes the report to path as CSV when it ends in .csv and as a JSON array otherwise
+func (u *UsageReport) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage report directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create usage report: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		w := csv.NewWriter(file)
+		w.Write([]string{"input", "key", "prompt_tokens", "completion_tokens", "cost", "usage"})
+		for _, row := range u.Rows {
+			w.Write([]string{
+				row.Input,
+				row.Key,
+				strconv.Itoa(row.PromptTokens),
+				strconv.Itoa(row.CompletionTokens),
+				strconv.FormatFloat(row.Cost, 'f', -1, 64),
+				row.Usage,
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("failed to write usage report: %w", err)
+		}
+		return file.Close()
+	}
+
+	rows := u.Rows
+	if rows == nil {
+		rows = []UsageRow{}
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(rows); err != nil {
+		return fmt.Errorf("failed to write usage report: %w", err)
+	}
+	return file.Close()
+}
+
+// tokenCount returns the first of keys present in usage as an integer
+func tokenCount(usage map[string]interface{}, keys ...string) int {
+	for _, key := range keys {
+		if value, ok := usage[key]; ok {
+			return intValue(value)
+		}
+	}
+	return 0
+}
+
+// intValue converts a decoded JSON or Go number to an int
+func intValue(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// share returns the part of total attributed to index when split across size
+// records; the first record takes the remainder so the parts add up to total
+func share(total, size, index int) int {
+	part := total / size
+	if index == 0 {
+		part += total % size
+	}
+	return part
+}
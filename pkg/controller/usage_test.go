@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// usageServer answers every Gemini request with a fixed label and token usage
+// proportional to the prompt length
+func usageServer(t *testing.T) *geminitest.Server {
+	t.Helper()
+
+	return geminitest.NewServer(t, func(n int, req geminitest.Request) geminitest.Reply {
+		return geminitest.Reply{Text: "positive", Usage: map[string]interface{}{
+			"promptTokenCount":     len(req.Prompt),
+			"candidatesTokenCount": 1,
+		}}
+	})
+}
+
+func TestExecute_UsageReport(t *testing.T) {
+	records := []sources.Record{
+		{"id": "r1", "text": "great"},
+		{"id": "r2", "text": "awful product"},
+		{"id": "r3", "text": "ok"},
+	}
+
+	for _, ext := range []string{".json", ".csv"} {
+		t.Run(ext, func(t *testing.T) {
+			t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+			server := usageServer(t)
+
+			cfg, _ := executeConfig(t, records)
+			cfg.Inputs[0].Schema.Fields = append(cfg.Inputs[0].Schema.Fields, config.FieldConfig{Name: "id", Type: "string"})
+			cfg.Evaluation.Provider = "gemini"
+			cfg.Evaluation.Model = "gemini-pro"
+			cfg.Evaluation.Auth.APIKeyEnv = "TEST_GEMINI_API_KEY"
+			cfg.Evaluation.Params = map[string]interface{}{"base_url": server.URL}
+			cfg.Controls.UsageReport = filepath.Join(t.TempDir(), "usage"+ext)
+			cfg.Controls.UsageKeyField = "id"
+
+			if err := NewDefaultController().Execute(context.Background(), cfg); err != nil {
+				t.Fatalf("Failed to execute: %v", err)
+			}
+
+			rows := readUsageReport(t, cfg.Controls.UsageReport)
+			if len(rows) != len(records) {
+				t.Fatalf("Expected %d usage rows, got %v", len(records), rows)
+			}
+			price := DefaultModelPrices["gemini-pro"]
+			for i, row := range rows {
+				prompt := "Review: " + records[i]["text"].(string)
+				if row.Input != "reviews" || row.Key != records[i]["id"] || row.Usage != UsageReported {
+					t.Errorf("Expected row %d to identify reviews/%s, got %+v", i, records[i]["id"], row)
+				}
+				if row.PromptTokens != len(prompt) || row.CompletionTokens != 1 {
+					t.Errorf("Expected row %d to carry the reported usage, got %+v", i, row)
+				}
+				wantCost := float64(len(prompt))/1e6*price.InputPerMillion + 1/1e6*price.OutputPerMillion
+				if diff := row.Cost - wantCost; diff > 1e-12 || diff < -1e-12 {
+					t.Errorf("Expected row %d cost %g, got %g", i, wantCost, row.Cost)
+				}
+			}
+		})
+	}
+}
+
+func TestUsageReport_MissingAndPacked(t *testing.T) {
+	report := newUsageReport(config.ControlsConfig{UsageReport: "usage.json"}, DefaultModelPrices)
+	eval := config.EvaluationConfig{Model: "unpriced-model"}
+
+	results := []evaluators.Result{
+		{Input: sources.Record{"text": "a"}, Error: evaluators.ErrEmptyResponse},
+		{Input: sources.Record{"text": "b"}, Metadata: map[string]interface{}{"estimatedPromptTokens": 12}},
+		{Input: sources.Record{"text": "c"}, Metadata: map[string]interface{}{
+			"usage": map[string]interface{}{"promptTokenCount": 10.0, "candidatesTokenCount": 5.0}, "batchIndex": 0, "batchSize": 2,
+		}},
+		{Input: sources.Record{"text": "d"}, Metadata: map[string]interface{}{
+			"usage": map[string]interface{}{"promptTokenCount": 10.0, "candidatesTokenCount": 5.0}, "batchIndex": 1, "batchSize": 2,
+		}},
+	}
+	for _, result := range results {
+		if err := report.Add("in", result, eval); err != nil {
+			t.Fatalf("Failed to add usage: %v", err)
+		}
+	}
+
+	want := []struct {
+		usage              string
+		prompt, completion int
+	}{
+		{UsageMissing, 0, 0},
+		{UsageEstimated, 12, 0},
+		{UsageReported, 5, 3},
+		{UsageReported, 5, 2},
+	}
+	for i, w := range want {
+		row := report.Rows[i]
+		if row.Usage != w.usage || row.PromptTokens != w.prompt || row.CompletionTokens != w.completion {
+			t.Errorf("Expected row %d %s %d/%d, got %+v", i, w.usage, w.prompt, w.completion, row)
+		}
+		if row.Cost != 0 {
+			t.Errorf("Expected no cost for an unpriced model, got %g", row.Cost)
+		}
+		if hash, _ := HashRecord(results[i].Input); row.Key != hash {
+			t.Errorf("Expected row %d keyed by the record hash, got %s", i, row.Key)
+		}
+	}
+}
+
+// readUsageReport decodes a JSON or CSV usage report
+func readUsageReport(t *testing.T, path string) []UsageRow {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read usage report: %v", err)
+	}
+
+	var rows []UsageRow
+	if !strings.HasSuffix(path, ".csv") {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			t.Fatalf("Failed to decode usage report: %v", err)
+		}
+		return rows
+	}
+
+	lines, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse usage report: %v", err)
+	}
+	if strings.Join(lines[0], ",") != "input,key,prompt_tokens,completion_tokens,cost,usage" {
+		t.Fatalf("Unexpected usage report header: %v", lines[0])
+	}
+	for _, line := range lines[1:] {
+		var row UsageRow
+		row.Input, row.Key, row.Usage = line[0], line[1], line[5]
+		json.Unmarshal([]byte(line[2]), &row.PromptTokens)
+		json.Unmarshal([]byte(line[3]), &row.CompletionTokens)
+		json.Unmarshal([]byte(line[4]), &row.Cost)
+		rows = append(rows, row)
+	}
+	return rows
+}
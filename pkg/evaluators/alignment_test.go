@@ -0,0 +1,92 @@
+package evaluators
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// echoServer answers each prompt with its own text after a random delay, so
+// requests complete out of order; prompts containing "fail" get an error
+func echoServer(t *testing.T) *geminitest.Server {
+	t.Helper()
+
+	return geminitest.NewServer(t, func(n int, req geminitest.Request) geminitest.Reply {
+		time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+		if strings.Contains(req.Prompt, "fail") {
+			return geminitest.Reply{Status: http.StatusBadRequest}
+		}
+		return geminitest.Reply{Text: req.Prompt}
+	})
+}
+
+func TestBatchEvaluate_ResultsAlignWithRecords(t *testing.T) {
+	server := echoServer(t)
+
+	records := make([]sources.Record, 60)
+	for i := range records {
+		text := fmt.Sprintf("record-%d", i)
+		if i%7 == 0 {
+			text += "-fail"
+		}
+		records[i] = sources.Record{"text": text}
+	}
+
+	gemini := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL},
+	})
+	gemini.SetConcurrency(8)
+
+	// The second pass is served from the cache, except for the failed records
+	evaluator := Chain(gemini, WithCache())
+	for pass := 0; pass < 2; pass++ {
+		shuffled := append([]sources.Record(nil), records...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		results, err := evaluator.BatchEvaluate(context.Background(), shuffled, "{{text}}")
+		if err != nil {
+			t.Fatalf("Failed to batch evaluate: %v", err)
+		}
+		if err := CheckAlignment(shuffled, results); err != nil {
+			t.Fatalf("Pass %d: %v", pass, err)
+		}
+
+		for i, result := range results {
+			text := shuffled[i]["text"].(string)
+			if !sameRecord(result.Input, shuffled[i]) {
+				t.Errorf("Pass %d: expected result %d to carry its own record", pass, i)
+			}
+			if strings.HasSuffix(text, "-fail") {
+				if result.Error == nil {
+					t.Errorf("Pass %d: expected %s to fail", pass, text)
+				}
+				continue
+			}
+			if result.Error != nil || result.Output["response"] != text {
+				t.Errorf("Pass %d: expected response %q for result %d, got %v (error %v)", pass, text, i, result.Output["response"], result.Error)
+			}
+		}
+	}
+}
+
+func TestCheckAlignment(t *testing.T) {
+	records := []sources.Record{{"id": 1}, {"id": 2}}
+
+	if err := CheckAlignment(records, []Result{{Input: records[0]}, {Input: sources.Record{"id": 2}}}); err != nil {
+		t.Errorf("Expected aligned results to pass, got %v", err)
+	}
+	if err := CheckAlignment(records, []Result{{Input: records[0]}}); err == nil {
+		t.Error("Expected error for a missing result, got nil")
+	}
+	if err := CheckAlignment(records, []Result{{Input: records[1]}, {Input: records[0]}}); err == nil {
+		t.Error("Expected error for swapped results, got nil")
+	}
+}
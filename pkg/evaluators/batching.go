@@ -0,0 +1,77 @@
+package evaluators
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// ErrAnswerCountMismatch is returned when a packed response does not contain one answer per record.
+// The controller applies controls.on_error to the affected records.
+var ErrAnswerCountMismatch = errors.New("answer count does not match record count")
+
+// answerLinePattern matches numbered answer lines such as "1. positive" or "2) negative"
+var answerLinePattern = regexp.MustCompile(`^\s*(\d+)\s*[.):]\s*(.*)$`)
+
+// packPrompts packs already-rendered prompts into a single numbered multi-example prompt
+func packPrompts(prompts []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Answer each of the following %d items independently.\n", len(prompts))
+	fmt.Fprintf(&b, "Respond with exactly %d lines, one per item, each prefixed with its item number (e.g. \"1. answer\").\n", len(prompts))
+
+	for i, prompt := range prompts {
+		fmt.Fprintf(&b, "\nItem %d:\n%s\n", i+1, strings.TrimSpace(prompt))
+	}
+
+	return b.String()
+}
+
+// splitAnswers splits a numbered response into exactly count answers, ordered by item number
+func splitAnswers(text string, count int) ([]string, error) {
+	answers := make([]string, count)
+	seen := make([]bool, count)
+	found := 0
+
+	for _, line := range strings.Split(text, "\n") {
+		match := answerLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		index, err := strconv.Atoi(match[1])
+		if err != nil || index < 1 || index > count {
+			return nil, fmt.Errorf("%w: unexpected item number %s", ErrAnswerCountMismatch, match[1])
+		}
+
+		if seen[index-1] {
+			return nil, fmt.Errorf("%w: duplicate answer for item %d", ErrAnswerCountMismatch, index)
+		}
+
+		answers[index-1] = strings.TrimSpace(match[2])
+		seen[index-1] = true
+		found++
+	}
+
+	if found != count {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrAnswerCountMismatch, count, found)
+	}
+
+	return answers, nil
+}
+
+// failedResults builds an error result for every record
+func failedResults(records []sources.Record, err error) []Result {
+	results := make([]Result, len(records))
+	for i, record := range records {
+		results[i] = Result{
+			Input: record,
+			Error: err,
+		}
+	}
+	return results
+}
@@ -0,0 +1,64 @@
+package evaluators
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestBatching_PackAndSplit(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{BatchSize: 3})
+
+	records := []sources.Record{
+		{"text": "I love it"},
+		{"text": "I hate it"},
+		{"text": "It is fine"},
+	}
+
+	prompts := make([]string, len(records))
+	for i, record := range records {
+		prompts[i], _ = evaluator.applyPromptTemplate("Text: {{text}}", record)
+	}
+
+	packed := packPrompts(prompts)
+	for i, want := range []string{"Item 1:\nText: I love it", "Item 2:\nText: I hate it", "Item 3:\nText: It is fine"} {
+		if !strings.Contains(packed, want) {
+			t.Errorf("Expected packed prompt to contain item %d %q, got:\n%s", i+1, want, packed)
+		}
+	}
+
+	answers, err := splitAnswers("2. negative\n1. positive\n\n3) neutral\n", len(records))
+	if err != nil {
+		t.Fatalf("Failed to split answers: %v", err)
+	}
+
+	expected := []string{"positive", "negative", "neutral"}
+	for i := range expected {
+		if answers[i] != expected[i] {
+			t.Errorf("Expected answer %d to be %s, got %s", i+1, expected[i], answers[i])
+		}
+	}
+}
+
+func TestBatching_AnswerCountMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"too few answers", "1. positive\n2. negative"},
+		{"out of range item", "1. positive\n2. negative\n4. neutral"},
+		{"duplicate item", "1. positive\n1. negative\n2. neutral"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := splitAnswers(tt.text, 3)
+			if !errors.Is(err, ErrAnswerCountMismatch) {
+				t.Errorf("Expected ErrAnswerCountMismatch, got %v", err)
+			}
+		})
+	}
+}
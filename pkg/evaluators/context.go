@@ -0,0 +1,148 @@
+package evaluators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// Policies for prompts exceeding the model's context window, matching the
+// evaluation.on_context_overflow values
+const (
+	ContextOverflowError    = "error"
+	ContextOverflowSkip     = "skip"
+	ContextOverflowTruncate = "truncate"
+)
+
+// ErrContextOverflow is returned when a rendered prompt does not fit the model's context window
+var ErrContextOverflow = errors.New("prompt exceeds the model context window")
+
+// defaultContextWindows maps model name prefixes to their input token limits
+var defaultContextWindows = map[string]int{
+	"gemini-pro":            32760,
+	"gemini-1.0-pro":        32760,
+	"gemini-1.5-pro":        2097152,
+	"gemini-1.5-flash":      1048576,
+	"gemini-2.0-flash":      1048576,
+	"gemini-2.0-flash-lite": 1048576,
+	"gemini-2.5-pro":        1048576,
+	"gemini-2.5-flash":      1048576,
+}
+
+// ContextWindow returns the context window of model in tokens, matching the
+// longest known name prefix so versioned names like gemini-1.5-pro-002 resolve
+func ContextWindow(model string) (int, bool) {
+	best := ""
+	for prefix := range defaultContextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0, false
+	}
+	return defaultContextWindows[best], true
+}
+
+// contextGuard checks rendered prompts against the model's context window
+// before they are sent
+type contextGuard struct {
+	policy string // on_context_overflow; the check is disabled when empty
+	limit  int    // context window in tokens; the check is disabled when zero
+}
+
+// newContextGuard uses evaluation.context_window when set, falling back to the
+// known window of the configured model
+func newContextGuard(cfg config.EvaluationConfig) contextGuard {
+	limit := cfg.ContextWindow
+	if limit == 0 {
+		limit, _ = ContextWindow(cfg.Model)
+	}
+	return contextGuard{policy: cfg.OnContextOverflow, limit: limit}
+}
+
+// skips reports whether overflow, as returned by check, means the record is skipped
+func (c contextGuard) skips(overflow map[string]interface{}) bool {
+	return overflow != nil && c.policy == ContextOverflowSkip
+}
+
+// check counts the prompt's tokens against the context window less reserve
+// tokens kept for the response. It returns the prompt to send, cut down to fit
+// under the truncate policy, and the decision to record in metadata, which is
+// nil when the prompt fits. Under the error policy, and when truncation cannot
+// help, the error wraps ErrContextOverflow.
+func (c contextGuard) check(prompt, model string, tokenizer Tokenizer, reserve int) (string, map[string]interface{}, error) {
+	if c.policy == "" || c.limit <= 0 {
+		return prompt, nil, nil
+	}
+
+	tokens, err := tokenizer.CountTokens(prompt, model)
+	if err != nil {
+		return prompt, nil, fmt.Errorf("failed to count prompt tokens: %w", err)
+	}
+
+	budget := c.limit - reserve
+	if tokens <= budget {
+		return prompt, nil, nil
+	}
+
+	overflow := map[string]interface{}{
+		"contextOverflow": c.policy,
+		"promptTokens":    tokens,
+		"contextWindow":   c.limit,
+	}
+
+	switch c.policy {
+	case ContextOverflowSkip:
+		return prompt, overflow, nil
+	case ContextOverflowTruncate:
+		if budget > 0 {
+			truncated, err := truncateToTokens(prompt, model, tokenizer, budget)
+			if err != nil {
+				return prompt, overflow, err
+			}
+			overflow["truncatedPromptTokens"], _ = tokenizer.CountTokens(truncated, model)
+			return truncated, overflow, nil
+		}
+	}
+
+	return prompt, overflow, fmt.Errorf("%w: %d prompt tokens, %d available of %d", ErrContextOverflow, tokens, budget, c.limit)
+}
+
+// truncateToTokens returns the longest prefix of text that fits in budget tokens
+func truncateToTokens(text, model string, tokenizer Tokenizer, budget int) (string, error) {
+	runes := []rune(text)
+
+	// Binary search over prefix lengths; token counts grow with the prefix
+	low, high := 0, len(runes)
+	for low < high {
+		mid := (low + high + 1) / 2
+		tokens, err := tokenizer.CountTokens(string(runes[:mid]), model)
+		if err != nil {
+			return "", fmt.Errorf("failed to count prompt tokens: %w", err)
+		}
+		if tokens <= budget {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return string(runes[:low]), nil
+}
+
+// outputReserve returns the maxOutputTokens a request body asks for, which the
+// prompt must leave room for in the context window
+func outputReserve(requestBody map[string]interface{}) int {
+	generationConfig, _ := requestBody["generationConfig"].(map[string]interface{})
+	switch v := generationConfig["maxOutputTokens"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
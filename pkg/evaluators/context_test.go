@@ -0,0 +1,140 @@
+package evaluators
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+		known bool
+	}{
+		{"gemini-1.5-pro", 2097152, true},
+		{"gemini-1.5-pro-002", 2097152, true},
+		{"gemini-2.0-flash-lite-001", 1048576, true},
+		{"gemini-pro", 32760, true},
+		{"my-finetune", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, known := ContextWindow(tt.model)
+		if got != tt.want || known != tt.known {
+			t.Errorf("Expected ContextWindow(%s) = %d, %v, got %d, %v", tt.model, tt.want, tt.known, got, known)
+		}
+	}
+}
+
+func TestGeminiEvaluator_ContextOverflow(t *testing.T) {
+	oversized := sources.Record{"text": strings.Repeat("word ", 200)}
+	tokenizer := NewApproximateTokenizer()
+
+	tests := []struct {
+		policy   string
+		wantErr  bool
+		skipped  bool
+		requests int
+	}{
+		{ContextOverflowError, true, false, 0},
+		{ContextOverflowSkip, false, true, 0},
+		{ContextOverflowTruncate, false, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			server := geminitest.NewServer(t, geminitest.Answer("positive"))
+			evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+				Params:            map[string]interface{}{"base_url": server.URL, "max_tokens": 10},
+				OnContextOverflow: tt.policy,
+				ContextWindow:     60,
+			})
+
+			result, err := evaluator.Evaluate(context.Background(), oversized, "Classify: {{text}}")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !errors.Is(err, ErrContextOverflow) {
+				t.Errorf("Expected ErrContextOverflow, got %v", err)
+			}
+			if result.Skipped != tt.skipped {
+				t.Errorf("Expected skipped %v, got %v", tt.skipped, result.Skipped)
+			}
+			if result.Metadata["contextOverflow"] != tt.policy {
+				t.Errorf("Expected contextOverflow %s in metadata, got %v", tt.policy, result.Metadata)
+			}
+
+			sent := server.Prompts()
+			if len(sent) != tt.requests {
+				t.Fatalf("Expected %d requests, got %d", tt.requests, len(sent))
+			}
+			if tt.policy == ContextOverflowTruncate {
+				tokens, _ := tokenizer.CountTokens(sent[0], "gemini-pro")
+				if tokens > 50 || !strings.HasPrefix(sent[0], "Classify: word") {
+					t.Errorf("Expected the prompt truncated to 50 tokens, got %d tokens: %q", tokens, sent[0])
+				}
+				if result.Output["response"] != "positive" {
+					t.Errorf("Expected the truncated prompt to be evaluated, got %v", result.Output)
+				}
+			}
+		})
+	}
+}
+
+func TestGeminiEvaluator_ContextFits(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params:            map[string]interface{}{"base_url": server.URL},
+		OnContextOverflow: ContextOverflowError,
+	})
+
+	result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "short"}, "Classify: {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+	if _, ok := result.Metadata["contextOverflow"]; ok {
+		t.Errorf("Expected no overflow decision for a prompt that fits, got %v", result.Metadata)
+	}
+	if got := server.Prompts(); len(got) != 1 || got[0] != "Classify: short" {
+		t.Errorf("Expected the prompt sent unchanged, got %v", got)
+	}
+}
+
+func TestGeminiEvaluator_ContextOverflowPacked(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("1. positive\n2. negative"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params:            map[string]interface{}{"base_url": server.URL},
+		BatchSize:         3,
+		OnContextOverflow: ContextOverflowSkip,
+		ContextWindow:     60,
+	})
+
+	records := []sources.Record{
+		{"text": "good"},
+		{"text": strings.Repeat("word ", 200)},
+		{"text": "bad"},
+	}
+	results, err := evaluator.BatchEvaluate(context.Background(), records, "Classify: {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+	if err := CheckAlignment(records, results); err != nil {
+		t.Fatalf("Expected aligned results: %v", err)
+	}
+
+	if !results[1].Skipped || results[1].Metadata["contextOverflow"] != ContextOverflowSkip {
+		t.Errorf("Expected the oversized record skipped, got %+v", results[1])
+	}
+	if results[0].Output["response"] != "positive" || results[2].Output["response"] != "negative" {
+		t.Errorf("Expected the other records packed together, got %v and %v", results[0].Output, results[2].Output)
+	}
+	if got := server.Prompts(); len(got) != 1 || strings.Contains(got[0], "word") {
+		t.Errorf("Expected one packed request without the oversized record, got %v", got)
+	}
+}
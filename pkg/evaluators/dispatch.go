@@ -0,0 +1,69 @@
+package evaluators
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// dispatcher runs work items on at most concurrency goroutines, using a
+// semaphore channel for backpressure
+type dispatcher struct {
+	concurrency int
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64 // high-water mark of inFlight, observed by tests
+}
+
+// newDispatcher creates a dispatcher; concurrency below 1 runs items one at a time
+func newDispatcher(concurrency int) *dispatcher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &dispatcher{concurrency: concurrency}
+}
+
+// run calls fn for each index in [0, n), blocking new dispatches while
+// concurrency calls are in flight. It stops dispatching when ctx is done and
+// returns the number of items dispatched; every dispatched call has returned.
+func (d *dispatcher) run(ctx context.Context, n int, fn func(i int)) int {
+	sem := make(chan struct{}, d.concurrency)
+	var wg sync.WaitGroup
+
+	dispatched := 0
+	for ; dispatched < n; dispatched++ {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			d.enter()
+			defer d.inFlight.Add(-1)
+			fn(i)
+		}(dispatched)
+	}
+
+	wg.Wait()
+	return dispatched
+}
+
+// enter records a call starting and updates the high-water mark
+func (d *dispatcher) enter() {
+	current := d.inFlight.Add(1)
+	for {
+		max := d.maxInFlight.Load()
+		if current <= max || d.maxInFlight.CompareAndSwap(max, current) {
+			return
+		}
+	}
+}
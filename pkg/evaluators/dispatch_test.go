@@ -0,0 +1,67 @@
+package evaluators
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestDispatcher_BoundsInFlight(t *testing.T) {
+	d := newDispatcher(3)
+
+	// Slow fake evaluator so calls overlap
+	evaluate := func(ctx context.Context, record sources.Record) Result {
+		time.Sleep(10 * time.Millisecond)
+		return Result{Input: record}
+	}
+
+	records := make([]sources.Record, 12)
+	results := make([]Result, len(records))
+	for i := range records {
+		records[i] = sources.Record{"id": i}
+	}
+
+	dispatched := d.run(context.Background(), len(records), func(i int) {
+		results[i] = evaluate(context.Background(), records[i])
+	})
+
+	if dispatched != len(records) {
+		t.Errorf("Expected %d records dispatched, got %d", len(records), dispatched)
+	}
+	if max := d.maxInFlight.Load(); max > 3 {
+		t.Errorf("Expected at most 3 requests in flight, got %d", max)
+	} else if max < 2 {
+		t.Errorf("Expected requests to run concurrently, got max %d in flight", max)
+	}
+	if inFlight := d.inFlight.Load(); inFlight != 0 {
+		t.Errorf("Expected no requests in flight after run, got %d", inFlight)
+	}
+	for i, result := range results {
+		if result.Input["id"] != i {
+			t.Errorf("Expected result %d to keep its input, got %v", i, result.Input)
+		}
+	}
+}
+
+func TestDispatcher_StopsOnCancel(t *testing.T) {
+	d := newDispatcher(2)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int64
+	dispatched := d.run(ctx, 10, func(i int) {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	if dispatched >= 10 {
+		t.Errorf("Expected dispatch to stop after cancel, dispatched %d", dispatched)
+	}
+	if int64(dispatched) != calls.Load() {
+		t.Errorf("Expected every dispatched item to run, dispatched %d ran %d", dispatched, calls.Load())
+	}
+}
@@ -0,0 +1,26 @@
+package evaluators
+
+import (
+	"errors"
+	"strings"
+)
+
+// Policies for blank model responses, matching the evaluation.on_empty_response values
+const (
+	EmptyResponseError   = "error"
+	EmptyResponseSkip    = "skip"
+	EmptyResponseRetry   = "retry"
+	EmptyResponseDefault = "default"
+)
+
+// DefaultEmptyResponseRetries is how many times on_empty_response: retry re-sends a request
+const DefaultEmptyResponseRetries = 2
+
+// ErrEmptyResponse is returned when the model's response is blank after trimming
+var ErrEmptyResponse = errors.New("empty response from model")
+
+// isEmptyResponse reports whether an output's response text is blank after trimming
+func isEmptyResponse(output map[string]interface{}) bool {
+	text, _ := output["response"].(string)
+	return strings.TrimSpace(text) == ""
+}
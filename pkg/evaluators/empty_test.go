@@ -0,0 +1,71 @@
+package evaluators
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// blankServer answers with blank text until the given request number, then with "positive"
+func blankServer(t *testing.T, answerFrom int) *geminitest.Server {
+	t.Helper()
+
+	return geminitest.NewServer(t, func(n int, req geminitest.Request) geminitest.Reply {
+		if n >= answerFrom {
+			return geminitest.Reply{Text: "positive"}
+		}
+		return geminitest.Reply{Text: "  \n"}
+	})
+}
+
+func TestGeminiEvaluator_OnEmptyResponse(t *testing.T) {
+	tests := []struct {
+		policy       string
+		answerFrom   int
+		wantErr      bool
+		wantSkipped  bool
+		wantResponse string
+		wantRequests int
+	}{
+		{"", 100, false, false, "  \n", 1},
+		{EmptyResponseError, 100, true, false, "", 1},
+		{EmptyResponseSkip, 100, false, true, "", 1},
+		{EmptyResponseDefault, 100, false, false, "neutral", 1},
+		{EmptyResponseRetry, 2, false, false, "positive", 2},
+		{EmptyResponseRetry, 100, true, false, "", DefaultEmptyResponseRetries + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			server := blankServer(t, tt.answerFrom)
+			evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+				Params:               map[string]interface{}{"base_url": server.URL},
+				OnEmptyResponse:      tt.policy,
+				EmptyResponseDefault: "neutral",
+			})
+
+			result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "{{text}}")
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrEmptyResponse) {
+					t.Errorf("Expected ErrEmptyResponse, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Skipped != tt.wantSkipped {
+				t.Errorf("Expected skipped %v, got %v", tt.wantSkipped, result.Skipped)
+			}
+			if tt.wantResponse != "" && result.Output["response"] != tt.wantResponse {
+				t.Errorf("Expected response %q, got %v", tt.wantResponse, result.Output["response"])
+			}
+			if got := len(server.Requests()); got != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package evaluators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Endpoint identifies the API root a provider's requests are sent to
+type Endpoint struct {
+	BaseURL    string
+	APIVersion string
+}
+
+// defaultEndpoints holds the public endpoint for each provider
+var defaultEndpoints = map[string]Endpoint{
+	"gemini":    {BaseURL: "https://generativelanguage.googleapis.com", APIVersion: "v1beta"},
+	"openai":    {BaseURL: "https://api.openai.com", APIVersion: "v1"},
+	"anthropic": {BaseURL: "https://api.anthropic.com", APIVersion: "v1"},
+}
+
+// resolveEndpoint returns the provider's default endpoint with any base_url
+// (or endpoint) and api_version params applied. An empty api_version drops the
+// version segment, for gateways that route on the base URL alone.
+func resolveEndpoint(provider string, params map[string]interface{}) (Endpoint, error) {
+	endpoint := defaultEndpoints[provider]
+
+	for _, key := range []string{"base_url", "endpoint"} {
+		raw, exists := params[key]
+		if !exists {
+			continue
+		}
+		baseURL, ok := raw.(string)
+		if !ok {
+			return Endpoint{}, fmt.Errorf("%s must be a string, got %T", key, raw)
+		}
+		parsed, err := url.Parse(baseURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return Endpoint{}, fmt.Errorf("%s must be an absolute URL, got %q", key, baseURL)
+		}
+		endpoint.BaseURL = strings.TrimRight(baseURL, "/")
+		break
+	}
+
+	if raw, exists := params["api_version"]; exists {
+		version, ok := raw.(string)
+		if !ok {
+			return Endpoint{}, fmt.Errorf("api_version must be a string, got %T", raw)
+		}
+		endpoint.APIVersion = strings.Trim(version, "/")
+	}
+
+	if endpoint.BaseURL == "" {
+		return Endpoint{}, fmt.Errorf("no default endpoint for provider %s; set base_url", provider)
+	}
+	return endpoint, nil
+}
+
+// URL joins the base URL, API version, and path
+func (e Endpoint) URL(path string) string {
+	if e.APIVersion == "" {
+		return e.BaseURL + "/" + path
+	}
+	return e.BaseURL + "/" + e.APIVersion + "/" + path
+}
+
+// resolveEndpoints returns the endpoints a provider fails over between: one
+// per params.endpoints base URL, in order, sharing the api_version, or the
+// single endpoint resolveEndpoint returns when endpoints is unset
+func resolveEndpoints(provider string, params map[string]interface{}) ([]Endpoint, error) {
+	endpoint, err := resolveEndpoint(provider, params)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, exists := params["endpoints"]
+	if !exists {
+		return []Endpoint{endpoint}, nil
+	}
+	if _, ok := params["base_url"]; ok {
+		return nil, fmt.Errorf("set either base_url or endpoints, not both")
+	}
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("endpoints must be a non-empty list of base URLs, got %v", raw)
+	}
+
+	endpoints := make([]Endpoint, 0, len(list))
+	for i, item := range list {
+		baseURL, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("endpoints[%d] must be a string, got %T", i, item)
+		}
+		parsed, err := url.Parse(baseURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("endpoints[%d] must be an absolute URL, got %q", i, baseURL)
+		}
+		endpoints = append(endpoints, Endpoint{BaseURL: strings.TrimRight(baseURL, "/"), APIVersion: endpoint.APIVersion})
+	}
+	return endpoints, nil
+}
+
+// endpointPool holds a provider's endpoints in failover order. Requests go to
+// the active endpoint; an outage moves it, and every later request, on to the
+// next endpoint, wrapping around after the last.
+type endpointPool struct {
+	mu           sync.Mutex
+	endpoints    []Endpoint
+	active       int
+	maxFailovers int // further endpoints one request may try after an outage
+}
+
+// newEndpointPool reads params.max_failovers, which defaults to trying every endpoint once
+func newEndpointPool(endpoints []Endpoint, params map[string]interface{}) (*endpointPool, error) {
+	pool := &endpointPool{endpoints: endpoints, maxFailovers: len(endpoints) - 1}
+
+	if raw, exists := params["max_failovers"]; exists {
+		var n int
+		switch v := raw.(type) {
+		case int:
+			n = v
+		case float64:
+			n = int(v)
+		default:
+			return nil, fmt.Errorf("max_failovers must be an integer, got %T", raw)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("max_failovers must not be negative, got %d", n)
+		}
+		pool.maxFailovers = min(n, len(endpoints)-1)
+	}
+	return pool, nil
+}
+
+// current returns the active endpoint and its index
+func (p *endpointPool) current() (int, Endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active, p.endpoints[p.active]
+}
+
+// failover moves past the endpoint at index failed after err, unless a
+// concurrent request already has, and returns the endpoint to try next
+func (p *endpointPool) failover(failed int, err error) (int, Endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == failed {
+		p.active = (failed + 1) % len(p.endpoints)
+		log.Printf("warning: endpoint %s failed (%v); failing over to %s",
+			p.endpoints[failed].BaseURL, err, p.endpoints[p.active].BaseURL)
+	}
+	return p.active, p.endpoints[p.active]
+}
+
+// isEndpointOutage reports whether err suggests the endpoint itself is down
+// rather than the request being at fault: a 502, 503, or 504 status, or a
+// failure to reach it. Rate limits are left to retries.
+func isEndpointOutage(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -2,6 +2,10 @@ package evaluators
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
 
 	"github.com/adhaamehab/meval.ai/pkg/config"
 	"github.com/adhaamehab/meval.ai/pkg/sources"
@@ -11,8 +15,27 @@ import (
 type Evaluator interface {
 	// Evaluate performs evaluation on a single record
 	Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error)
-	// BatchEvaluate performs evaluation on multiple records
+	// BatchEvaluate performs evaluation on multiple records. It returns one result
+	// per record in input order: results[i].Input is records[i], whatever order
+	// the records completed in and whether or not they failed.
 	BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error)
+	// Capabilities reports the optional features the evaluator supports
+	Capabilities() Capabilities
+}
+
+// Capabilities describes the optional features an evaluator supports
+type Capabilities struct {
+	Streaming bool
+	Batch     bool
+	Logprobs  bool
+}
+
+// BaseEvaluator provides default capabilities for evaluators; embed it and override as needed
+type BaseEvaluator struct{}
+
+// Capabilities reports no optional features
+func (BaseEvaluator) Capabilities() Capabilities {
+	return Capabilities{}
 }
 
 // Result contains the result of an evaluation
@@ -21,9 +44,47 @@ type Result struct {
 	Output   map[string]interface{}
 	Metadata map[string]interface{}
 	Error    error
+	Skipped  bool // no output should be written for the record
 }
 
 // Factory creates evaluators based on provider
 type Factory interface {
 	CreateEvaluator(provider string, config config.EvaluationConfig) (Evaluator, error)
-}
\ No newline at end of file
+}
+
+// Doer sends HTTP requests for evaluators that call a remote API. *http.Client
+// implements it; inject another to stub responses in tests or to add
+// instrumented transports.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DefaultHTTPTimeout bounds each request of the built-in HTTP client
+const DefaultHTTPTimeout = 30 * time.Second
+
+// newDefaultHTTPClient returns the client evaluators use unless one is injected
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: DefaultHTTPTimeout}
+}
+
+// CheckAlignment verifies the BatchEvaluate contract: one result per record, with
+// results[i].Input equal to records[i]. Misaligned results would silently attach
+// outputs to the wrong records downstream.
+func CheckAlignment(records []sources.Record, results []Result) error {
+	if len(results) != len(records) {
+		return fmt.Errorf("evaluator returned %d results for %d records", len(results), len(records))
+	}
+	for i, record := range records {
+		input := results[i].Input
+		if sameRecord(input, record) || reflect.DeepEqual(input, record) {
+			continue
+		}
+		return fmt.Errorf("result %d does not belong to record %d", i, i)
+	}
+	return nil
+}
+
+// sameRecord reports whether two records are the same map
+func sameRecord(a, b sources.Record) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
@@ -2,23 +2,80 @@ package evaluators
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/adhaamehab/meval.ai/pkg/config"
 )
 
 // DefaultFactory implements the Factory interface for evaluators
-type DefaultFactory struct{}
+type DefaultFactory struct {
+	middlewares []Middleware
+	metrics     *Metrics
+	concurrency int
+	// retry only evaluations IsIdempotent accepts (controls.retry_idempotent_only)
+	retryIdempotentOnly bool
+	httpClient          Doer // shared by evaluators that call a remote API; nil uses their built-in client
+}
 
 // NewDefaultFactory creates a new evaluator factory
 func NewDefaultFactory() *DefaultFactory {
 	return &DefaultFactory{}
 }
 
+// NewFactoryWithControls creates an evaluator factory that wraps every evaluator
+// in the middlewares enabled by controls
+func NewFactoryWithControls(controls config.ControlsConfig) *DefaultFactory {
+	f := &DefaultFactory{
+		concurrency:         controls.Concurrency,
+		retryIdempotentOnly: controls.RetryIdempotentOnly && controls.Retries > 0,
+	}
+	if controls.Metrics {
+		f.metrics = &Metrics{}
+	}
+	f.middlewares = MiddlewareFor(controls, f.metrics)
+	return f
+}
+
+// Use appends middlewares applied to evaluators created after the call
+func (f *DefaultFactory) Use(middlewares ...Middleware) {
+	f.middlewares = append(f.middlewares, middlewares...)
+}
+
+// SetHTTPClient sets the client evaluators created after the call send API requests with
+func (f *DefaultFactory) SetHTTPClient(client Doer) {
+	f.httpClient = client
+}
+
+// Metrics returns the metrics collected by the factory's evaluators, or nil when disabled
+func (f *DefaultFactory) Metrics() *Metrics {
+	return f.metrics
+}
+
 // CreateEvaluator creates an evaluator based on provider and configuration
 func (f *DefaultFactory) CreateEvaluator(provider string, cfg config.EvaluationConfig) (Evaluator, error) {
+	evaluator, err := f.createProvider(provider, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if setter, ok := evaluator.(interface{ SetConcurrency(int) }); ok && f.concurrency > 0 {
+		setter.SetConcurrency(f.concurrency)
+	}
+	if setter, ok := evaluator.(interface{ SetHTTPClient(Doer) }); ok && f.httpClient != nil {
+		setter.SetHTTPClient(f.httpClient)
+	}
+	if f.retryIdempotentOnly && !IsIdempotent(cfg) {
+		log.Printf("warning: retries disabled for %s generation without a seed or temperature 0 (controls.retry_idempotent_only)", provider)
+		evaluator = withoutRetries(evaluator)
+	}
+	return Chain(evaluator, f.middlewares...), nil
+}
+
+func (f *DefaultFactory) createProvider(provider string, cfg config.EvaluationConfig) (Evaluator, error) {
 	switch provider {
 	case "gemini":
 		return NewGeminiEvaluator(cfg)
+	case "passthrough":
+		return NewPassthroughEvaluator(cfg), nil
 	case "openai":
 		return nil, fmt.Errorf("OpenAI evaluator not yet implemented")
 	case "anthropic":
@@ -28,4 +85,4 @@ func (f *DefaultFactory) CreateEvaluator(provider string, cfg config.EvaluationC
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
-}
\ No newline at end of file
+}
@@ -6,9 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/adhaamehab/meval.ai/pkg/config"
 	"github.com/adhaamehab/meval.ai/pkg/sources"
@@ -16,10 +17,23 @@ import (
 
 // GeminiEvaluator implements the Evaluator interface for Google Gemini
 type GeminiEvaluator struct {
-	apiKey     string
-	model      string
-	params     map[string]interface{}
-	httpClient *http.Client
+	apiKey               string
+	model                string
+	strategy             string
+	endpoints            *endpointPool
+	params               map[string]interface{}
+	rawParams            map[string]interface{}
+	safetySettings       []map[string]interface{}
+	batchSize            int
+	renderOptions        RenderOptions
+	onEmptyResponse      string
+	emptyResponseDefault string
+	tokenizer            Tokenizer
+	templates            templateCache
+	httpClient           Doer
+	dispatcher           *dispatcher
+	requestIDs           requestIDs
+	context              contextGuard
 }
 
 // NewGeminiEvaluator creates a new Gemini evaluator
@@ -29,40 +43,123 @@ func NewGeminiEvaluator(cfg config.EvaluationConfig) (*GeminiEvaluator, error) {
 		return nil, fmt.Errorf("API key environment variable %s is not set", cfg.Auth.APIKeyEnv)
 	}
 
+	endpoints, err := resolveEndpoints("gemini", cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := newEndpointPool(endpoints, cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GeminiEvaluator{
-		apiKey: apiKey,
-		model:  cfg.Model,
-		params: cfg.Params,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		apiKey:               apiKey,
+		model:                cfg.Model,
+		strategy:             cfg.Strategy,
+		endpoints:            pool,
+		params:               cfg.Params,
+		rawParams:            cfg.RawParams,
+		safetySettings:       safetySettings(cfg.SafetySettings),
+		batchSize:            cfg.BatchSize,
+		renderOptions:        NewRenderOptions(cfg),
+		onEmptyResponse:      cfg.OnEmptyResponse,
+		emptyResponseDefault: cfg.EmptyResponseDefault,
+		tokenizer:            NewApproximateTokenizer(),
+		dispatcher:           newDispatcher(1),
+		requestIDs:           newRequestIDs(cfg.Params),
+		context:              newContextGuard(cfg),
+		httpClient:           newDefaultHTTPClient(),
 	}, nil
 }
 
 // Evaluate performs evaluation on a single record
 func (g *GeminiEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	ctx, requestID := g.requestIDs.attach(ctx)
+
 	// Apply prompt templating
-	processedPrompt := g.applyPromptTemplate(prompt, record)
+	processedPrompt, err := g.applyPromptTemplate(prompt, record)
+	if err != nil {
+		return Result{Input: record, Error: err}, err
+	}
+
+	// Check the prompt fits the context window before sending it
+	processedPrompt, overflow, err := g.checkContext(processedPrompt)
+	if err != nil {
+		return Result{Input: record, Metadata: overflow, Error: err}, err
+	}
+	if g.context.skips(overflow) {
+		return Result{Input: record, Metadata: overflow, Skipped: true}, nil
+	}
 
 	// Prepare request
-	requestBody := g.buildRequestBody(processedPrompt)
+	requestBody := g.buildRequestBody(processedPrompt, 1)
 
-	// Make API call
-	response, err := g.makeAPICall(ctx, requestBody)
-	if err != nil {
-		return Result{
-			Input: record,
-			Error: err,
-		}, err
+	var effectiveSeed interface{}
+	if seed, ok := seedFor(g.params, record); ok {
+		effectiveSeed = setSeed(requestBody, seed)
 	}
 
-	// Parse response
-	output, metadata, err := g.parseResponse(response)
-	if err != nil {
-		return Result{
-			Input: record,
-			Error: err,
-		}, err
+	var output, metadata map[string]interface{}
+	var served string
+	for attempt := 0; ; attempt++ {
+		// Make API call
+		var response map[string]interface{}
+		response, served, err = g.makeAPICall(ctx, requestBody)
+		if err != nil {
+			return Result{
+				Input: record,
+				Error: err,
+			}, err
+		}
+
+		// Parse response
+		output, metadata, err = g.parseResponse(response)
+		if err != nil {
+			return Result{
+				Input: record,
+				Error: err,
+			}, err
+		}
+
+		if g.onEmptyResponse == "" || !isEmptyResponse(output) {
+			break
+		}
+
+		metadata["emptyResponse"] = g.onEmptyResponse
+		if g.onEmptyResponse == EmptyResponseRetry && attempt < DefaultEmptyResponseRetries {
+			continue
+		}
+
+		switch g.onEmptyResponse {
+		case EmptyResponseSkip:
+			return Result{Input: record, Metadata: metadata, Skipped: true}, nil
+		case EmptyResponseDefault:
+			output = buildOutput(g.emptyResponseDefault)
+		default:
+			if g.onEmptyResponse == EmptyResponseRetry {
+				err = fmt.Errorf("%w after %d attempts", ErrEmptyResponse, attempt+1)
+			} else {
+				err = ErrEmptyResponse
+			}
+			return Result{Input: record, Metadata: metadata, Error: err}, err
+		}
+		break
+	}
+
+	if effectiveSeed != nil {
+		metadata["seed"] = effectiveSeed
+	}
+	metadata["requestId"] = requestID
+	metadata["endpoint"] = served
+	for k, v := range overflow {
+		metadata[k] = v
+	}
+
+	// Fall back to a local estimate when the API does not report usage
+	if _, ok := metadata["usage"]; !ok {
+		if tokens, err := g.tokenizer.CountTokens(processedPrompt, g.model); err == nil {
+			metadata["estimatedPromptTokens"] = tokens
+		}
 	}
 
 	return Result{
@@ -73,43 +170,190 @@ func (g *GeminiEvaluator) Evaluate(ctx context.Context, record sources.Record, p
 	}, nil
 }
 
+// checkContext applies on_context_overflow to a rendered prompt, reserving
+// room for the response the request asks for
+func (g *GeminiEvaluator) checkContext(prompt string) (string, map[string]interface{}, error) {
+	reserve := outputReserve(g.buildRequestBody("", 1))
+	return g.context.check(prompt, g.model, g.tokenizer, reserve)
+}
+
+// SetTokenizer replaces the tokenizer used for token estimates and context window checks
+func (g *GeminiEvaluator) SetTokenizer(tokenizer Tokenizer) {
+	g.tokenizer = tokenizer
+}
+
+// SetHTTPClient replaces the client API requests are sent with; nil restores the built-in client
+func (g *GeminiEvaluator) SetHTTPClient(client Doer) {
+	if client == nil {
+		client = newDefaultHTTPClient()
+	}
+	g.httpClient = client
+}
+
 // BatchEvaluate performs evaluation on multiple records
 func (g *GeminiEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	if g.batchSize > 1 {
+		return g.packedBatchEvaluate(ctx, records, prompt)
+	}
+
 	results := make([]Result, len(records))
 
-	// Process each record individually
-	// TODO: Implement concurrent processing based on controls.concurrency
-	for i, record := range records {
-		result, err := g.Evaluate(ctx, record, prompt)
+	// Process records individually, at most concurrency at a time
+	dispatched := g.dispatcher.run(ctx, len(records), func(i int) {
+		result, err := g.Evaluate(ctx, records[i], prompt)
 		if err != nil {
 			results[i] = Result{
-				Input: record,
+				Input: records[i],
 				Error: err,
 			}
 		} else {
 			results[i] = result
 		}
+	})
+
+	// Records never dispatched because the context ended
+	for i := dispatched; i < len(records); i++ {
+		results[i] = Result{Input: records[i], Error: ctx.Err()}
 	}
 
 	return results, nil
 }
 
-// applyPromptTemplate replaces template variables with values from the record
-func (g *GeminiEvaluator) applyPromptTemplate(prompt string, record sources.Record) string {
-	processedPrompt := prompt
+// SetRequestIDContextKey sets the context key an embedding service stores its
+// request ID under, so evaluator calls carry that ID instead of a generated one
+func (g *GeminiEvaluator) SetRequestIDContextKey(key interface{}) {
+	g.requestIDs.contextKey = key
+}
+
+// SetConcurrency sets how many records BatchEvaluate evaluates at once
+func (g *GeminiEvaluator) SetConcurrency(concurrency int) {
+	g.dispatcher = newDispatcher(concurrency)
+}
+
+// packedBatchEvaluate packs batchSize records into each prompt and splits the answers back out
+func (g *GeminiEvaluator) packedBatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	results := make([]Result, len(records))
+
+	// Each record's prompt is checked against the context window on its own;
+	// records that cannot be sent are resolved here and the rest are packed
+	prompts := make([]string, len(records))
+	overflows := make([]map[string]interface{}, len(records))
+	pending := make([]int, 0, len(records))
+	for i, record := range records {
+		rendered, err := g.applyPromptTemplate(prompt, record)
+		var overflow map[string]interface{}
+		if err == nil {
+			rendered, overflow, err = g.checkContext(rendered)
+		}
+		switch {
+		case err != nil:
+			results[i] = Result{Input: record, Metadata: overflow, Error: err}
+		case g.context.skips(overflow):
+			results[i] = Result{Input: record, Metadata: overflow, Skipped: true}
+		default:
+			prompts[i], overflows[i] = rendered, overflow
+			pending = append(pending, i)
+		}
+	}
+
+	for start := 0; start < len(pending); start += g.batchSize {
+		end := start + g.batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		group := pending[start:end]
+
+		groupRecords := make([]sources.Record, len(group))
+		groupPrompts := make([]string, len(group))
+		for k, i := range group {
+			groupRecords[k], groupPrompts[k] = records[i], prompts[i]
+		}
+
+		for k, result := range g.evaluatePacked(ctx, groupRecords, groupPrompts) {
+			i := group[k]
+			if overflows[i] != nil && result.Metadata == nil {
+				result.Metadata = make(map[string]interface{}, len(overflows[i]))
+			}
+			for key, v := range overflows[i] {
+				result.Metadata[key] = v
+			}
+			results[i] = result
+		}
+	}
+
+	return results, nil
+}
+
+// evaluatePacked evaluates a group of records with a single multi-example
+// prompt built from their rendered prompts
+func (g *GeminiEvaluator) evaluatePacked(ctx context.Context, records []sources.Record, prompts []string) []Result {
+	ctx, requestID := g.requestIDs.attach(ctx)
 
-	// Replace template variables like {{field_name}} with actual values
-	for key, value := range record {
-		placeholder := fmt.Sprintf("{{%s}}", key)
-		valueStr := fmt.Sprintf("%v", value)
-		processedPrompt = strings.ReplaceAll(processedPrompt, placeholder, valueStr)
+	requestBody := g.buildRequestBody(packPrompts(prompts), len(records))
+
+	// A packed request shares one seed, so only the fixed seed applies
+	var effectiveSeed interface{}
+	if seed, ok := seedFor(g.params, nil); ok {
+		effectiveSeed = setSeed(requestBody, seed)
+	}
+
+	response, served, err := g.makeAPICall(ctx, requestBody)
+	if err != nil {
+		return failedResults(records, err)
 	}
 
-	return processedPrompt
+	output, metadata, err := g.parseResponse(response)
+	if err != nil {
+		return failedResults(records, err)
+	}
+
+	text, _ := output["response"].(string)
+	answers, err := splitAnswers(text, len(records))
+	if err != nil {
+		return failedResults(records, err)
+	}
+	metadata["requestId"] = requestID
+	metadata["endpoint"] = served
+
+	results := make([]Result, len(records))
+	for i, record := range records {
+		recordMetadata := make(map[string]interface{}, len(metadata)+2)
+		for k, v := range metadata {
+			recordMetadata[k] = v
+		}
+		recordMetadata["batchIndex"] = i
+		recordMetadata["batchSize"] = len(records)
+		if effectiveSeed != nil {
+			recordMetadata["seed"] = effectiveSeed
+		}
+
+		results[i] = Result{
+			Input:    record,
+			Output:   buildOutput(answers[i]),
+			Metadata: recordMetadata,
+		}
+	}
+
+	return results
+}
+
+// Capabilities reports the features supported by the Gemini evaluator
+func (g *GeminiEvaluator) Capabilities() Capabilities {
+	return Capabilities{
+		Batch: true,
+	}
+}
+
+// applyPromptTemplate replaces template variables with values from the record
+// following the RenderPrompt rules. Prompts are compiled once and cached, so
+// rendering per record does not re-parse them.
+func (g *GeminiEvaluator) applyPromptTemplate(prompt string, record sources.Record) (string, error) {
+	return g.templates.get(prompt).render(record, g.renderOptions)
 }
 
-// buildRequestBody builds the API request body
-func (g *GeminiEvaluator) buildRequestBody(prompt string) map[string]interface{} {
+// buildRequestBody builds the API request body for a prompt expecting the given
+// number of answers, starting from the strategy's generation defaults
+func (g *GeminiEvaluator) buildRequestBody(prompt string, answers int) map[string]interface{} {
 	// Build request based on Gemini API format
 	requestBody := map[string]interface{}{
 		"contents": []map[string]interface{}{
@@ -123,44 +367,131 @@ func (g *GeminiEvaluator) buildRequestBody(prompt string) map[string]interface{}
 		},
 	}
 
-	// Add generation config from params
+	// Add generation config from strategy defaults, overridden by params
+	generationConfig := strategyGenerationConfig(g.strategy, answers)
 	if g.params != nil {
-		generationConfig := make(map[string]interface{})
-		
 		if temp, ok := g.params["temperature"]; ok {
 			generationConfig["temperature"] = temp
 		}
-		
+
 		if maxTokens, ok := g.params["max_tokens"]; ok {
 			generationConfig["maxOutputTokens"] = maxTokens
 		}
-		
-		if len(generationConfig) > 0 {
-			requestBody["generationConfig"] = generationConfig
+
+		if n := g.samples(); n > 1 {
+			generationConfig["candidateCount"] = n
 		}
 	}
 
+	if len(generationConfig) > 0 {
+		requestBody["generationConfig"] = generationConfig
+	}
+
+	if len(g.safetySettings) > 0 {
+		requestBody["safetySettings"] = g.safetySettings
+	}
+
+	// Merge provider-specific params verbatim
+	mergeRawParams(requestBody, g.rawParams)
+
 	return requestBody
 }
 
-// makeAPICall makes the HTTP request to Gemini API
-func (g *GeminiEvaluator) makeAPICall(ctx context.Context, requestBody map[string]interface{}) (map[string]interface{}, error) {
-	// Construct API URL
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", g.model, g.apiKey)
+// safetySettings converts evaluation.safety_settings into Gemini's
+// safetySettings list, ordered by category so request bodies are stable
+func safetySettings(settings map[string]string) []map[string]interface{} {
+	categories := make([]string, 0, len(settings))
+	for category := range settings {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	list := make([]map[string]interface{}, 0, len(categories))
+	for _, category := range categories {
+		list = append(list, map[string]interface{}{"category": category, "threshold": settings[category]})
+	}
+	return list
+}
+
+// reservedRequestKeys are structural request body keys raw params cannot override
+var reservedRequestKeys = []string{"contents"}
+
+// mergeRawParams merges raw params into the request body, recursing into nested maps
+func mergeRawParams(requestBody map[string]interface{}, rawParams map[string]interface{}) {
+	for key, value := range rawParams {
+		if contains(reservedRequestKeys, key) {
+			continue
+		}
+		mergeValue(requestBody, key, value)
+	}
+}
+
+// mergeValue sets key in dst, merging maps instead of replacing them
+func mergeValue(dst map[string]interface{}, key string, value interface{}) {
+	src, ok := value.(map[string]interface{})
+	if !ok {
+		dst[key] = value
+		return
+	}
+
+	existing, ok := dst[key].(map[string]interface{})
+	if !ok {
+		existing = make(map[string]interface{})
+		dst[key] = existing
+	}
+
+	for k, v := range src {
+		mergeValue(existing, k, v)
+	}
+}
 
+// contains checks if a slice contains the given string
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// makeAPICall makes the HTTP request to Gemini API, failing over to the next
+// configured endpoint on an outage, and returns the base URL that answered
+func (g *GeminiEvaluator) makeAPICall(ctx context.Context, requestBody map[string]interface{}) (map[string]interface{}, string, error) {
 	// Marshal request body
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
+	index, endpoint := g.endpoints.current()
+	for failovers := 0; ; failovers++ {
+		response, err := g.post(ctx, endpoint, jsonBody)
+		if err == nil {
+			return response, endpoint.BaseURL, nil
+		}
+		if failovers >= g.endpoints.maxFailovers || !isEndpointOutage(err) || ctx.Err() != nil {
+			return nil, endpoint.BaseURL, err
+		}
+		index, endpoint = g.endpoints.failover(index, err)
+	}
+}
+
+// post sends one generateContent request to endpoint
+func (g *GeminiEvaluator) post(ctx context.Context, endpoint Endpoint, jsonBody []byte) (map[string]interface{}, error) {
+	// Construct API URL
+	requestURL := endpoint.URL(fmt.Sprintf("models/%s:generateContent?key=%s", g.model, url.QueryEscape(g.apiKey)))
+
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		req.Header.Set(g.requestIDs.header, requestID)
+	}
 
 	// Execute request
 	resp, err := g.httpClient.Do(req)
@@ -173,9 +504,9 @@ func (g *GeminiEvaluator) makeAPICall(ctx context.Context, requestBody map[strin
 	if resp.StatusCode != http.StatusOK {
 		var errorResponse map[string]interface{}
 		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+			return nil, &APIError{StatusCode: resp.StatusCode}
 		}
-		return nil, fmt.Errorf("API error: %v", errorResponse)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: errorResponse}
 	}
 
 	// Parse response
@@ -189,12 +520,14 @@ func (g *GeminiEvaluator) makeAPICall(ctx context.Context, requestBody map[strin
 
 // parseResponse extracts the output and metadata from Gemini API response
 func (g *GeminiEvaluator) parseResponse(response map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
-	output := make(map[string]interface{})
 	metadata := make(map[string]interface{})
 
 	// Extract candidates
 	candidates, ok := response["candidates"].([]interface{})
 	if !ok || len(candidates) == 0 {
+		if feedback, ok := response["promptFeedback"].(map[string]interface{}); ok && feedback["blockReason"] != nil {
+			return nil, nil, fmt.Errorf("prompt blocked: %v", feedback["blockReason"])
+		}
 		return nil, nil, fmt.Errorf("no candidates in response")
 	}
 
@@ -204,29 +537,57 @@ func (g *GeminiEvaluator) parseResponse(response map[string]interface{}) (map[st
 		return nil, nil, fmt.Errorf("invalid candidate format")
 	}
 
-	// Extract content
-	content, ok := candidate["content"].(map[string]interface{})
-	if !ok {
-		return nil, nil, fmt.Errorf("no content in candidate")
+	text, err := candidateText(candidate)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Extract parts
-	parts, ok := content["parts"].([]interface{})
-	if !ok || len(parts) == 0 {
-		return nil, nil, fmt.Errorf("no parts in content")
+	output := buildOutput(text)
+
+	// Vote across samples when multiple candidates were requested
+	if g.samples() > 1 && g.majorityVoting() {
+		samples := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			if candidateMap, ok := c.(map[string]interface{}); ok {
+				if sampleText, err := candidateText(candidateMap); err == nil {
+					samples = append(samples, sampleText)
+				}
+			}
+		}
+
+		label, confidence := majorityVote(samples)
+		output["label"] = label
+		output["confidence"] = confidence
+		output["samples"] = samples
 	}
 
-	// Get text from first part
-	part, ok := parts[0].(map[string]interface{})
-	if !ok {
-		return nil, nil, fmt.Errorf("invalid part format")
+	// Add metadata
+	if safetyRatings, ok := candidate["safetyRatings"]; ok {
+		metadata["safetyRatings"] = safetyRatings
 	}
 
-	text, ok := part["text"].(string)
-	if !ok {
-		return nil, nil, fmt.Errorf("no text in part")
+	if finishReason, ok := candidate["finishReason"]; ok {
+		metadata["finishReason"] = finishReason
 	}
 
+	if usageMetadata, ok := response["usageMetadata"]; ok {
+		metadata["usage"] = usageMetadata
+	}
+
+	// Record the model that served the request, as reported by the API when available
+	metadata["provider"] = "gemini"
+	metadata["model"] = g.model
+	if modelVersion, ok := response["modelVersion"].(string); ok && modelVersion != "" {
+		metadata["model"] = modelVersion
+	}
+
+	return output, metadata, nil
+}
+
+// buildOutput stores the raw text response and its JSON form when it parses as JSON
+func buildOutput(text string) map[string]interface{} {
+	output := make(map[string]interface{})
+
 	// Store the raw text response
 	output["response"] = text
 
@@ -239,18 +600,51 @@ func (g *GeminiEvaluator) parseResponse(response map[string]interface{}) (map[st
 		}
 	}
 
-	// Add metadata
-	if safetyRatings, ok := candidate["safetyRatings"]; ok {
-		metadata["safetyRatings"] = safetyRatings
+	return output
+}
+
+// candidateText extracts the text of the first part of a candidate
+func candidateText(candidate map[string]interface{}) (string, error) {
+	// Extract content
+	content, ok := candidate["content"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("no content in candidate")
 	}
 
-	if finishReason, ok := candidate["finishReason"]; ok {
-		metadata["finishReason"] = finishReason
+	// Extract parts
+	parts, ok := content["parts"].([]interface{})
+	if !ok || len(parts) == 0 {
+		return "", fmt.Errorf("no parts in content")
 	}
 
-	if usageMetadata, ok := response["usageMetadata"]; ok {
-		metadata["usage"] = usageMetadata
+	// Get text from first part
+	part, ok := parts[0].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid part format")
 	}
 
-	return output, metadata, nil
-}
\ No newline at end of file
+	text, ok := part["text"].(string)
+	if !ok {
+		return "", fmt.Errorf("no text in part")
+	}
+
+	return text, nil
+}
+
+// samples returns the number of candidates requested via the n param
+func (g *GeminiEvaluator) samples() int {
+	switch n := g.params["n"].(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 1
+	}
+}
+
+// majorityVoting reports whether majority voting across samples is enabled
+func (g *GeminiEvaluator) majorityVoting() bool {
+	voting, _ := g.params["voting"].(string)
+	return voting == "majority"
+}
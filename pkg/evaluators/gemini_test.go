@@ -0,0 +1,461 @@
+package evaluators
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// geminiCandidate encodes a response candidate for servers that shape their own replies
+func geminiCandidate(text string) map[string]interface{} {
+	return geminitest.Candidate(text)
+}
+
+func newTestGeminiEvaluator(t *testing.T, cfg config.EvaluationConfig) *GeminiEvaluator {
+	t.Helper()
+
+	t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+	cfg.Auth.APIKeyEnv = "TEST_GEMINI_API_KEY"
+	if cfg.Model == "" {
+		cfg.Model = "gemini-pro"
+	}
+
+	evaluator, err := NewGeminiEvaluator(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create Gemini evaluator: %v", err)
+	}
+	return evaluator
+}
+
+func TestGeminiEvaluator_RawParams(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{
+			"temperature": 0.2,
+		},
+		RawParams: map[string]interface{}{
+			"generationConfig": map[string]interface{}{
+				"topK":           40,
+				"topP":           0.9,
+				"candidateCount": 1,
+			},
+			"cachedContent": "cache-123",
+			"contents":      "must not override",
+		},
+	})
+
+	body := evaluator.buildRequestBody("hello", 1)
+
+	generationConfig, ok := body["generationConfig"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected generationConfig map, got %T", body["generationConfig"])
+	}
+
+	if generationConfig["temperature"] != 0.2 {
+		t.Errorf("Expected temperature 0.2 to be preserved, got %v", generationConfig["temperature"])
+	}
+
+	if generationConfig["topK"] != 40 {
+		t.Errorf("Expected topK 40, got %v", generationConfig["topK"])
+	}
+
+	if generationConfig["topP"] != 0.9 {
+		t.Errorf("Expected topP 0.9, got %v", generationConfig["topP"])
+	}
+
+	if body["cachedContent"] != "cache-123" {
+		t.Errorf("Expected cachedContent to be passed through, got %v", body["cachedContent"])
+	}
+
+	if _, ok := body["contents"].([]map[string]interface{}); !ok {
+		t.Errorf("Expected contents to be preserved, got %v", body["contents"])
+	}
+}
+
+func TestGeminiEvaluator_SafetySettings(t *testing.T) {
+	// The mock blocks the prompt unless harassment filtering is relaxed
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			SafetySettings []map[string]string `json:"safetySettings"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		received.Store(body.SafetySettings)
+
+		for _, setting := range body.SafetySettings {
+			if setting["category"] == "HARM_CATEGORY_HARASSMENT" && setting["threshold"] == "BLOCK_NONE" {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"candidates": []interface{}{geminiCandidate("negative")},
+				})
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"promptFeedback": map[string]interface{}{"blockReason": "SAFETY"},
+		})
+	}))
+	defer server.Close()
+
+	record := sources.Record{"text": "you are all idiots"}
+
+	strict := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL},
+	})
+	if _, err := strict.Evaluate(context.Background(), record, "Classify: {{text}}"); err == nil || !strings.Contains(err.Error(), "prompt blocked: SAFETY") {
+		t.Errorf("Expected the default filters to block the prompt, got %v", err)
+	}
+	if got := received.Load().([]map[string]string); len(got) != 0 {
+		t.Errorf("Expected no safetySettings by default, got %v", got)
+	}
+
+	relaxed := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL},
+		SafetySettings: map[string]string{
+			"HARM_CATEGORY_HATE_SPEECH": "BLOCK_ONLY_HIGH",
+			"HARM_CATEGORY_HARASSMENT":  "BLOCK_NONE",
+		},
+	})
+	result, err := relaxed.Evaluate(context.Background(), record, "Classify: {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate with relaxed filters: %v", err)
+	}
+	if result.Output["response"] != "negative" {
+		t.Errorf("Expected the relaxed config to get an answer, got %v", result.Output)
+	}
+
+	want := []map[string]string{
+		{"category": "HARM_CATEGORY_HARASSMENT", "threshold": "BLOCK_NONE"},
+		{"category": "HARM_CATEGORY_HATE_SPEECH", "threshold": "BLOCK_ONLY_HIGH"},
+	}
+	if got := received.Load().([]map[string]string); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected safetySettings %v in the request body, got %v", want, got)
+	}
+}
+
+func TestGeminiEvaluator_Capabilities(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{})
+
+	expected := Capabilities{Batch: true}
+	if caps := evaluator.Capabilities(); caps != expected {
+		t.Errorf("Expected capabilities %+v, got %+v", expected, caps)
+	}
+}
+
+func TestGeminiEvaluator_BaseURL(t *testing.T) {
+	var gotPath, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.URL.Query().Get("key")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"candidates": []interface{}{geminiCandidate("positive")},
+		})
+	}))
+	defer server.Close()
+
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Model: "gemini-test",
+		Params: map[string]interface{}{
+			"base_url":    server.URL + "/",
+			"api_version": "v1",
+		},
+	})
+
+	result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "Classify {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate against local server: %v", err)
+	}
+
+	if gotPath != "/v1/models/gemini-test:generateContent" {
+		t.Errorf("Expected path /v1/models/gemini-test:generateContent, got %s", gotPath)
+	}
+	if gotKey != "test-key" {
+		t.Errorf("Expected API key test-key, got %s", gotKey)
+	}
+	if result.Output["response"] != "positive" {
+		t.Errorf("Expected response 'positive', got %v", result.Output["response"])
+	}
+}
+
+// recordingDoer answers every request with a canned Gemini response and keeps the requests
+type recordingDoer struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	answer   string
+}
+
+func (d *recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	d.requests = append(d.requests, req)
+	d.bodies = append(d.bodies, string(body))
+	d.mu.Unlock()
+
+	data, _ := json.Marshal(map[string]interface{}{"candidates": []interface{}{geminiCandidate(d.answer)}})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+func TestGeminiEvaluator_SetHTTPClient(t *testing.T) {
+	doer := &recordingDoer{answer: "positive"}
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{Model: "gemini-test"})
+	evaluator.SetHTTPClient(doer)
+
+	result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "great"}, "Classify {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate with injected client: %v", err)
+	}
+	if result.Output["response"] != "positive" {
+		t.Errorf("Expected response 'positive', got %v", result.Output["response"])
+	}
+
+	if len(doer.requests) != 1 {
+		t.Fatalf("Expected 1 recorded request, got %d", len(doer.requests))
+	}
+	req := doer.requests[0]
+	if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/models/gemini-test:generateContent") {
+		t.Errorf("Expected POST to gemini-test:generateContent, got %s %s", req.Method, req.URL.Path)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", req.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(doer.bodies[0], "Classify great") {
+		t.Errorf("Expected the rendered prompt in the request body, got %s", doer.bodies[0])
+	}
+
+	evaluator.SetHTTPClient(nil)
+	if _, ok := evaluator.httpClient.(*http.Client); !ok {
+		t.Errorf("Expected nil to restore the built-in client, got %T", evaluator.httpClient)
+	}
+}
+
+func TestDefaultFactory_SetHTTPClient(t *testing.T) {
+	t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+	doer := &recordingDoer{answer: "negative"}
+
+	factory := NewFactoryWithControls(config.ControlsConfig{Concurrency: 2})
+	factory.SetHTTPClient(doer)
+	evaluator, err := factory.CreateEvaluator("gemini", config.EvaluationConfig{
+		Model: "gemini-test",
+		Auth:  config.AuthConfig{APIKeyEnv: "TEST_GEMINI_API_KEY"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create evaluator: %v", err)
+	}
+
+	records := []sources.Record{{"text": "awful"}, {"text": "bad"}, {"text": "poor"}}
+	results, err := evaluator.BatchEvaluate(context.Background(), records, "Classify {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to batch evaluate: %v", err)
+	}
+	for i, result := range results {
+		if result.Error != nil || result.Output["response"] != "negative" {
+			t.Errorf("Expected result %d to come from the injected client, got %+v", i, result)
+		}
+	}
+	if len(doer.requests) != len(records) {
+		t.Errorf("Expected %d recorded requests, got %d", len(records), len(doer.requests))
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"default", nil, "https://generativelanguage.googleapis.com/v1beta/models", false},
+		{"endpoint alias", map[string]interface{}{"endpoint": "http://gateway:8080/gemini"}, "http://gateway:8080/gemini/v1beta/models", false},
+		{"no version", map[string]interface{}{"base_url": "http://gateway", "api_version": ""}, "http://gateway/models", false},
+		{"relative url", map[string]interface{}{"base_url": "gateway/gemini"}, "", true},
+		{"non-string", map[string]interface{}{"api_version": 1}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, err := resolveEndpoint("gemini", tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to resolve endpoint: %v", err)
+			}
+			if got := endpoint.URL("models"); got != tt.want {
+				t.Errorf("Expected URL %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	endpoints, err := resolveEndpoints("gemini", map[string]interface{}{
+		"endpoints":   []interface{}{"https://us-central1.example.com/", "https://europe-west4.example.com"},
+		"api_version": "v1",
+	})
+	if err != nil {
+		t.Fatalf("Failed to resolve endpoints: %v", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].URL("models") != "https://us-central1.example.com/v1/models" ||
+		endpoints[1].URL("models") != "https://europe-west4.example.com/v1/models" {
+		t.Errorf("Expected both regions in order with the shared version, got %v", endpoints)
+	}
+
+	invalid := []map[string]interface{}{
+		{"endpoints": []interface{}{}},
+		{"endpoints": "https://us-central1.example.com"},
+		{"endpoints": []interface{}{"us-central1.example.com"}},
+		{"endpoints": []interface{}{"https://a.example.com"}, "base_url": "https://b.example.com"},
+	}
+	for _, params := range invalid {
+		if _, err := resolveEndpoints("gemini", params); err == nil {
+			t.Errorf("Expected error for params %v, got nil", params)
+		}
+	}
+
+	if _, err := newEndpointPool(endpoints, map[string]interface{}{"max_failovers": -1}); err == nil {
+		t.Error("Expected error for negative max_failovers, got nil")
+	}
+}
+
+func TestGeminiEvaluator_EndpointFailover(t *testing.T) {
+	var primaryCalls, secondaryCalls atomic.Int64
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondaryCalls.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"candidates": []interface{}{geminiCandidate("positive")},
+		})
+	}))
+	defer secondary.Close()
+
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"endpoints": []interface{}{primary.URL, secondary.URL}},
+	})
+
+	for i := 0; i < 3; i++ {
+		result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "Classify {{text}}")
+		if err != nil {
+			t.Fatalf("Failed to evaluate with failover: %v", err)
+		}
+		if result.Metadata["endpoint"] != secondary.URL {
+			t.Errorf("Expected record %d to be served by %s, got %v", i, secondary.URL, result.Metadata["endpoint"])
+		}
+	}
+
+	// Only the first request hits the failed endpoint; later ones go straight to the next
+	if primaryCalls.Load() != 1 || secondaryCalls.Load() != 3 {
+		t.Errorf("Expected 1 primary and 3 secondary calls, got %d and %d", primaryCalls.Load(), secondaryCalls.Load())
+	}
+
+	// With failover disabled the outage fails the request
+	bounded := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"endpoints": []interface{}{primary.URL, secondary.URL}, "max_failovers": 0},
+	})
+	_, err := bounded.Evaluate(context.Background(), sources.Record{"text": "hi"}, "Classify {{text}}")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the 503 without failover, got %v", err)
+	}
+}
+
+func TestIsEndpointOutage(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		outage bool
+	}{
+		{"unavailable", &APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"gateway timeout", &APIError{StatusCode: http.StatusGatewayTimeout}, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, false},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest}, false},
+		{"unreachable", fmt.Errorf("API request failed: %w", &url.Error{Op: "Post", URL: "https://a", Err: errors.New("connection refused")}), true},
+		{"canceled", fmt.Errorf("API request failed: %w", &url.Error{Op: "Post", URL: "https://a", Err: context.Canceled}), false},
+	}
+
+	for _, tt := range tests {
+		if got := isEndpointOutage(tt.err); got != tt.outage {
+			t.Errorf("%s: isEndpointOutage(%v) = %v, want %v", tt.name, tt.err, got, tt.outage)
+		}
+	}
+}
+
+func TestGeminiEvaluator_ConcurrentBatch(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if current <= max || maxInFlight.CompareAndSwap(max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		text := body["contents"].([]interface{})[0].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["text"]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"candidates": []interface{}{geminiCandidate(text.(string))},
+		})
+	}))
+	defer server.Close()
+
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL},
+	})
+	evaluator.SetConcurrency(3)
+
+	records := make([]sources.Record, 9)
+	for i := range records {
+		records[i] = sources.Record{"text": fmt.Sprintf("record %d", i)}
+	}
+
+	results, err := evaluator.BatchEvaluate(context.Background(), records, "{{text}}")
+	if err != nil {
+		t.Fatalf("Failed to batch evaluate: %v", err)
+	}
+
+	if max := maxInFlight.Load(); max > 3 {
+		t.Errorf("Expected at most 3 requests in flight, got %d", max)
+	}
+	for i, result := range results {
+		if result.Error != nil {
+			t.Fatalf("Record %d failed: %v", i, result.Error)
+		}
+		if want := fmt.Sprintf("record %d", i); result.Output["response"] != want {
+			t.Errorf("Expected result %d to be %q, got %v", i, want, result.Output["response"])
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package geminitest provides a recording fake of the Gemini generateContent API for tests
+package geminitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Request is a request the server received
+type Request struct {
+	Header http.Header
+	Body   map[string]interface{} // decoded JSON body
+	Prompt string                 // text of the first content part
+}
+
+// Reply is the server's answer to a request
+type Reply struct {
+	Text   string                 // candidate text
+	Status int                    // HTTP error status to fail with instead, zero to answer
+	Usage  map[string]interface{} // usageMetadata, omitted when nil
+}
+
+// ReplyFunc answers the nth request, counting from 1
+type ReplyFunc func(n int, req Request) Reply
+
+// Answer replies to every request with text
+func Answer(text string) ReplyFunc {
+	return func(int, Request) Reply { return Reply{Text: text} }
+}
+
+// Server records every request and answers it through a ReplyFunc
+type Server struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewServer starts a server answering with reply; it is closed when the test ends
+func NewServer(t testing.TB, reply ReplyFunc) *Server {
+	t.Helper()
+
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := Request{Header: r.Header.Clone()}
+		if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		req.Prompt = prompt(req.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		n := len(s.requests)
+		s.mu.Unlock()
+
+		answer := reply(n, req)
+		if answer.Status != 0 {
+			http.Error(w, `{"error": {"message": "fake failure"}}`, answer.Status)
+			return
+		}
+		response := map[string]interface{}{"candidates": []interface{}{Candidate(answer.Text)}}
+		if answer.Usage != nil {
+			response["usageMetadata"] = answer.Usage
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns the requests received so far, in arrival order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Prompts returns the prompt of each request received so far
+func (s *Server) Prompts() []string {
+	requests := s.Requests()
+	prompts := make([]string, len(requests))
+	for i, req := range requests {
+		prompts[i] = req.Prompt
+	}
+	return prompts
+}
+
+// Candidate encodes a response candidate answering text
+func Candidate(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"content": map[string]interface{}{
+			"parts": []interface{}{
+				map[string]interface{}{"text": text},
+			},
+		},
+	}
+}
+
+// prompt returns the text of the first content part of a request body
+func prompt(body map[string]interface{}) string {
+	contents, _ := body["contents"].([]interface{})
+	if len(contents) == 0 {
+		return ""
+	}
+	content, _ := contents[0].(map[string]interface{})
+	parts, _ := content["parts"].([]interface{})
+	if len(parts) == 0 {
+		return ""
+	}
+	part, _ := parts[0].(map[string]interface{})
+	text, _ := part["text"].(string)
+	return text
+}
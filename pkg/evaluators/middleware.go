@@ -0,0 +1,406 @@
+package evaluators
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// Middleware decorates an evaluator with a cross-cutting concern
+type Middleware func(Evaluator) Evaluator
+
+// Chain wraps base with the given middlewares; the first middleware is the outermost
+func Chain(base Evaluator, middlewares ...Middleware) Evaluator {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+// DefaultRetryBackoff is the initial delay between retry attempts
+const DefaultRetryBackoff = 500 * time.Millisecond
+
+// MiddlewareFor returns the built-in middlewares enabled by the run controls.
+// Cache hits skip retries and rate limiting, and every retry attempt is rate limited.
+func MiddlewareFor(controls config.ControlsConfig, metrics *Metrics) []Middleware {
+	var middlewares []Middleware
+	if controls.LogRequests {
+		middlewares = append(middlewares, WithLogging(nil))
+	}
+	if metrics != nil {
+		middlewares = append(middlewares, WithMetrics(metrics))
+	}
+	if controls.Cache {
+		middlewares = append(middlewares, WithCache())
+	}
+	if controls.Retries > 0 {
+		middlewares = append(middlewares, WithRetry(controls.Retries, DefaultRetryBackoff))
+	}
+	if controls.RateLimit > 0 {
+		middlewares = append(middlewares, WithRateLimit(controls.RateLimit))
+	}
+	return middlewares
+}
+
+// WithLogging logs each evaluation call and its duration; a nil logger uses the standard logger
+func WithLogging(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next Evaluator) Evaluator {
+		return &loggingEvaluator{next: next, logger: logger}
+	}
+}
+
+type loggingEvaluator struct {
+	next   Evaluator
+	logger *log.Logger
+}
+
+func (l *loggingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	start := time.Now()
+	result, err := l.next.Evaluate(ctx, record, prompt)
+	if err != nil {
+		l.logger.Printf("evaluate failed after %s: %v", time.Since(start), err)
+	} else {
+		l.logger.Printf("evaluate completed in %s", time.Since(start))
+	}
+	return result, err
+}
+
+func (l *loggingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	start := time.Now()
+	results, err := l.next.BatchEvaluate(ctx, records, prompt)
+	if err != nil {
+		l.logger.Printf("batch of %d failed after %s: %v", len(records), time.Since(start), err)
+	} else {
+		l.logger.Printf("batch of %d completed in %s", len(records), time.Since(start))
+	}
+	return results, err
+}
+
+func (l *loggingEvaluator) Capabilities() Capabilities {
+	return l.next.Capabilities()
+}
+
+// WithCache memoizes successful results by prompt and record content for the
+// lifetime of the evaluator
+func WithCache() Middleware {
+	return func(next Evaluator) Evaluator {
+		return &cachingEvaluator{next: next}
+	}
+}
+
+type cachingEvaluator struct {
+	next    Evaluator
+	results sync.Map // cache key -> Result
+}
+
+// cacheKey hashes the prompt and record; encoding/json sorts map keys, so the key is canonical
+func cacheKey(record sources.Record, prompt string) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(prompt))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *cachingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	key, err := cacheKey(record, prompt)
+	if err != nil {
+		return c.next.Evaluate(ctx, record, prompt)
+	}
+	if cached, ok := c.results.Load(key); ok {
+		return cachedResult(cached.(Result), record), nil
+	}
+
+	result, err := c.next.Evaluate(ctx, record, prompt)
+	if err == nil && result.Error == nil {
+		c.results.Store(key, result)
+	}
+	return result, err
+}
+
+func (c *cachingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	results := make([]Result, len(records))
+	keys := make([]string, len(records))
+	var misses []sources.Record
+	var missIndexes []int
+
+	for i, record := range records {
+		key, err := cacheKey(record, prompt)
+		if err == nil {
+			if cached, ok := c.results.Load(key); ok {
+				results[i] = cachedResult(cached.(Result), record)
+				continue
+			}
+		}
+		keys[i] = key
+		misses = append(misses, record)
+		missIndexes = append(missIndexes, i)
+	}
+
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	fresh, err := c.next.BatchEvaluate(ctx, misses, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckAlignment(misses, fresh); err != nil {
+		return nil, err
+	}
+
+	for j, i := range missIndexes {
+		results[i] = fresh[j]
+		if fresh[j].Error == nil && keys[i] != "" {
+			c.results.Store(keys[i], fresh[j])
+		}
+	}
+	return results, nil
+}
+
+// cachedResult returns a cached result for record, pointing Input at the record
+// being evaluated rather than the one that populated the cache
+func cachedResult(cached Result, record sources.Record) Result {
+	cached.Input = record
+	return cached
+}
+
+func (c *cachingEvaluator) Capabilities() Capabilities {
+	return c.next.Capabilities()
+}
+
+// WithRetry retries calls that fail with a retryable error up to maxRetries
+// times, doubling the delay from backoff after each attempt
+func WithRetry(maxRetries int, backoff time.Duration) Middleware {
+	return func(next Evaluator) Evaluator {
+		return &retryingEvaluator{next: next, maxRetries: maxRetries, backoff: backoff}
+	}
+}
+
+type retryingEvaluator struct {
+	next       Evaluator
+	maxRetries int
+	backoff    time.Duration
+}
+
+// do runs call until it succeeds, fails permanently, or retries are exhausted
+func (r *retryingEvaluator) do(ctx context.Context, call func() error) error {
+	delay := r.backoff
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= r.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		if !sleep(ctx, delay) {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// sleep waits for delay, reporting false when ctx ends first
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (r *retryingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	var result Result
+	err := r.do(ctx, func() error {
+		var err error
+		result, err = r.next.Evaluate(ctx, record, prompt)
+		return err
+	})
+	return result, err
+}
+
+// BatchEvaluate retries a batch that fails as a whole, then re-runs the records
+// whose Result.Error is retryable, merging the new results back by index.
+// Evaluators such as GeminiEvaluator report per-record failures only that way.
+func (r *retryingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	var results []Result
+	err := r.do(ctx, func() error {
+		var err error
+		results, err = r.next.BatchEvaluate(ctx, records, prompt)
+		return err
+	})
+	if err != nil || CheckAlignment(records, results) != nil {
+		return results, err
+	}
+
+	delay := r.backoff
+	for attempt := 0; attempt < r.maxRetries; attempt++ {
+		var pending []int
+		for i, result := range results {
+			if isRetryable(result.Error) {
+				pending = append(pending, i)
+			}
+		}
+		if len(pending) == 0 || !sleep(ctx, delay) {
+			break
+		}
+		delay *= 2
+
+		retry := make([]sources.Record, len(pending))
+		for j, i := range pending {
+			retry[j] = records[i]
+		}
+		fresh, err := r.next.BatchEvaluate(ctx, retry, prompt)
+		if err != nil || CheckAlignment(retry, fresh) != nil {
+			// Keep the per-record errors of the last complete attempt
+			break
+		}
+		for j, i := range pending {
+			results[i] = fresh[j]
+		}
+	}
+	return results, nil
+}
+
+func (r *retryingEvaluator) Capabilities() Capabilities {
+	return r.next.Capabilities()
+}
+
+// WithRateLimit spaces calls so that at most perSecond requests start each
+// second; a batch counts as one request
+func WithRateLimit(perSecond float64) Middleware {
+	interval := time.Duration(float64(time.Second) / perSecond)
+	return func(next Evaluator) Evaluator {
+		return &rateLimitedEvaluator{next: next, interval: interval}
+	}
+}
+
+type rateLimitedEvaluator struct {
+	next     Evaluator
+	interval time.Duration
+
+	mu       sync.Mutex
+	nextSlot time.Time
+}
+
+// wait blocks until the next request slot or until ctx is done
+func (r *rateLimitedEvaluator) wait(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	slot := r.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	r.nextSlot = slot.Add(r.interval)
+	r.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (r *rateLimitedEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	if err := r.wait(ctx); err != nil {
+		return Result{}, err
+	}
+	return r.next.Evaluate(ctx, record, prompt)
+}
+
+func (r *rateLimitedEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.next.BatchEvaluate(ctx, records, prompt)
+}
+
+func (r *rateLimitedEvaluator) Capabilities() Capabilities {
+	return r.next.Capabilities()
+}
+
+// Metrics accumulates call counts and latency across evaluators; it is safe for concurrent use
+type Metrics struct {
+	calls   atomic.Int64
+	records atomic.Int64
+	errors  atomic.Int64
+	latency atomic.Int64 // nanoseconds
+}
+
+// Calls returns the number of Evaluate and BatchEvaluate calls
+func (m *Metrics) Calls() int64 { return m.calls.Load() }
+
+// Records returns the number of records evaluated
+func (m *Metrics) Records() int64 { return m.records.Load() }
+
+// Errors returns the number of calls that returned an error
+func (m *Metrics) Errors() int64 { return m.errors.Load() }
+
+// Latency returns the total time spent in evaluation calls
+func (m *Metrics) Latency() time.Duration { return time.Duration(m.latency.Load()) }
+
+func (m *Metrics) observe(records int, start time.Time, err error) {
+	m.calls.Add(1)
+	m.records.Add(int64(records))
+	m.latency.Add(int64(time.Since(start)))
+	if err != nil {
+		m.errors.Add(1)
+	}
+}
+
+// WithMetrics records call counts and latency into metrics
+func WithMetrics(metrics *Metrics) Middleware {
+	return func(next Evaluator) Evaluator {
+		return &meteredEvaluator{next: next, metrics: metrics}
+	}
+}
+
+type meteredEvaluator struct {
+	next    Evaluator
+	metrics *Metrics
+}
+
+func (m *meteredEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	start := time.Now()
+	result, err := m.next.Evaluate(ctx, record, prompt)
+	m.metrics.observe(1, start, err)
+	return result, err
+}
+
+func (m *meteredEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	start := time.Now()
+	results, err := m.next.BatchEvaluate(ctx, records, prompt)
+	m.metrics.observe(len(records), start, err)
+	return results, err
+}
+
+func (m *meteredEvaluator) Capabilities() Capabilities {
+	return m.next.Capabilities()
+}
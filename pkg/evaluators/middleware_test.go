@@ -0,0 +1,110 @@
+package evaluators
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// mockEvaluator echoes the record text and counts calls
+type mockEvaluator struct {
+	BaseEvaluator
+	calls    int
+	failures []error // returned by successive calls before succeeding
+}
+
+func (m *mockEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	m.calls++
+	if len(m.failures) > 0 {
+		err := m.failures[0]
+		m.failures = m.failures[1:]
+		return Result{}, err
+	}
+	return Result{Input: record, Output: map[string]interface{}{"response": record["text"]}}, nil
+}
+
+func (m *mockEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	results := make([]Result, len(records))
+	for i, record := range records {
+		result, err := m.Evaluate(ctx, record, prompt)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func TestMiddleware_LoggingAndCache(t *testing.T) {
+	var logs bytes.Buffer
+	mock := &mockEvaluator{}
+	evaluator := Chain(mock, WithLogging(log.New(&logs, "", 0)), WithCache())
+
+	ctx := context.Background()
+	record := sources.Record{"text": "hello"}
+
+	for i := 0; i < 3; i++ {
+		result, err := evaluator.Evaluate(ctx, record, "Echo {{text}}")
+		if err != nil {
+			t.Fatalf("Failed to evaluate: %v", err)
+		}
+		if result.Output["response"] != "hello" {
+			t.Errorf("Expected response 'hello', got %v", result.Output["response"])
+		}
+	}
+
+	if mock.calls != 1 {
+		t.Errorf("Expected 1 call to the base evaluator, got %d", mock.calls)
+	}
+
+	// Logging is outermost, so cache hits are logged too
+	if n := strings.Count(logs.String(), "evaluate completed"); n != 3 {
+		t.Errorf("Expected 3 log lines, got %d:\n%s", n, logs.String())
+	}
+
+	// A different prompt is a cache miss; the batch only sends uncached records
+	records := []sources.Record{record, {"text": "world"}}
+	if _, err := evaluator.BatchEvaluate(ctx, records, "Echo {{text}}"); err != nil {
+		t.Fatalf("Failed to batch evaluate: %v", err)
+	}
+	if mock.calls != 2 {
+		t.Errorf("Expected 2 calls after batch, got %d", mock.calls)
+	}
+	if _, err := evaluator.Evaluate(ctx, record, "Repeat {{text}}"); err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+	if mock.calls != 3 {
+		t.Errorf("Expected 3 calls after new prompt, got %d", mock.calls)
+	}
+}
+
+func TestMiddleware_Retry(t *testing.T) {
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	mock := &mockEvaluator{failures: []error{unavailable, unavailable}}
+	evaluator := Chain(mock, WithRetry(2, 0))
+	if _, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "p"); err != nil {
+		t.Fatalf("Expected retries to recover, got %v", err)
+	}
+	if mock.calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", mock.calls)
+	}
+
+	// Permanent errors are returned without retrying
+	badRequest := &APIError{StatusCode: http.StatusBadRequest}
+	mock = &mockEvaluator{failures: []error{badRequest}}
+	evaluator = Chain(mock, WithRetry(2, 0))
+	_, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "p")
+	if !errors.Is(err, badRequest) {
+		t.Errorf("Expected bad request error, got %v", err)
+	}
+	if mock.calls != 1 {
+		t.Errorf("Expected 1 attempt, got %d", mock.calls)
+	}
+}
@@ -0,0 +1,67 @@
+package evaluators
+
+import (
+	"context"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// PassthroughEvaluator copies each input record into its output without calling
+// a model, for testing mappings and metrics or as a no-op pipeline stage
+type PassthroughEvaluator struct {
+	BaseEvaluator
+	model         string
+	renderOptions RenderOptions
+	templates     templateCache
+}
+
+// NewPassthroughEvaluator creates a new passthrough evaluator
+func NewPassthroughEvaluator(cfg config.EvaluationConfig) *PassthroughEvaluator {
+	return &PassthroughEvaluator{model: cfg.Model, renderOptions: NewRenderOptions(cfg)}
+}
+
+// Evaluate copies the record's fields into the output. When a prompt is given,
+// the rendered prompt is returned as the response.
+func (p *PassthroughEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{Input: record, Error: err}, err
+	}
+
+	output := make(map[string]interface{}, len(record)+1)
+	for k, v := range record {
+		output[k] = v
+	}
+	if prompt != "" {
+		response, err := p.templates.get(prompt).render(record, p.renderOptions)
+		if err != nil {
+			return Result{Input: record, Error: err}, err
+		}
+		output["response"] = response
+	}
+
+	metadata := map[string]interface{}{"provider": "passthrough"}
+	if p.model != "" {
+		metadata["model"] = p.model
+	}
+
+	return Result{
+		Input:    record,
+		Output:   output,
+		Metadata: metadata,
+	}, nil
+}
+
+// BatchEvaluate copies each record into its output
+func (p *PassthroughEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	results := make([]Result, len(records))
+	for i, record := range records {
+		results[i], _ = p.Evaluate(ctx, record, prompt)
+	}
+	return results, nil
+}
+
+// Capabilities reports that passthrough evaluation supports batches
+func (p *PassthroughEvaluator) Capabilities() Capabilities {
+	return Capabilities{Batch: true}
+}
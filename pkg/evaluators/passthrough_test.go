@@ -0,0 +1,47 @@
+package evaluators
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+func TestPassthroughEvaluator_CopiesInput(t *testing.T) {
+	evaluator, err := NewDefaultFactory().CreateEvaluator("passthrough", config.EvaluationConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create passthrough evaluator: %v", err)
+	}
+
+	records := []sources.Record{
+		{"text": "I love it", "score": 0.9, "tags": []interface{}{"a", "b"}},
+		{"text": "Not great", "score": 0.1},
+	}
+
+	results, err := evaluator.BatchEvaluate(context.Background(), records, "Text: {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	for i, result := range results {
+		for field, value := range records[i] {
+			if !reflect.DeepEqual(result.Output[field], value) {
+				t.Errorf("Expected output field %s to be %v, got %v", field, value, result.Output[field])
+			}
+		}
+		if want := "Text: " + records[i]["text"].(string); result.Output["response"] != want {
+			t.Errorf("Expected response %q, got %v", want, result.Output["response"])
+		}
+	}
+
+	// Without a prompt only the input fields are copied
+	result, err := evaluator.Evaluate(context.Background(), records[1], "")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+	if !reflect.DeepEqual(result.Output, map[string]interface{}(records[1])) {
+		t.Errorf("Expected output %v, got %v", records[1], result.Output)
+	}
+}
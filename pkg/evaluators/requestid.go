@@ -0,0 +1,72 @@
+package evaluators
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// DefaultRequestIDHeader is the header carrying the request ID on evaluator HTTP calls
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context whose evaluator calls carry the given request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set by ContextWithRequestID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// requestIDs resolves the request ID of each evaluator call: the upstream ID
+// under the configured context key when present, otherwise a generated one
+type requestIDs struct {
+	header     string
+	contextKey interface{} // key an embedding service stores its request ID under; nil when unset
+}
+
+// newRequestIDs reads params.request_id_header and params.request_id_context_key
+func newRequestIDs(params map[string]interface{}) requestIDs {
+	ids := requestIDs{header: DefaultRequestIDHeader}
+	if header, ok := params["request_id_header"].(string); ok && header != "" {
+		ids.header = header
+	}
+	if key, ok := params["request_id_context_key"].(string); ok && key != "" {
+		ids.contextKey = key
+	}
+	return ids
+}
+
+// attach returns ctx carrying the call's request ID, and the ID
+func (r requestIDs) attach(ctx context.Context) (context.Context, string) {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return ctx, id
+	}
+	if r.contextKey != nil {
+		switch v := ctx.Value(r.contextKey).(type) {
+		case string:
+			if v != "" {
+				return ContextWithRequestID(ctx, v), v
+			}
+		case fmt.Stringer:
+			if id := v.String(); id != "" {
+				return ContextWithRequestID(ctx, id), id
+			}
+		}
+	}
+
+	id := newRequestID()
+	return ContextWithRequestID(ctx, id), id
+}
+
+// newRequestID generates a random 128-bit request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,83 @@
+package evaluators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// headerValues returns the value of a request header on every call the server received
+func headerValues(server *geminitest.Server, header string) []string {
+	requests := server.Requests()
+	values := make([]string, len(requests))
+	for i, req := range requests {
+		values[i] = req.Header.Get(header)
+	}
+	return values
+}
+
+type traceKey struct{}
+
+func TestGeminiEvaluator_PropagatesRequestID(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL},
+	})
+
+	ctx := ContextWithRequestID(context.Background(), "upstream-123")
+	result, err := evaluator.Evaluate(ctx, sources.Record{"text": "hi"}, "{{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	if got := headerValues(server, DefaultRequestIDHeader); len(got) != 1 || got[0] != "upstream-123" {
+		t.Errorf("Expected X-Request-ID upstream-123, got %v", got)
+	}
+	if result.Metadata["requestId"] != "upstream-123" {
+		t.Errorf("Expected metadata requestId upstream-123, got %v", result.Metadata["requestId"])
+	}
+}
+
+func TestGeminiEvaluator_ConfiguredRequestIDKey(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL, "request_id_header": "X-Trace-ID"},
+	})
+	evaluator.SetRequestIDContextKey(traceKey{})
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-9")
+	if _, err := evaluator.Evaluate(ctx, sources.Record{"text": "hi"}, "{{text}}"); err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	if got := headerValues(server, "X-Trace-ID"); len(got) != 1 || got[0] != "trace-9" {
+		t.Errorf("Expected X-Trace-ID trace-9, got %v", got)
+	}
+}
+
+func TestGeminiEvaluator_GeneratesRequestIDPerRecord(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL},
+	})
+
+	records := []sources.Record{{"text": "a"}, {"text": "b"}}
+	results, err := evaluator.BatchEvaluate(context.Background(), records, "{{text}}")
+	if err != nil {
+		t.Fatalf("Failed to batch evaluate: %v", err)
+	}
+
+	got := headerValues(server, DefaultRequestIDHeader)
+	if len(got) != 2 || got[0] == "" || got[0] == got[1] {
+		t.Fatalf("Expected a distinct generated request ID per record, got %v", got)
+	}
+	for i, result := range results {
+		id, _ := result.Metadata["requestId"].(string)
+		if id != got[0] && id != got[1] {
+			t.Errorf("Expected result %d requestId to match a sent header, got %q", i, id)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package evaluators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// APIError is returned when a provider API responds with a non-success status
+type APIError struct {
+	StatusCode int
+	Body       map[string]interface{}
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Body == nil {
+		return fmt.Sprintf("API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API error (status %d): %v", e.StatusCode, e.Body)
+}
+
+// isRetryable reports whether an evaluation error is transient and worth retrying.
+// Rate limits, server errors, and transient network failures are retried;
+// context cancellation, caller deadlines, permanent DNS failures, and errors of
+// non-idempotent evaluations under retry_idempotent_only are not.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var nonIdempotent nonIdempotentError
+	if errors.As(err, &nonIdempotent) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound && (dnsErr.IsTemporary || dnsErr.IsTimeout)
+	}
+
+	// Covers *url.Error and *net.OpError timeouts, including the HTTP client timeout
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	return false
+}
+
+// nonIdempotentError marks an error from an evaluation that must not be
+// retried even when the underlying failure is transient
+type nonIdempotentError struct {
+	err error
+}
+
+func (e nonIdempotentError) Error() string {
+	return e.err.Error()
+}
+
+func (e nonIdempotentError) Unwrap() error {
+	return e.err
+}
+
+// IsIdempotent reports whether repeating an evaluation is expected to return the
+// same output. Classification and extraction, and any evaluation sampled at
+// temperature 0 (from params, raw_params, or the strategy defaults), are. So are
+// seeded evaluations (params.seed or params.seed_per_record): the provider
+// reproduces a seeded sample, though that is best effort. Generation otherwise
+// samples at the provider's default temperature, which is above 0.
+func IsIdempotent(cfg config.EvaluationConfig) bool {
+	if cfg.Strategy != StrategyGeneration {
+		return true
+	}
+	if _, seeded := seedFor(cfg.Params, nil); seeded {
+		return true
+	}
+	if perRecord, _ := cfg.Params["seed_per_record"].(bool); perRecord {
+		return true
+	}
+
+	temperature, ok := StrategyDefaults(cfg.Strategy)["temperature"]
+	if value, exists := cfg.Params["temperature"]; exists {
+		temperature, ok = value, true
+	}
+	if generationConfig, isMap := cfg.RawParams["generationConfig"].(map[string]interface{}); isMap {
+		if value, exists := generationConfig["temperature"]; exists {
+			temperature, ok = value, true
+		}
+	}
+	if !ok {
+		return false
+	}
+
+	switch t := temperature.(type) {
+	case float64:
+		return t == 0
+	case int:
+		return t == 0
+	}
+	return false
+}
+
+// withoutRetries marks every error of next as not retryable, so retry
+// middlewares further out give up after the first attempt
+func withoutRetries(next Evaluator) Evaluator {
+	return &nonRetryingEvaluator{next: next}
+}
+
+type nonRetryingEvaluator struct {
+	next Evaluator
+}
+
+func (n *nonRetryingEvaluator) Evaluate(ctx context.Context, record sources.Record, prompt string) (Result, error) {
+	result, err := n.next.Evaluate(ctx, record, prompt)
+	if err != nil {
+		err = nonIdempotentError{err}
+	}
+	return result, err
+}
+
+func (n *nonRetryingEvaluator) BatchEvaluate(ctx context.Context, records []sources.Record, prompt string) ([]Result, error) {
+	results, err := n.next.BatchEvaluate(ctx, records, prompt)
+	if err != nil {
+		err = nonIdempotentError{err}
+	}
+	for i := range results {
+		if results[i].Error != nil {
+			results[i].Error = nonIdempotentError{results[i].Error}
+		}
+	}
+	return results, err
+}
+
+func (n *nonRetryingEvaluator) Capabilities() Capabilities {
+	return n.next.Capabilities()
+}
@@ -0,0 +1,226 @@
+package evaluators
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// failingTransport fails every request with err, honoring request cancellation like real transports
+type failingTransport struct {
+	err error
+}
+
+func (f failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return nil, f.err
+}
+
+func TestIsRetryable_InjectedTransportErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"timeout", timeoutError{}, true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"temporary dns failure", &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, true},
+		{"permanent dns failure", &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, false},
+		{"canceled context", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{})
+			evaluator.httpClient = &http.Client{Transport: failingTransport{err: tt.err}}
+
+			_, _, err := evaluator.makeAPICall(context.Background(), evaluator.buildRequestBody("hello", 1))
+			if err == nil {
+				t.Fatal("Expected transport error, got nil")
+			}
+
+			if got := isRetryable(err); got != tt.retryable {
+				t.Errorf("isRetryable(%v) = %v, want %v", err, got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestIsRetryable_StatusCodes(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryable(&APIError{StatusCode: tt.status}); got != tt.retryable {
+			t.Errorf("isRetryable(status %d) = %v, want %v", tt.status, got, tt.retryable)
+		}
+	}
+}
+
+func TestIsRetryable_CanceledRequest(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{})
+	evaluator.httpClient = &http.Client{Transport: failingTransport{err: timeoutError{}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := evaluator.makeAPICall(ctx, evaluator.buildRequestBody("hello", 1))
+	if err == nil {
+		t.Fatal("Expected error for canceled context, got nil")
+	}
+
+	if isRetryable(err) {
+		t.Errorf("Expected canceled request not to be retryable, got %v", err)
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.EvaluationConfig
+		want bool
+	}{
+		{"classification", config.EvaluationConfig{Strategy: StrategyClassification}, true},
+		{"classification above temperature 0", config.EvaluationConfig{Strategy: StrategyClassification, Params: map[string]interface{}{"temperature": 0.7}}, true},
+		{"generation at the provider default", config.EvaluationConfig{Strategy: StrategyGeneration}, false},
+		{"generation above temperature 0", config.EvaluationConfig{Strategy: StrategyGeneration, Params: map[string]interface{}{"temperature": 0.9}}, false},
+		{"generation at temperature 0", config.EvaluationConfig{Strategy: StrategyGeneration, Params: map[string]interface{}{"temperature": 0}}, true},
+		{"raw params temperature", config.EvaluationConfig{Strategy: StrategyGeneration, RawParams: map[string]interface{}{
+			"generationConfig": map[string]interface{}{"temperature": 0.0},
+		}}, true},
+		{"seeded generation", config.EvaluationConfig{Strategy: StrategyGeneration, Params: map[string]interface{}{"temperature": 0.9, "seed": 7}}, true},
+		{"per-record seed", config.EvaluationConfig{Strategy: StrategyGeneration, Params: map[string]interface{}{"temperature": 0.9, "seed_per_record": true}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsIdempotent(tt.cfg); got != tt.want {
+				t.Errorf("Expected IsIdempotent %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryIdempotentOnly_SkipsHighTemperatureGeneration(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+
+	factory := NewFactoryWithControls(config.ControlsConfig{Retries: 3, RetryIdempotentOnly: true})
+	evaluator, err := factory.CreateEvaluator("gemini", config.EvaluationConfig{
+		Model:    "gemini-pro",
+		Strategy: StrategyGeneration,
+		Params:   map[string]interface{}{"base_url": server.URL, "temperature": 1.2},
+		Auth:     config.AuthConfig{APIKeyEnv: "TEST_GEMINI_API_KEY"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create evaluator: %v", err)
+	}
+
+	_, err = evaluator.Evaluate(context.Background(), sources.Record{"text": "a story"}, "Write about {{text}}")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the 503 API error, got %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected a single attempt for high-temperature generation, got %d", got)
+	}
+}
+
+func TestWithoutRetries(t *testing.T) {
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	mock := &mockEvaluator{failures: []error{unavailable, unavailable}}
+	evaluator := Chain(withoutRetries(mock), WithRetry(2, 0))
+	if _, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "p"); !errors.Is(err, unavailable) {
+		t.Errorf("Expected the transient error to be returned, got %v", err)
+	}
+	if mock.calls != 1 {
+		t.Errorf("Expected 1 attempt, got %d", mock.calls)
+	}
+}
+
+func TestRetry_BatchEvaluateRetriesRecordErrors(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "positive"}]}}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GEMINI_API_KEY", "test-key")
+
+	gemini, err := NewGeminiEvaluator(config.EvaluationConfig{
+		Model:    "gemini-pro",
+		Strategy: "classification",
+		Params:   map[string]interface{}{"base_url": server.URL},
+		Auth:     config.AuthConfig{APIKeyEnv: "TEST_GEMINI_API_KEY"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create evaluator: %v", err)
+	}
+	gemini.SetConcurrency(1)
+
+	// GeminiEvaluator reports the 503 in Result.Error, not as the batch error
+	records := []sources.Record{{"text": "great"}, {"text": "fine"}}
+	results, err := Chain(gemini, WithRetry(2, 0)).BatchEvaluate(context.Background(), records, "Classify {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to batch evaluate: %v", err)
+	}
+	for i, result := range results {
+		if result.Error != nil || result.Output["response"] != "positive" {
+			t.Errorf("Expected record %d to succeed after the retry, got %+v", i, result)
+		}
+		if result.Input["text"] != records[i]["text"] {
+			t.Errorf("Expected result %d merged back for its record, got input %v", i, result.Input)
+		}
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected only the failed record to be retried, got %d requests", got)
+	}
+
+	// Per-record errors of non-idempotent evaluations are not retried either
+	requests.Store(0)
+	results, err = Chain(withoutRetries(gemini), WithRetry(2, 0)).BatchEvaluate(context.Background(), records[:1], "Classify {{text}}")
+	if err != nil {
+		t.Fatalf("Failed to batch evaluate: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(results[0].Error, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the 503 kept without retrying, got %v", results[0].Error)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected a single attempt, got %d", got)
+	}
+}
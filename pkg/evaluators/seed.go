@@ -0,0 +1,55 @@
+package evaluators
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// seedFor returns the sampling seed for a record: params.seed, or with
+// params.seed_per_record a seed derived from the record's content (mixed with
+// params.seed when set), so reruns reproduce each record's sample. A nil record
+// yields only the fixed seed.
+func seedFor(params map[string]interface{}, record sources.Record) (int64, bool) {
+	var base int64
+	seeded := false
+	switch v := params["seed"].(type) {
+	case int:
+		base, seeded = int64(v), true
+	case float64:
+		base, seeded = int64(v), true
+	}
+
+	perRecord, _ := params["seed_per_record"].(bool)
+	if !perRecord || record == nil {
+		return base, seeded
+	}
+
+	// encoding/json sorts map keys, so the encoding is canonical
+	data, err := json.Marshal(record)
+	if err != nil {
+		return base, seeded
+	}
+	h := fnv.New64a()
+	h.Write(data)
+
+	// Providers accept 32-bit seeds, so keep the derived seed in range
+	return int64((h.Sum64() ^ uint64(base)) % math.MaxInt32), true
+}
+
+// setSeed adds the seed to the request's generation config unless raw params
+// already set one, and returns the seed the request will use
+func setSeed(requestBody map[string]interface{}, seed int64) interface{} {
+	generationConfig, ok := requestBody["generationConfig"].(map[string]interface{})
+	if !ok {
+		generationConfig = make(map[string]interface{})
+		requestBody["generationConfig"] = generationConfig
+	}
+	if existing, exists := generationConfig["seed"]; exists {
+		return existing
+	}
+	generationConfig["seed"] = seed
+	return seed
+}
@@ -0,0 +1,91 @@
+package evaluators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/evaluators/geminitest"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// requestSeeds returns the generationConfig.seed of every request the server received
+func requestSeeds(server *geminitest.Server) []interface{} {
+	requests := server.Requests()
+	seeds := make([]interface{}, len(requests))
+	for i, req := range requests {
+		generationConfig, _ := req.Body["generationConfig"].(map[string]interface{})
+		seeds[i] = generationConfig["seed"]
+	}
+	return seeds
+}
+
+func TestGeminiEvaluator_Seed(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL, "seed": 42},
+	})
+
+	result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "{{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	got := requestSeeds(server)
+	if len(got) != 1 || got[0] != float64(42) {
+		t.Errorf("Expected request seed 42, got %v", got)
+	}
+	if result.Metadata["seed"] != int64(42) {
+		t.Errorf("Expected metadata seed 42, got %v", result.Metadata["seed"])
+	}
+}
+
+func TestGeminiEvaluator_SeedPerRecord(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL, "seed_per_record": true},
+	})
+
+	records := []sources.Record{{"text": "a"}, {"text": "b"}, {"text": "a"}}
+	for _, record := range records {
+		if _, err := evaluator.Evaluate(context.Background(), record, "{{text}}"); err != nil {
+			t.Fatalf("Failed to evaluate: %v", err)
+		}
+	}
+
+	got := requestSeeds(server)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(got))
+	}
+	if got[0] == nil {
+		t.Fatalf("Expected a derived seed in the request")
+	}
+	if got[0] != got[2] {
+		t.Errorf("Expected identical records to share a seed, got %v and %v", got[0], got[2])
+	}
+	if got[0] == got[1] {
+		t.Errorf("Expected different records to get different seeds, got %v for both", got[0])
+	}
+}
+
+func TestGeminiEvaluator_RawParamsSeedWins(t *testing.T) {
+	server := geminitest.NewServer(t, geminitest.Answer("positive"))
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{"base_url": server.URL, "seed": 42},
+		RawParams: map[string]interface{}{
+			"generationConfig": map[string]interface{}{"seed": 7},
+		},
+	})
+
+	result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "{{text}}")
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	if got := requestSeeds(server); len(got) != 1 || got[0] != float64(7) {
+		t.Errorf("Expected raw_params seed 7, got %v", got)
+	}
+	if result.Metadata["seed"] != 7 {
+		t.Errorf("Expected metadata seed 7, got %v", result.Metadata["seed"])
+	}
+}
@@ -0,0 +1,49 @@
+package evaluators
+
+// Evaluation strategies
+const (
+	StrategyClassification = "classification"
+	StrategyExtraction     = "extraction"
+	StrategyGeneration     = "generation"
+)
+
+// strategyDefaults are the generation config settings each strategy starts from.
+// Explicit params and raw_params override them.
+var strategyDefaults = map[string]map[string]interface{}{
+	// Labels are short and should be stable across runs
+	StrategyClassification: {"temperature": 0.0, "maxOutputTokens": 16},
+	// Extracted fields come back as a JSON object
+	StrategyExtraction: {"responseMimeType": "application/json"},
+	// Free-form text needs room to finish
+	StrategyGeneration: {"maxOutputTokens": 2048},
+}
+
+// StrategyDefaults returns a copy of the generation config defaults for a strategy
+func StrategyDefaults(strategy string) map[string]interface{} {
+	defaults := make(map[string]interface{}, len(strategyDefaults[strategy]))
+	for k, v := range strategyDefaults[strategy] {
+		defaults[k] = v
+	}
+	return defaults
+}
+
+// strategyGenerationConfig returns the strategy defaults for a request carrying
+// the given number of answers. Packed classification requests get room for every
+// label, and packed extraction requests skip JSON mode since their answers are
+// numbered lines rather than a single object.
+func strategyGenerationConfig(strategy string, answers int) map[string]interface{} {
+	defaults := StrategyDefaults(strategy)
+	if answers <= 1 {
+		return defaults
+	}
+
+	switch strategy {
+	case StrategyClassification:
+		if maxTokens, ok := defaults["maxOutputTokens"].(int); ok {
+			defaults["maxOutputTokens"] = maxTokens * answers
+		}
+	case StrategyExtraction:
+		delete(defaults, "responseMimeType")
+	}
+	return defaults
+}
@@ -0,0 +1,74 @@
+package evaluators
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestBuildRequestBody_StrategyDefaults(t *testing.T) {
+	tests := []struct {
+		strategy string
+		answers  int
+		want     map[string]interface{}
+	}{
+		{StrategyClassification, 1, map[string]interface{}{"temperature": 0.0, "maxOutputTokens": 16}},
+		{StrategyClassification, 4, map[string]interface{}{"temperature": 0.0, "maxOutputTokens": 64}},
+		{StrategyExtraction, 1, map[string]interface{}{"responseMimeType": "application/json"}},
+		{StrategyExtraction, 4, nil},
+		{StrategyGeneration, 1, map[string]interface{}{"maxOutputTokens": 2048}},
+		{"", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{Strategy: tt.strategy})
+
+			body := evaluator.buildRequestBody("hello", tt.answers)
+
+			got, _ := body["generationConfig"].(map[string]interface{})
+			if tt.want == nil {
+				if _, ok := body["generationConfig"]; ok {
+					t.Errorf("Expected no generationConfig, got %v", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected generationConfig %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildRequestBody_ParamsOverrideStrategy(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Strategy: StrategyClassification,
+		Params:   map[string]interface{}{"temperature": 0.7, "max_tokens": 100},
+		RawParams: map[string]interface{}{
+			"generationConfig": map[string]interface{}{"responseMimeType": "text/plain"},
+		},
+	})
+
+	body := evaluator.buildRequestBody("hello", 1)
+
+	generationConfig := body["generationConfig"].(map[string]interface{})
+	if generationConfig["temperature"] != 0.7 {
+		t.Errorf("Expected temperature 0.7, got %v", generationConfig["temperature"])
+	}
+	if generationConfig["maxOutputTokens"] != 100 {
+		t.Errorf("Expected maxOutputTokens 100, got %v", generationConfig["maxOutputTokens"])
+	}
+	if generationConfig["responseMimeType"] != "text/plain" {
+		t.Errorf("Expected raw_params responseMimeType, got %v", generationConfig["responseMimeType"])
+	}
+}
+
+func TestStrategyDefaults_ReturnsCopy(t *testing.T) {
+	defaults := StrategyDefaults(StrategyGeneration)
+	defaults["maxOutputTokens"] = 1
+
+	if got := StrategyDefaults(StrategyGeneration)["maxOutputTokens"]; got != 2048 {
+		t.Errorf("Expected defaults to be unchanged, got %v", got)
+	}
+}
@@ -0,0 +1,137 @@
+package evaluators
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats for object and array values substituted into prompts
+const (
+	ValueFormatJSON = "json"
+	ValueFormatYAML = "yaml"
+	ValueFormatGo   = "go"
+)
+
+// placeholderPattern matches template variables like {{field_name}}
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// promptTemplate is a prompt compiled once into literal and variable segments
+type promptTemplate struct {
+	literals  []string // len(literals) == len(variables)+1
+	variables []string
+}
+
+// compilePrompt splits a prompt into literal text and template variables
+func compilePrompt(prompt string) *promptTemplate {
+	tmpl := &promptTemplate{}
+
+	last := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(prompt, -1) {
+		tmpl.literals = append(tmpl.literals, prompt[last:match[0]])
+		tmpl.variables = append(tmpl.variables, prompt[match[2]:match[3]])
+		last = match[1]
+	}
+	tmpl.literals = append(tmpl.literals, prompt[last:])
+
+	return tmpl
+}
+
+// RenderOptions controls how template variables are resolved and formatted
+type RenderOptions struct {
+	ValueFormat string            // json (default), yaml, or go rendering of object and array values
+	Mappings    map[string]string // template variable -> record field path, as in evaluation.mappings.input
+	Strict      bool              // fail on unresolved variables instead of leaving them as-is
+}
+
+// NewRenderOptions returns the render options an evaluation config implies
+func NewRenderOptions(cfg config.EvaluationConfig) RenderOptions {
+	return RenderOptions{
+		ValueFormat: cfg.TemplateValueFormat,
+		Mappings:    cfg.Mappings.Input,
+		Strict:      cfg.StrictTemplate,
+	}
+}
+
+// render fills the template with record values, formatting objects and arrays
+// as opts.ValueFormat. Each variable resolves through opts.Mappings first, then
+// as a top-level field, then as a dotted path into nested objects (user.name).
+// Unresolved variables are left as-is unless opts.Strict is set. Safe for
+// concurrent use.
+func (t *promptTemplate) render(record sources.Record, opts RenderOptions) (string, error) {
+	var b strings.Builder
+	for i, variable := range t.variables {
+		b.WriteString(t.literals[i])
+		if value, ok := resolveVariable(record, strings.TrimSpace(variable), opts.Mappings); ok {
+			b.WriteString(formatValue(value, opts.ValueFormat))
+		} else if opts.Strict {
+			return "", fmt.Errorf("template variable %s is not set in the record", strings.TrimSpace(variable))
+		} else {
+			b.WriteString("{{" + variable + "}}")
+		}
+	}
+	b.WriteString(t.literals[len(t.literals)-1])
+	return b.String(), nil
+}
+
+// resolveVariable looks up a template variable in record, through its mapped
+// field path when one is configured
+func resolveVariable(record sources.Record, variable string, mappings map[string]string) (interface{}, bool) {
+	if path, ok := mappings[variable]; ok {
+		if value, ok := sources.LookupField(record, strings.TrimPrefix(path, "$.")); ok {
+			return value, true
+		}
+	}
+	return sources.LookupField(record, variable)
+}
+
+// formatValue renders a record value for a prompt. Scalars print as-is; objects
+// and arrays are encoded as JSON (the default), YAML, or Go syntax.
+func formatValue(value interface{}, valueFormat string) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, sources.Record:
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+
+	switch valueFormat {
+	case ValueFormatGo:
+		return fmt.Sprintf("%v", value)
+	case ValueFormatYAML:
+		if data, err := yaml.Marshal(value); err == nil {
+			return strings.TrimRight(string(data), "\n")
+		}
+	default:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// templateCache compiles each distinct prompt once and reuses it across records
+type templateCache struct {
+	templates sync.Map // prompt string -> *promptTemplate
+}
+
+// get returns the compiled template for prompt, compiling it on first use
+func (c *templateCache) get(prompt string) *promptTemplate {
+	if cached, ok := c.templates.Load(prompt); ok {
+		return cached.(*promptTemplate)
+	}
+	compiled, _ := c.templates.LoadOrStore(prompt, compilePrompt(prompt))
+	return compiled.(*promptTemplate)
+}
+
+// RenderPrompt fills the prompt's {{field}} variables from record exactly as
+// evaluators do before sending a request, so prompts can be inspected and
+// tested without running an evaluation
+func RenderPrompt(prompt string, record sources.Record, opts RenderOptions) (string, error) {
+	return compilePrompt(prompt).render(record, opts)
+}
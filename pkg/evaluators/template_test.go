@@ -0,0 +1,182 @@
+package evaluators
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"github.com/adhaamehab/meval.ai/pkg/sources"
+)
+
+// naiveRender is the previous per-record ReplaceAll templating, kept as a benchmark baseline
+func naiveRender(prompt string, record sources.Record) string {
+	for key, value := range record {
+		prompt = strings.ReplaceAll(prompt, fmt.Sprintf("{{%s}}", key), fmt.Sprintf("%v", value))
+	}
+	return prompt
+}
+
+const benchmarkPrompt = `Given the text below, output exactly one word:
+positive, negative, or neutral.
+
+Text: {{text}}
+Model prediction: {{predicted_sentiment}}
+Unknown stays: {{missing}}`
+
+func TestPromptTemplate_Render(t *testing.T) {
+	record := sources.Record{"text": "I love it", "predicted_sentiment": "positive", "score": 0.9}
+
+	got, _ := compilePrompt(benchmarkPrompt).render(record, RenderOptions{})
+	want := naiveRender(benchmarkPrompt, record)
+
+	if got != want {
+		t.Errorf("Expected compiled render to match ReplaceAll templating\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if !strings.Contains(got, "{{missing}}") {
+		t.Errorf("Expected missing variables to be left as-is, got %q", got)
+	}
+}
+
+func TestTemplateCache_ConcurrentRender(t *testing.T) {
+	var cache templateCache
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			record := sources.Record{"text": fmt.Sprintf("record %d", i), "predicted_sentiment": "neutral"}
+			got, _ := cache.get(benchmarkPrompt).render(record, RenderOptions{})
+			if !strings.Contains(got, fmt.Sprintf("Text: record %d\n", i)) {
+				t.Errorf("Expected rendered prompt for record %d, got %q", i, got)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func benchmarkRecords(n int) []sources.Record {
+	records := make([]sources.Record, n)
+	for i := range records {
+		records[i] = sources.Record{
+			"text":                fmt.Sprintf("This is review number %d", i),
+			"predicted_sentiment": "positive",
+			"id":                  i,
+			"source":              "benchmark",
+		}
+	}
+	return records
+}
+
+func BenchmarkPromptTemplate_Naive(b *testing.B) {
+	records := benchmarkRecords(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, record := range records {
+			naiveRender(benchmarkPrompt, record)
+		}
+	}
+}
+
+func BenchmarkPromptTemplate_Cached(b *testing.B) {
+	records := benchmarkRecords(1000)
+	var cache templateCache
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, record := range records {
+			cache.get(benchmarkPrompt).render(record, RenderOptions{})
+		}
+	}
+}
+
+func TestPromptTemplate_ValueFormats(t *testing.T) {
+	record := sources.Record{
+		"review": map[string]interface{}{"rating": 4.0, "text": "good"},
+		"tags":   []interface{}{"fast", "cheap"},
+		"title":  "Delivery",
+	}
+	tmpl := compilePrompt("{{title}}: {{review}} {{tags}}")
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{ValueFormatJSON, `Delivery: {"rating":4,"text":"good"} ["fast","cheap"]`},
+		{"", `Delivery: {"rating":4,"text":"good"} ["fast","cheap"]`},
+		{ValueFormatYAML, "Delivery: rating: 4\ntext: good - fast\n- cheap"},
+		{ValueFormatGo, "Delivery: map[rating:4 text:good] [fast cheap]"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := tmpl.render(record, RenderOptions{ValueFormat: tt.format}); got != tt.want {
+			t.Errorf("Expected %q format to render %q, got %q", tt.format, tt.want, got)
+		}
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	record := sources.Record{
+		"text":       "I love it",
+		"prediction": "positive",
+		"user":       map[string]interface{}{"name": "Ada", "address": map[string]interface{}{"city": "London"}},
+		"meta.id":    7,
+		"tags":       []interface{}{"a", "b"},
+	}
+
+	tests := []struct {
+		name    string
+		prompt  string
+		opts    RenderOptions
+		want    string
+		wantErr bool
+	}{
+		{"top-level field", "Text: {{text}}", RenderOptions{}, "Text: I love it", false},
+		{"spaces inside braces", "Text: {{ text }}", RenderOptions{}, "Text: I love it", false},
+		{"nested path", "{{user.name}} from {{user.address.city}}", RenderOptions{}, "Ada from London", false},
+		{"dotted top-level field wins", "ID {{meta.id}}", RenderOptions{}, "ID 7", false},
+		{"nested object as JSON", "{{user.address}}", RenderOptions{}, `{"city":"London"}`, false},
+		{"array as YAML", "{{tags}}", RenderOptions{ValueFormat: ValueFormatYAML}, "- a\n- b", false},
+		{"mapped variable", "Predicted: {{predicted_sentiment}}", RenderOptions{Mappings: map[string]string{"predicted_sentiment": "prediction"}}, "Predicted: positive", false},
+		{"mapped nested path", "{{city}}", RenderOptions{Mappings: map[string]string{"city": "$.user.address.city"}}, "London", false},
+		{"unresolved mapping falls back to the field", "{{text}}", RenderOptions{Mappings: map[string]string{"text": "body"}}, "I love it", false},
+		{"missing left as-is", "{{missing}} {{user.age}}", RenderOptions{}, "{{missing}} {{user.age}}", false},
+		{"strict missing", "{{missing}}", RenderOptions{Strict: true}, "", true},
+		{"strict missing nested", "{{user.age}}", RenderOptions{Strict: true}, "", true},
+		{"strict resolved", "{{text}}", RenderOptions{Strict: true}, "I love it", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderPrompt(tt.prompt, record, tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to render prompt: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGeminiEvaluator_StrictTemplate(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{StrictTemplate: true})
+
+	result, err := evaluator.Evaluate(context.Background(), sources.Record{"text": "hi"}, "{{text}} {{label}}")
+	if err == nil || result.Error == nil {
+		t.Fatalf("Expected an error for an unresolved variable before any request, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "label") {
+		t.Errorf("Expected the error to name the variable, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package evaluators
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer counts tokens for a model, e.g. for cost estimation and prompt-length checks
+type Tokenizer interface {
+	CountTokens(text, model string) (int, error)
+}
+
+// ApproximateTokenizer estimates token counts from character and word counts.
+// It is model-agnostic; plug in a BPE-backed Tokenizer where exact counts matter.
+type ApproximateTokenizer struct{}
+
+// NewApproximateTokenizer creates a new approximate tokenizer
+func NewApproximateTokenizer() *ApproximateTokenizer {
+	return &ApproximateTokenizer{}
+}
+
+// CountTokens averages the ~4 characters per token and ~0.75 words per token heuristics
+func (a *ApproximateTokenizer) CountTokens(text, model string) (int, error) {
+	if strings.TrimSpace(text) == "" {
+		return 0, nil
+	}
+
+	byChars := float64(utf8.RuneCountInString(text)) / 4
+	byWords := float64(len(strings.Fields(text))) / 0.75
+
+	return int(math.Ceil((byChars + byWords) / 2)), nil
+}
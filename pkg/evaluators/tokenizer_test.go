@@ -0,0 +1,34 @@
+package evaluators
+
+import "testing"
+
+func TestApproximateTokenizer_CountTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		known int // count from a BPE tokenizer
+	}{
+		{"pangram", "The quick brown fox jumps over the lazy dog.", 10},
+		{"prompt", "Given the text below, output exactly one word: positive, negative, or neutral.", 16},
+	}
+
+	tokenizer := NewApproximateTokenizer()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := tokenizer.CountTokens(tt.text, "gemini-pro")
+			if err != nil {
+				t.Fatalf("CountTokens failed: %v", err)
+			}
+
+			low, high := tt.known*7/10, tt.known*13/10
+			if count < low || count > high {
+				t.Errorf("Expected count within [%d, %d] of known %d, got %d", low, high, tt.known, count)
+			}
+		})
+	}
+
+	if count, _ := tokenizer.CountTokens("   ", "gemini-pro"); count != 0 {
+		t.Errorf("Expected 0 tokens for blank text, got %d", count)
+	}
+}
@@ -0,0 +1,35 @@
+package evaluators
+
+import (
+	"sort"
+	"strings"
+)
+
+// majorityVote returns the most common label among samples and the fraction of
+// samples agreeing with it. Labels are compared trimmed and case-insensitively,
+// and ties resolve to the lexicographically smallest label.
+func majorityVote(samples []string) (string, float64) {
+	if len(samples) == 0 {
+		return "", 0
+	}
+
+	counts := make(map[string]int)
+	for _, sample := range samples {
+		counts[strings.ToLower(strings.TrimSpace(sample))]++
+	}
+
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	winner := labels[0]
+	for _, label := range labels[1:] {
+		if counts[label] > counts[winner] {
+			winner = label
+		}
+	}
+
+	return winner, float64(counts[winner]) / float64(len(samples))
+}
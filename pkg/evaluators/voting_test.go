@@ -0,0 +1,54 @@
+package evaluators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestMajorityVote_Confidence(t *testing.T) {
+	evaluator := newTestGeminiEvaluator(t, config.EvaluationConfig{
+		Params: map[string]interface{}{
+			"n":      3,
+			"voting": "majority",
+		},
+	})
+
+	body := evaluator.buildRequestBody("hello", 1)
+	generationConfig, _ := body["generationConfig"].(map[string]interface{})
+	if generationConfig["candidateCount"] != 3 {
+		t.Errorf("Expected candidateCount 3, got %v", generationConfig["candidateCount"])
+	}
+
+	response := map[string]interface{}{
+		"candidates": []interface{}{
+			geminiCandidate("positive"),
+			geminiCandidate("negative"),
+			geminiCandidate(" Positive\n"),
+		},
+	}
+
+	output, _, err := evaluator.parseResponse(response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if output["label"] != "positive" {
+		t.Errorf("Expected label positive, got %v", output["label"])
+	}
+
+	confidence, ok := output["confidence"].(float64)
+	if !ok || math.Abs(confidence-0.667) > 0.001 {
+		t.Errorf("Expected confidence 0.667, got %v", output["confidence"])
+	}
+}
+
+func TestMajorityVote_TieIsDeterministic(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		label, confidence := majorityVote([]string{"positive", "negative"})
+		if label != "negative" || confidence != 0.5 {
+			t.Fatalf("Expected tie to resolve to negative with 0.5, got %s with %v", label, confidence)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Append adds records to the file at path, keeping it a complete JSON file after
+// every call. Lines mode appends to the file in place (as a new gzip member
+// when compressed); array and object mode read the records already in the file
+// and atomically rewrite it with the new ones added, so appending is linear in
+// the file size.
+func (j *JSONSource) Append(ctx context.Context, records []Record) error {
+	if IsStdioPath(j.path) {
+		return fmt.Errorf("cannot append to stdout; use Write")
+	}
+	if j.fsys != nil {
+		return fmt.Errorf("JSON source backed by fs.FS is read-only")
+	}
+	if j.mode == "auto" {
+		return fmt.Errorf("mode auto is only supported for reading")
+	}
+	if j.writer != nil {
+		return fmt.Errorf("cannot append to a JSON source that is being written; use either Write or Append")
+	}
+
+	if j.split != nil {
+		return j.writeSplit(ctx, records)
+	}
+
+	formatted := make([]Record, 0, len(records))
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := j.validator.validateOutputFields(record, j.errorField); err != nil {
+			return fmt.Errorf("record validation failed: %w", err)
+		}
+		formatted = append(formatted, j.formatRecord(record))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	compressed := isCompressed(j.compression, j.path)
+	if j.mode == "lines" {
+		return appendJSONLines(j.path, formatted, j.encoder, compressed, j.fsync)
+	}
+	return j.rewriteWithAppended(formatted, compressed)
+}
+
+// appendJSONLines appends one JSON line per record to path, first terminating
+// a final line left without a newline. Compressed files get the lines as an
+// extra gzip member, which gzip readers decode as one stream. With sync set,
+// the file is synced to disk before it is closed.
+func appendJSONLines(path string, records []Record, encode EncoderFunc, compressed, sync bool) error {
+	file, unterminated, err := openAppend(path, compressed)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if unterminated {
+		buf.WriteByte('\n')
+	}
+
+	encoder := encode(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+	}
+
+	data := buf.Bytes()
+	if compressed {
+		if data, err = gzipBytes(data); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to compress records: %w", err)
+		}
+	}
+
+	// A single write keeps a failed append from leaving half a batch behind
+	var output io.WriteCloser = file
+	if sync {
+		output = syncingFile{file}
+	}
+	return writeAndClose(output, data)
+}
+
+// openAppend opens path for appending, creating it if needed, and reports
+// whether it ends in a line without a newline that must be terminated before
+// more lines are added. Compressed files are not inspected.
+func openAppend(path string, compressed bool) (*os.File, bool, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 || compressed {
+		return file, false, nil
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		file.Close()
+		return nil, false, fmt.Errorf("failed to read file: %w", err)
+	}
+	return file, last[0] != '\n', nil
+}
+
+// rewriteWithAppended rewrites an array or object mode file with records added
+// after the ones it already holds. The new content is written to a temporary
+// file and renamed over the old one, so readers never see a partial file.
+func (j *JSONSource) rewriteWithAppended(records []Record, compressed bool) error {
+	existing, err := readPersistedRecords(j.path, compressed)
+	if err != nil {
+		return fmt.Errorf("failed to read existing records: %w", err)
+	}
+	all := append(existing, records...)
+
+	var value interface{} = all
+	switch {
+	case j.mode == "object" && len(all) == 1:
+		value = all[0]
+	case all == nil:
+		value = []Record{}
+	}
+
+	var buf bytes.Buffer
+	encoder := j.encoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+
+	data := buf.Bytes()
+	if compressed {
+		if data, err = gzipBytes(data); err != nil {
+			return fmt.Errorf("failed to compress records: %w", err)
+		}
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(j.path), ".meval-append-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	// With fsync, the new content is on disk before it replaces the old
+	var output io.WriteCloser = temp
+	if j.fsync {
+		output = syncingFile{temp}
+	}
+	if err := writeAndClose(output, data); err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), j.path); err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
+
+// readPersistedRecords decodes the records of an array or object file at path,
+// returning none when the file does not exist or is empty
+func readPersistedRecords(path string, compressed bool) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if compressed && len(data) > 0 {
+		if data, err = gunzipBytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var records []Record
+	if data[0] == '{' {
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			return nil, err
+		}
+		records = []Record{record}
+	} else if err := decoder.Decode(&records); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return records, nil
+}
@@ -0,0 +1,239 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestJSONSource_Append(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "number"}}}
+
+	for _, mode := range []string{"array", "lines", "object"} {
+		t.Run(mode, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out", "results.json")
+			cfg := map[string]interface{}{"path": path, "mode": mode}
+
+			var want []Record
+			for call := 0; call < 3; call++ {
+				// Each call appends through a fresh source, as separate runs would
+				source, err := NewJSONSource(cfg, schema)
+				if err != nil {
+					t.Fatalf("Failed to create JSON source: %v", err)
+				}
+				batch := []Record{{"id": float64(call * 2)}, {"id": float64(call*2 + 1)}}
+				if err := Append(context.Background(), source, batch); err != nil {
+					t.Fatalf("Failed to append batch %d: %v", call, err)
+				}
+				if err := source.Close(); err != nil {
+					t.Fatalf("Failed to close source: %v", err)
+				}
+				want = append(want, batch...)
+
+				// The file is complete after every call; object mode holds an array
+				// once it has more than one record, which mode auto reads
+				reader, err := NewJSONSource(map[string]interface{}{"path": path, "mode": "auto"}, schema)
+				if err != nil {
+					t.Fatalf("Failed to create JSON source: %v", err)
+				}
+				got, err := reader.Read(context.Background())
+				if err != nil {
+					t.Fatalf("Failed to read after append %d: %v", call, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Expected %v after append %d, got %v", want, call, got)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONSource_AppendSingleObject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	cfg := map[string]interface{}{"path": path, "mode": "object"}
+	source, err := NewJSONSource(cfg, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	if err := source.Append(context.Background(), []Record{{"id": "a"}}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\n  \"id\": \"a\"\n}\n" {
+		t.Errorf("Expected a single object, got %s", data)
+	}
+
+	if err := source.Append(context.Background(), []Record{{"id": "b"}}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	records, err := readPersistedRecords(path, false)
+	if err != nil || len(records) != 2 {
+		t.Errorf("Expected the object to become an array of 2 records, got %v, %v", records, err)
+	}
+}
+
+func TestJSONSource_AppendTerminatesPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	if err := os.WriteFile(path, []byte(`{"id": 1}`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": path, "mode": "lines"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if err := source.Append(context.Background(), []Record{{"id": 2}}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\"id\": 1}\n{\"id\":2}\n" {
+		t.Errorf("Expected the appended record on its own line, got %q", data)
+	}
+}
+
+func TestJSONSource_AppendRejections(t *testing.T) {
+	dir := t.TempDir()
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "number"}}}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": filepath.Join(dir, "a.json")}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if err := source.Append(context.Background(), []Record{{"id": "one"}}); err == nil {
+		t.Errorf("Expected an invalid record to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file after a rejected append, got %v", err)
+	}
+
+	if err := source.Write(context.Background(), []Record{{"id": 1}}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := source.Append(context.Background(), []Record{{"id": 2}}); err == nil {
+		t.Errorf("Expected append during a Write to fail")
+	}
+	source.Close()
+
+	if err := Append(context.Background(), &staticSource{}, nil); !errors.Is(err, ErrAppendUnsupported) {
+		t.Errorf("Expected ErrAppendUnsupported, got %v", err)
+	}
+}
+
+func TestAppend_ThroughMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	cfg := map[string]interface{}{"path": path, "mode": "lines", "limit": 1}
+
+	source, err := NewDefaultFactory().CreateSource(cfg, "json", config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := Append(context.Background(), source, []Record{{"id": fmt.Sprint(i)}}); err != nil {
+			t.Fatalf("Failed to append through middleware: %v", err)
+		}
+	}
+
+	records, err := readJSONLinesFile(path)
+	if err != nil || len(records) != 2 {
+		t.Errorf("Expected 2 appended records, got %v, %v", records, err)
+	}
+}
+
+// readJSONLinesFile reads a JSON lines file without a schema
+func readJSONLinesFile(path string) ([]Record, error) {
+	source, err := NewJSONSource(map[string]interface{}{"path": path, "mode": "lines"}, config.SchemaConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return source.Read(context.Background())
+}
+
+func TestJSONSource_WriteAppendOption(t *testing.T) {
+	for _, name := range []string{"results.jsonl", "results.jsonl.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			cfg := map[string]interface{}{"path": path, "mode": "lines", "append": true}
+
+			var want []Record
+			for run := 0; run < 3; run++ {
+				// Each run writes through a fresh source, as incremental runs would
+				source, err := NewJSONSource(cfg, config.SchemaConfig{})
+				if err != nil {
+					t.Fatalf("Failed to create JSON source: %v", err)
+				}
+				for batch := 0; batch < 2; batch++ {
+					record := Record{"id": fmt.Sprintf("%d-%d", run, batch)}
+					if err := source.Write(context.Background(), []Record{record}); err != nil {
+						t.Fatalf("Failed to write run %d: %v", run, err)
+					}
+					want = append(want, record)
+				}
+				if err := source.Close(); err != nil {
+					t.Fatalf("Failed to close source: %v", err)
+				}
+			}
+
+			got, err := readJSONLinesFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read appended file: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestJSONSource_WriteAppendTerminatesPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	if err := os.WriteFile(path, []byte(`{"id": 1}`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": path, "mode": "lines", "append": true}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if err := source.Write(context.Background(), []Record{{"id": 2}}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\"id\": 1}\n{\"id\":2}\n" {
+		t.Errorf("Expected the written record on its own line, got %q", data)
+	}
+}
+
+func TestJSONSource_WriteAppendRejections(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{"array mode", map[string]interface{}{"path": "out.json", "append": true}, "only supported in lines mode"},
+		{"object mode", map[string]interface{}{"path": "out.json", "mode": "object", "append": true}, "only supported in lines mode"},
+		{"split", map[string]interface{}{"path": "out", "mode": "lines", "split": SplitPerRecord, "append": true}, "not supported with split"},
+		{"not a boolean", map[string]interface{}{"path": "out.jsonl", "mode": "lines", "append": "yes"}, "append"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJSONSource(tt.cfg, config.SchemaConfig{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
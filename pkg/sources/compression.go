@@ -0,0 +1,88 @@
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Values of the compression source option
+const (
+	CompressionAuto = "auto" // gzip for paths ending in .gz
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// isCompressed reports whether the file at name is gzip-compressed under the compression option
+func isCompressed(compression, name string) bool {
+	switch compression {
+	case CompressionGzip:
+		return true
+	case CompressionNone:
+		return false
+	default:
+		return strings.HasSuffix(strings.ToLower(name), ".gz")
+	}
+}
+
+// gzipReadCloser decompresses a file; Close closes both the gzip reader and the file
+type gzipReadCloser struct {
+	*gzip.Reader
+	file io.Closer
+}
+
+func (g gzipReadCloser) Close() error {
+	return errors.Join(g.Reader.Close(), g.file.Close())
+}
+
+// newGzipReader wraps file in a gzip reader that takes ownership of it
+func newGzipReader(file io.ReadCloser) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	return gzipReadCloser{Reader: reader, file: file}, nil
+}
+
+// gzipWriteCloser compresses writes to a file; Close flushes the gzip footer
+// before closing the file
+type gzipWriteCloser struct {
+	*gzip.Writer
+	file io.Closer
+}
+
+func (g gzipWriteCloser) Close() error {
+	return errors.Join(g.Writer.Close(), g.file.Close())
+}
+
+// newGzipWriter wraps file in a gzip writer that takes ownership of it
+func newGzipWriter(file io.WriteCloser) io.WriteCloser {
+	return gzipWriteCloser{Writer: gzip.NewWriter(file), file: file}
+}
+
+// gzipBytes compresses data into a single gzip member
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzipBytes decompresses every gzip member in data
+func gunzipBytes(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,174 @@
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// writeGzip writes content gzip-compressed to path
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write([]byte(content))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to compress test data: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+}
+
+func TestJSONSource_ReadGzip(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	want := []Record{{"text": "a"}, {"text": "b"}}
+
+	tests := []struct {
+		name string
+		file string
+		mode string
+		cfg  map[string]interface{}
+		data string
+	}{
+		{"array by suffix", "data.json.gz", "array", nil, `[{"text": "a"}, {"text": "b"}]`},
+		{"lines by suffix", "data.jsonl.gz", "lines", nil, "{\"text\": \"a\"}\n{\"text\": \"b\"}\n"},
+		{"auto by suffix", "data.jsonl.gz", "auto", nil, "{\"text\": \"a\"}\n{\"text\": \"b\"}\n"},
+		{"configured", "data.json", "array", map[string]interface{}{"compression": "gzip"}, `[{"text": "a"}, {"text": "b"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeGzip(t, path, tt.data)
+
+			cfg := map[string]interface{}{"path": path, "mode": tt.mode}
+			for k, v := range tt.cfg {
+				cfg[k] = v
+			}
+			source, err := NewJSONSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read records: %v", err)
+			}
+			if !reflect.DeepEqual(records, want) {
+				t.Errorf("Expected %v, got %v", want, records)
+			}
+		})
+	}
+}
+
+func TestJSONSource_ReadGzipErrors(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.json.gz")
+	if err := os.WriteFile(plain, []byte(`[{"text": "a"}]`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": plain}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to open gzip stream") {
+		t.Errorf("Expected an uncompressed .gz file to fail, got %v", err)
+	}
+
+	// compression: none reads .gz paths as plain JSON
+	source, err = NewJSONSource(map[string]interface{}{"path": plain, "compression": "none"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if records, err := source.Read(context.Background()); err != nil || len(records) != 1 {
+		t.Errorf("Expected the plain file to be read, got %v, %v", records, err)
+	}
+
+	if _, err := NewJSONSource(map[string]interface{}{"path": plain, "compression": "zstd"}, config.SchemaConfig{}); err == nil {
+		t.Errorf("Expected an unsupported compression to be rejected")
+	}
+}
+
+func TestJSONSource_WriteGzipRoundTrip(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	records := []Record{{"text": "a"}, {"text": "b"}, {"text": "c"}}
+
+	for _, mode := range []string{"array", "lines", "object"} {
+		t.Run(mode, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.json.gz")
+			cfg := map[string]interface{}{"path": path, "mode": mode}
+
+			writer, err := NewJSONSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+			if err := writer.Write(context.Background(), records[:2]); err != nil {
+				t.Fatalf("Failed to write records: %v", err)
+			}
+			if err := writer.Write(context.Background(), records[2:]); err != nil {
+				t.Fatalf("Failed to write records: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			data, _ := os.ReadFile(path)
+			if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+				t.Fatalf("Expected gzip output, got %q", data)
+			}
+
+			cfg["mode"] = "auto"
+			reader, err := NewJSONSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+			got, err := reader.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read back records: %v", err)
+			}
+			if !reflect.DeepEqual(got, records) {
+				t.Errorf("Expected %v, got %v", records, got)
+			}
+		})
+	}
+}
+
+func TestJSONSource_AppendGzip(t *testing.T) {
+	for _, mode := range []string{"array", "lines"} {
+		t.Run(mode, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.jsonl.gz")
+			cfg := map[string]interface{}{"path": path, "mode": mode}
+
+			for i := 0; i < 3; i++ {
+				source, err := NewJSONSource(cfg, config.SchemaConfig{})
+				if err != nil {
+					t.Fatalf("Failed to create JSON source: %v", err)
+				}
+				if err := source.Append(context.Background(), []Record{{"n": float64(i)}}); err != nil {
+					t.Fatalf("Failed to append: %v", err)
+				}
+			}
+
+			source, err := NewJSONSource(cfg, config.SchemaConfig{})
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read appended records: %v", err)
+			}
+			if want := []Record{{"n": 0.0}, {"n": 1.0}, {"n": 2.0}}; !reflect.DeepEqual(records, want) {
+				t.Errorf("Expected %v, got %v", want, records)
+			}
+		})
+	}
+}
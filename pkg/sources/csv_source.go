@@ -0,0 +1,366 @@
+package sources
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// utf8BOM is stripped from the first header cell, as spreadsheet exports often start with it
+const utf8BOM = "\ufeff"
+
+// CSVSource implements Source interface for CSV files
+type CSVSource struct {
+	path       string
+	delimiter  rune
+	headers    []string // column names for files without a header row
+	schema     config.SchemaConfig
+	files      FileOptions
+	validator  *recordValidator
+	fsync      bool   // sync the output file to disk after each Write and before closing
+	errorField string // written records with an error here may lack schema fields
+	writeEmpty bool   // Close creates a header-only output when nothing was written
+	file       *os.File
+	writer     *csv.Writer
+}
+
+// NewCSVSource creates a new CSV source
+func NewCSVSource(cfg map[string]interface{}, schema config.SchemaConfig) (*CSVSource, error) {
+	path, ok := cfg["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("path is required for CSV source")
+	}
+
+	delimiter, err := delimiterOption(cfg, "delimiter")
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := stringsOption(cfg, "headers")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHeaders(headers); err != nil {
+		return nil, fmt.Errorf("headers: %w", err)
+	}
+
+	recursive, err := boolOption(cfg, "recursive")
+	if err != nil {
+		return nil, err
+	}
+
+	sortFiles, err := choiceOption(cfg, "sort", SortByName, SortByName, SortByModTime)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := newRecordValidator(cfg, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	fsync, err := boolOption(cfg, "fsync")
+	if err != nil {
+		return nil, err
+	}
+
+	errorField, _ := cfg["error_field"].(string)
+
+	writeEmpty, err := boolOption(cfg, "write_empty")
+	if err != nil {
+		return nil, err
+	}
+
+	return &CSVSource{
+		path:       path,
+		delimiter:  delimiter,
+		headers:    headers,
+		schema:     schema,
+		files:      FileOptions{Recursive: recursive, Sort: sortFiles},
+		validator:  validator,
+		fsync:      fsync,
+		errorField: errorField,
+		writeEmpty: writeEmpty,
+	}, nil
+}
+
+// delimiterOption reads a single-character field delimiter, defaulting to a comma
+func delimiterOption(cfg map[string]interface{}, key string) (rune, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return ',', nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return 0, fmt.Errorf("%s must be a string, got %T", key, raw)
+	}
+	if value == "" {
+		return ',', nil
+	}
+
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || delimiter == utf8.RuneError {
+		return 0, fmt.Errorf("%s must be a single character, got %q", key, value)
+	}
+	if delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		return 0, fmt.Errorf("%s cannot be %q", key, value)
+	}
+	return delimiter, nil
+}
+
+// checkHeaders rejects empty and repeated column names
+func checkHeaders(headers []string) error {
+	seen := make(map[string]bool, len(headers))
+	for i, name := range headers {
+		if name == "" {
+			return fmt.Errorf("column %d has no name", i+1)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate column %s", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Read reads records from CSV files
+func (c *CSVSource) Read(ctx context.Context) ([]Record, error) {
+	fsys, pattern, root, err := osFilesystem(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	files, err := resolveFiles(fsys, pattern, c.path, c.files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files: %w", err)
+	}
+
+	var allRecords []Record
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return allRecords, err
+		}
+
+		records, err := c.readFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", filepath.Join(root, filepath.FromSlash(file)), err)
+		}
+		allRecords = append(allRecords, records...)
+	}
+
+	return allRecords, nil
+}
+
+// readFile reads records from a single CSV file in fsys
+func (c *CSVSource) readFile(fsys fs.FS, name string) ([]Record, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = c.delimiter
+
+	headers := c.headers
+	if headers == nil {
+		headers, err = reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+		headers[0] = strings.TrimPrefix(headers[0], utf8BOM)
+		if err := checkHeaders(headers); err != nil {
+			return nil, fmt.Errorf("header: %w", err)
+		}
+	}
+	// Every row must have one value per column
+	reader.FieldsPerRecord = len(headers)
+
+	types := make(map[string]string, len(c.schema.Fields))
+	for _, field := range c.schema.Fields {
+		types[field.Name] = field.Type
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(Record, len(headers))
+		for i, name := range headers {
+			record[name] = parseCell(row[i], types[name])
+		}
+
+		line, _ := reader.FieldPos(0)
+		record, err = c.validator.validate(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: validation failed: %w", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseCell converts a CSV value to the Go type validateFieldType expects for
+// fieldType. Values that do not parse stay strings, so validation reports them.
+func parseCell(value string, fieldType string) interface{} {
+	switch fieldType {
+	case "number", "integer":
+		if n, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return b
+		}
+	case "array", "object":
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			return decoded
+		}
+	}
+	return value
+}
+
+// Write writes records to a CSV file, one column per schema field in schema
+// order. A header row is written first unless headers are configured.
+func (c *CSVSource) Write(ctx context.Context, records []Record) error {
+	if c.writer == nil {
+		if len(c.schema.Fields) == 0 {
+			return fmt.Errorf("CSV output requires a schema to order its columns")
+		}
+
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+
+		file, err := os.Create(c.path)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		c.file = file
+		c.writer = csv.NewWriter(file)
+		c.writer.Comma = c.delimiter
+
+		if c.headers == nil {
+			header := make([]string, len(c.schema.Fields))
+			for i, field := range c.schema.Fields {
+				header[i] = field.Name
+			}
+			if err := c.writer.Write(header); err != nil {
+				return fmt.Errorf("failed to write header: %w", err)
+			}
+		}
+	}
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Validate record against schema
+		if err := c.validator.validateOutputFields(record, c.errorField); err != nil {
+			return fmt.Errorf("record validation failed: %w", err)
+		}
+
+		row := make([]string, len(c.schema.Fields))
+		for i, field := range c.schema.Fields {
+			value, _ := LookupField(record, field.Name)
+			cell, err := formatCell(value)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			row[i] = cell
+		}
+		if err := c.writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+	if c.fsync {
+		if err := c.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync file: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatCell renders a value as a CSV cell; arrays and objects are written as JSON
+func formatCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value: %w", err)
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
+
+// Capabilities reports the features supported by the CSV source
+func (c *CSVSource) Capabilities() Capabilities {
+	return Capabilities{Write: true}
+}
+
+// Schema returns the schema the source was configured with
+func (c *CSVSource) Schema() config.SchemaConfig {
+	return c.schema
+}
+
+// Close flushes buffered rows and closes the output file, syncing it first with
+// fsync. With write_empty, an output nothing was written to is still created
+// with its header row.
+func (c *CSVSource) Close() error {
+	if c.file == nil && c.writeEmpty {
+		if err := c.Write(context.Background(), nil); err != nil {
+			return fmt.Errorf("failed to create empty output: %w", err)
+		}
+	}
+	if c.file == nil {
+		return nil
+	}
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return errors.Join(err, c.file.Close())
+	}
+	if c.fsync {
+		return syncingFile{c.file}.Close()
+	}
+	return c.file.Close()
+}
@@ -0,0 +1,340 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// writeCSV writes content to name in a temp directory and returns its path
+func writeCSV(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+func TestCSVSource_Read(t *testing.T) {
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "score", Type: "number"},
+			{Name: "flagged", Type: "boolean"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		content string
+	}{
+		{"header row", nil, "text,score,flagged\ngreat,4.5,false\nbad,1,true\n"},
+		{"byte order mark", nil, "\ufefftext,score,flagged\r\ngreat,4.5,false\r\nbad,1,true\r\n"},
+		{"explicit headers", map[string]interface{}{"headers": []interface{}{"text", "score", "flagged"}}, "great,4.5,false\nbad,1,true"},
+		{"tab delimiter", map[string]interface{}{"delimiter": "\t"}, "text\tscore\tflagged\ngreat\t4.5\tfalse\nbad\t1\ttrue\n"},
+		{"columns in any order", nil, "flagged,text,score\nfalse,great,4.5\ntrue,bad,1\n"},
+	}
+
+	want := []Record{
+		{"text": "great", "score": 4.5, "flagged": false},
+		{"text": "bad", "score": 1.0, "flagged": true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]interface{}{"path": writeCSV(t, "data.csv", tt.content)}
+			for k, v := range tt.cfg {
+				cfg[k] = v
+			}
+
+			source, err := NewCSVSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create CSV source: %v", err)
+			}
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read records: %v", err)
+			}
+			if !reflect.DeepEqual(records, want) {
+				t.Errorf("Expected %v, got %v", want, records)
+			}
+		})
+	}
+}
+
+func TestCSVSource_ReadQuotedFields(t *testing.T) {
+	// RFC 4180: quoted fields may hold delimiters, line breaks, and doubled quotes
+	content := "id,text\n" +
+		"1,\"hello, world\"\n" +
+		"2,\"first line\nsecond line\"\n" +
+		"3,\"she said \"\"hi\"\"\"\n" +
+		"4,\"\"\n"
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}, {Name: "text", Type: "string"}}}
+
+	source, err := NewCSVSource(map[string]interface{}{"path": writeCSV(t, "quoted.csv", content)}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	want := []string{"hello, world", "first line\nsecond line", `she said "hi"`, ""}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(records))
+	}
+	for i, text := range want {
+		if records[i]["text"] != text {
+			t.Errorf("Expected record %d text %q, got %q", i, text, records[i]["text"])
+		}
+	}
+}
+
+func TestCSVSource_ReadErrors(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}, {Name: "score", Type: "number"}}}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"missing column", "text\nhi\n", "missing required field: score"},
+		{"wrong type", "text,score\nhi,1\nthere,high\n", "line 3: validation failed: field score: expected number"},
+		{"short row", "text,score\nhi\n", "wrong number of fields"},
+		{"duplicate header", "text,text,score\na,b,1\n", "header: duplicate column text"},
+		{"unterminated quote", "text,score\n\"hi,1\n", "extraneous or missing \" in quoted-field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewCSVSource(map[string]interface{}{"path": writeCSV(t, "data.csv", tt.content)}, schema)
+			if err != nil {
+				t.Fatalf("Failed to create CSV source: %v", err)
+			}
+			_, err = source.Read(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCSVSource_ReadIntegers(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}, {Name: "label_id", Type: "integer"}}}
+
+	source, err := NewCSVSource(map[string]interface{}{"path": writeCSV(t, "data.csv", "text,label_id\nhi,3\n")}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 1 || records[0]["label_id"] != 3.0 {
+		t.Errorf("Expected label_id parsed to 3, got %v", records)
+	}
+
+	source, err = NewCSVSource(map[string]interface{}{"path": writeCSV(t, "data.csv", "text,label_id\nhi,3.14\n")}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "field label_id: expected integer, got 3.14") {
+		t.Errorf("Expected a fractional label_id to be rejected, got %v", err)
+	}
+}
+
+func TestNewCSVSource_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{"missing path", map[string]interface{}{}, "path is required"},
+		{"long delimiter", map[string]interface{}{"path": "x.csv", "delimiter": ";;"}, "single character"},
+		{"quote delimiter", map[string]interface{}{"path": "x.csv", "delimiter": `"`}, "cannot be"},
+		{"empty header", map[string]interface{}{"path": "x.csv", "headers": []interface{}{"a", ""}}, "column 2 has no name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCSVSource(tt.cfg, config.SchemaConfig{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCSVSource_WriteRoundTrip(t *testing.T) {
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "score", Type: "number"},
+			{Name: "tags", Type: "array"},
+		},
+	}
+	records := []Record{
+		{"text": "quoted \"value\", with comma", "score": 0.25, "tags": []interface{}{"a", "b"}, "extra": "dropped"},
+		{"text": "multi\nline", "score": 3.0, "tags": []interface{}{}},
+	}
+
+	for _, delimiter := range []string{",", "\t"} {
+		path := filepath.Join(t.TempDir(), "out", "results.csv")
+		cfg := map[string]interface{}{"path": path, "delimiter": delimiter}
+
+		writer, err := NewCSVSource(cfg, schema)
+		if err != nil {
+			t.Fatalf("Failed to create CSV source: %v", err)
+		}
+		for _, record := range records {
+			if err := writer.Write(context.Background(), []Record{record}); err != nil {
+				t.Fatalf("Failed to write records: %v", err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Failed to close source: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		if header := strings.SplitN(string(data), "\n", 2)[0]; header != strings.Join([]string{"text", "score", "tags"}, delimiter) {
+			t.Errorf("Expected a header row in schema order, got %q", header)
+		}
+
+		reader, err := NewCSVSource(cfg, schema)
+		if err != nil {
+			t.Fatalf("Failed to create CSV source: %v", err)
+		}
+		got, err := reader.Read(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to read back records: %v", err)
+		}
+		want := []Record{
+			{"text": records[0]["text"], "score": 0.25, "tags": []interface{}{"a", "b"}},
+			{"text": "multi\nline", "score": 3.0, "tags": []interface{}{}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v with delimiter %q, got %v", want, delimiter, got)
+		}
+	}
+}
+
+func TestCSVSource_WriteWithoutHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.csv")
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}, {Name: "ok", Type: "boolean"}}}
+
+	source, err := NewCSVSource(map[string]interface{}{"path": path, "headers": []interface{}{"text", "ok"}}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	if err := source.Write(context.Background(), []Record{{"text": "hi", "ok": true}}); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "hi,true\n" {
+		t.Errorf("Expected only the data row with headers configured, got %q", data)
+	}
+}
+
+func TestCSVSource_WriteEmpty(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}, {Name: "ok", Type: "boolean"}}}
+
+	tests := []struct {
+		name string
+		cfg  map[string]interface{}
+		want string
+	}{
+		{"header", map[string]interface{}{"write_empty": true}, "text,ok\n"},
+		{"configured headers", map[string]interface{}{"write_empty": true, "headers": []interface{}{"text", "ok"}}, ""},
+		{"tab delimited", map[string]interface{}{"write_empty": true, "delimiter": "\t"}, "text\tok\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", "results.csv")
+			tt.cfg["path"] = path
+			source, err := NewCSVSource(tt.cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create CSV source: %v", err)
+			}
+			if err := source.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Expected an empty output file, got %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, data)
+			}
+		})
+	}
+
+	// Without write_empty, closing an unwritten source creates nothing
+	path := filepath.Join(t.TempDir(), "results.csv")
+	source, err := NewCSVSource(map[string]interface{}{"path": path}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file, got %v", err)
+	}
+}
+
+func TestCSVSource_WriteDottedFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.csv")
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}, {Name: "meta.lang", Type: "string"}}}
+
+	source, err := NewCSVSource(map[string]interface{}{"path": path}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	records := []Record{{"text": "hi", "meta": map[string]interface{}{"lang": "en"}}}
+	if err := source.Write(context.Background(), records); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "text,meta.lang\nhi,en\n" {
+		t.Errorf("Expected the nested value in the meta.lang column, got %q", data)
+	}
+}
+
+func TestDefaultFactory_CSV(t *testing.T) {
+	path := writeCSV(t, "data.csv", "text\nhello\n")
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+
+	source, err := NewDefaultFactory().CreateSource(map[string]interface{}{"path": path}, "csv", schema)
+	if err != nil {
+		t.Fatalf("Failed to create CSV source: %v", err)
+	}
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 1 || records[0]["text"] != "hello" {
+		t.Errorf("Expected one record, got %v", records)
+	}
+}
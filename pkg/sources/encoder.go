@@ -0,0 +1,134 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultEncoder is the encoding/json encoder JSON sources write with unless
+// their encoder option names another
+const DefaultEncoder = "std"
+
+// Encoder writes JSON values to an output, like json.Encoder. Implementations
+// must produce the same bytes as encoding/json: object keys sorted, <, >, and &
+// escaped, the indentation set with SetIndent, and a newline after each value.
+type Encoder interface {
+	Encode(v interface{}) error
+	SetIndent(prefix, indent string)
+}
+
+// EncoderFunc creates an Encoder writing to w
+type EncoderFunc func(w io.Writer) Encoder
+
+// defaultEncoder encodes with encoding/json
+func defaultEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFunc{DefaultEncoder: defaultEncoder}
+)
+
+// RegisterEncoder makes a JSON encoder, such as a faster third-party library,
+// available to JSON sources as encoder: name. It is typically called from an
+// init function, optionally in a file behind a build tag. Like
+// database/sql.Register, it panics if name is empty or already registered, fn
+// is nil, or the encoder's output differs from encoding/json (see VerifyEncoder).
+func RegisterEncoder(name string, fn EncoderFunc) {
+	if name == "" {
+		panic("sources: RegisterEncoder name is empty")
+	}
+	if fn == nil {
+		panic("sources: RegisterEncoder encoder is nil for " + name)
+	}
+	if err := VerifyEncoder(fn); err != nil {
+		panic(fmt.Sprintf("sources: RegisterEncoder %s: %v", name, err))
+	}
+
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, dup := encoders[name]; dup {
+		panic(fmt.Sprintf("sources: RegisterEncoder called twice for %s", name))
+	}
+	encoders[name] = fn
+}
+
+// LookupEncoder returns the encoder registered under name
+func LookupEncoder(name string) (EncoderFunc, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	fn, ok := encoders[name]
+	return fn, ok
+}
+
+// Encoders returns the names of the registered encoders, sorted
+func Encoders() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// encoderOption reads the name of a registered encoder, defaulting to DefaultEncoder
+func encoderOption(cfg map[string]interface{}, key string) (EncoderFunc, error) {
+	name := DefaultEncoder
+	if raw, exists := cfg[key]; exists && raw != nil {
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string, got %T", key, raw)
+		}
+		name = s
+	}
+
+	fn, ok := LookupEncoder(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown %s %s (registered: %s)", key, name, strings.Join(Encoders(), ", "))
+	}
+	return fn, nil
+}
+
+// encoderSamples exercise the behaviour output files depend on: key order,
+// escaping, number formatting, nesting, and empty values
+var encoderSamples = []interface{}{
+	Record{"zeta": 1.0, "alpha": "a", "mid": true, "none": nil},
+	Record{"html": "<b>tom & jerry</b>", "quote": `say "hi"\n`, "control": "tab\there\u0001", "unicode": "caf\u00e9\u2028\u2029\U0001F600", "invalid": "\xff"},
+	Record{"int": 42, "float": 0.1, "large": 1e21, "small": 1e-7, "negative": -3.5, "zero": 0.0},
+	Record{"nested": map[string]interface{}{"b": []interface{}{1.0, "two", nil}, "a": map[string]interface{}{}}, "empty": []interface{}{}},
+	[]Record{{"id": "1"}, {"id": "2"}},
+	[]Record{},
+}
+
+// VerifyEncoder checks that fn encodes a set of sample records to the same
+// bytes as encoding/json, compact and indented, returning the first difference
+func VerifyEncoder(fn EncoderFunc) error {
+	for _, indent := range []string{"", "  "} {
+		for i, sample := range encoderSamples {
+			var want, got bytes.Buffer
+			stdEncoder := json.NewEncoder(&want)
+			stdEncoder.SetIndent("", indent)
+			if err := stdEncoder.Encode(sample); err != nil {
+				return fmt.Errorf("sample %d: %w", i, err)
+			}
+
+			encoder := fn(&got)
+			encoder.SetIndent("", indent)
+			if err := encoder.Encode(sample); err != nil {
+				return fmt.Errorf("sample %d: failed to encode: %w", i, err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				return fmt.Errorf("sample %d: output differs from encoding/json: got %q, want %q", i, got.String(), want.String())
+			}
+		}
+	}
+	return nil
+}
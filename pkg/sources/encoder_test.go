@@ -0,0 +1,164 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// bufferedEncoder is a stand-in for a third-party encoder: it renders each
+// value with json.Marshal and writes it in one call
+type bufferedEncoder struct {
+	w              io.Writer
+	prefix, indent string
+}
+
+func (b *bufferedEncoder) SetIndent(prefix, indent string) {
+	b.prefix, b.indent = prefix, indent
+}
+
+func (b *bufferedEncoder) Encode(v interface{}) error {
+	var data []byte
+	var err error
+	if b.prefix == "" && b.indent == "" {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, b.prefix, b.indent)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = b.w.Write(append(data, '\n'))
+	return err
+}
+
+func init() {
+	RegisterEncoder("test-buffered", func(w io.Writer) Encoder { return &bufferedEncoder{w: w} })
+}
+
+func TestVerifyEncoder(t *testing.T) {
+	if err := VerifyEncoder(defaultEncoder); err != nil {
+		t.Errorf("Expected encoding/json to verify, got %v", err)
+	}
+
+	unescaped := func(w io.Writer) Encoder {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return encoder
+	}
+	if err := VerifyEncoder(unescaped); err == nil || !strings.Contains(err.Error(), "differs from encoding/json") {
+		t.Errorf("Expected an encoder without HTML escaping to fail verification, got %v", err)
+	}
+}
+
+func TestRegisterEncoder_Panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoder  string
+		fn       EncoderFunc
+		contains string
+	}{
+		{"empty name", "", defaultEncoder, "name is empty"},
+		{"nil encoder", "nil", nil, "encoder is nil"},
+		{"duplicate", DefaultEncoder, defaultEncoder, "called twice"},
+		{"different output", "doubled", func(w io.Writer) Encoder {
+			return &bufferedEncoder{w: io.MultiWriter(w, w)}
+		}, "differs from encoding/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil || !strings.Contains(fmt.Sprint(r), tt.contains) {
+					t.Errorf("Expected panic containing %q, got %v", tt.contains, r)
+				}
+			}()
+			RegisterEncoder(tt.encoder, tt.fn)
+		})
+	}
+}
+
+func TestJSONSource_EncoderOutputMatches(t *testing.T) {
+	records := []Record{
+		{"text": "<great> & \"fast\"", "score": 0.1, "tags": []interface{}{"a", nil}},
+		{"text": "café", "score": 1e21, "meta": map[string]interface{}{"z": true, "a": 1.0}},
+	}
+
+	for _, mode := range []string{"array", "lines", "object"} {
+		t.Run(mode, func(t *testing.T) {
+			outputs := make(map[string]string)
+			for _, encoder := range []string{DefaultEncoder, "test-buffered"} {
+				path := filepath.Join(t.TempDir(), "output.json")
+				source, err := NewJSONSource(map[string]interface{}{"path": path, "mode": mode, "encoder": encoder}, config.SchemaConfig{})
+				if err != nil {
+					t.Fatalf("Failed to create source: %v", err)
+				}
+				if err := source.Write(context.Background(), records); err != nil {
+					t.Fatalf("Failed to write: %v", err)
+				}
+				if err := source.Close(); err != nil {
+					t.Fatalf("Failed to close: %v", err)
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("Failed to read output: %v", err)
+				}
+				outputs[encoder] = string(data)
+			}
+
+			if outputs[DefaultEncoder] != outputs["test-buffered"] {
+				t.Errorf("Expected identical output, got %q and %q", outputs[DefaultEncoder], outputs["test-buffered"])
+			}
+		})
+	}
+}
+
+func TestJSONSource_UnknownEncoder(t *testing.T) {
+	_, err := NewJSONSource(map[string]interface{}{"path": "out.json", "encoder": "simd"}, config.SchemaConfig{})
+	if err == nil || !strings.Contains(err.Error(), "unknown encoder simd") {
+		t.Errorf("Expected unknown encoder error, got %v", err)
+	}
+}
+
+func BenchmarkEncoders(b *testing.B) {
+	records := make([]Record, 10000)
+	for i := range records {
+		records[i] = Record{
+			"id":     fmt.Sprintf("record-%d", i),
+			"text":   fmt.Sprintf("This is review number %d, which was <fine> & fast", i),
+			"score":  float64(i) / 7,
+			"labels": []interface{}{"positive", "short"},
+			"meta":   map[string]interface{}{"source": "benchmark", "index": float64(i)},
+		}
+	}
+
+	for _, name := range Encoders() {
+		fn, _ := LookupEncoder(name)
+		for _, indent := range []string{"", "  "} {
+			label := name + "/compact"
+			if indent != "" {
+				label = name + "/indented"
+			}
+			b.Run(label, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					encoder := fn(io.Discard)
+					encoder.SetIndent("", indent)
+					for _, record := range records {
+						if err := encoder.Encode(record); err != nil {
+							b.Fatalf("Failed to encode: %v", err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -14,16 +14,36 @@ func NewDefaultFactory() *DefaultFactory {
 	return &DefaultFactory{}
 }
 
-// CreateSource creates a source based on format and configuration
+// CreateSource creates a source based on format and configuration, wrapped in
+// the middlewares enabled by the config
 func (f *DefaultFactory) CreateSource(cfg map[string]interface{}, format string, schema config.SchemaConfig) (Source, error) {
+	middlewares, err := MiddlewareFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := f.createFormat(cfg, format, schema)
+	if err != nil {
+		return nil, err
+	}
+	return Chain(src, middlewares...), nil
+}
+
+func (f *DefaultFactory) createFormat(cfg map[string]interface{}, format string, schema config.SchemaConfig) (Source, error) {
 	switch format {
 	case "json":
+		switch path, _ := cfg["path"].(string); {
+		case IsS3Path(path):
+			return NewS3Source(cfg, schema)
+		case IsHTTPPath(path):
+			return NewHTTPSource(cfg, schema)
+		}
 		return NewJSONSource(cfg, schema)
 	case "csv":
-		return nil, fmt.Errorf("CSV source not yet implemented")
+		return NewCSVSource(cfg, schema)
 	case "parquet":
 		return nil, fmt.Errorf("Parquet source not yet implemented")
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
-}
\ No newline at end of file
+}
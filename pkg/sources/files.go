@@ -0,0 +1,298 @@
+package sources
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sort orders for FileOptions.Sort
+const (
+	SortByName    = "name"  // lexical path order
+	SortByModTime = "mtime" // oldest first, ties broken by name
+)
+
+// Policies for FileOptions.OnMissing
+const (
+	MissingFail = "fail"
+	MissingSkip = "skip"
+)
+
+// FileOptions controls how a path pattern is resolved to files
+type FileOptions struct {
+	Recursive bool   // include every file beneath matched directories
+	Sort      string // SortByName (default) or SortByModTime
+	OnMissing string // MissingFail (default) errors when nothing matches; MissingSkip returns no files
+}
+
+// FileError is a file skipped because it failed to read, e.g. with continue_on_file_error
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// joinFileErrors combines per-file errors into one, one file per line
+func joinFileErrors(failed []FileError) error {
+	errs := make([]error, len(failed))
+	for i, f := range failed {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
+
+// ResolveFiles expands a path pattern on the OS filesystem into the files it
+// matches. Directories are skipped unless opts.Recursive is set.
+func ResolveFiles(pattern string, opts FileOptions) ([]string, error) {
+	fsys, rel, root, err := osFilesystem(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	names, err := resolveFiles(fsys, rel, pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(root, filepath.FromSlash(name))
+	}
+	return paths, nil
+}
+
+// ResolveFilesFS is ResolveFiles over fsys; the pattern and the returned names are fs.FS paths
+func ResolveFilesFS(fsys fs.FS, pattern string, opts FileOptions) ([]string, error) {
+	return resolveFiles(fsys, pattern, pattern, opts)
+}
+
+// HasWildcard reports whether a path pattern contains glob metacharacters
+func HasWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// osFilesystem returns the OS filesystem rooted at the volume of path's
+// absolute form, the path relative to it, and the root
+func osFilesystem(path string) (fs.FS, string, string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	root := filepath.VolumeName(abs) + string(filepath.Separator)
+	rel := filepath.ToSlash(strings.TrimPrefix(abs, root))
+	if rel == "" {
+		rel = "."
+	}
+	return os.DirFS(root), rel, root, nil
+}
+
+// resolveFiles matches pattern in fsys; display is the pattern as the user wrote it, for errors
+func resolveFiles(fsys fs.FS, pattern, display string, opts FileOptions) ([]string, error) {
+	sortBy := opts.Sort
+	if sortBy == "" {
+		sortBy = SortByName
+	}
+	if sortBy != SortByName && sortBy != SortByModTime {
+		return nil, fmt.Errorf("unsupported file sort %s (must be %s or %s)", sortBy, SortByName, SortByModTime)
+	}
+	onMissing := opts.OnMissing
+	if onMissing == "" {
+		onMissing = MissingFail
+	}
+	if onMissing != MissingFail && onMissing != MissingSkip {
+		return nil, fmt.Errorf("unsupported on_missing policy %s (must be %s or %s)", onMissing, MissingFail, MissingSkip)
+	}
+
+	// A literal path reports why it can't be read rather than that nothing matched it
+	if !HasWildcard(pattern) {
+		if _, err := fs.Stat(fsys, pattern); err != nil {
+			if onMissing == MissingSkip && errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			var pathErr *fs.PathError
+			if errors.As(err, &pathErr) {
+				return nil, &fs.PathError{Op: pathErr.Op, Path: display, Err: pathErr.Err}
+			}
+			return nil, err
+		}
+	}
+
+	matches, err := glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	modTimes := make(map[string]time.Time)
+	add := func(name string, info fs.FileInfo) {
+		if _, seen := modTimes[name]; !seen {
+			modTimes[name] = info.ModTime()
+		}
+	}
+
+	for _, match := range matches {
+		info, err := fs.Stat(fsys, match)
+		if err != nil {
+			// The match disappeared since globbing
+			continue
+		}
+		if !info.IsDir() {
+			add(match, info)
+			continue
+		}
+		if !opts.Recursive {
+			continue
+		}
+		err = fs.WalkDir(fsys, match, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				add(name, info)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(modTimes) == 0 {
+		if onMissing == MissingSkip {
+			return nil, nil
+		}
+		if !HasWildcard(display) {
+			return nil, fmt.Errorf("no files found at %s: %w", display, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("no files found matching pattern: %s", display)
+	}
+
+	files := make([]string, 0, len(modTimes))
+	for name := range modTimes {
+		files = append(files, name)
+	}
+	sort.Slice(files, func(i, k int) bool {
+		if sortBy == SortByModTime && !modTimes[files[i]].Equal(modTimes[files[k]]) {
+			return modTimes[files[i]].Before(modTimes[files[k]])
+		}
+		return files[i] < files[k]
+	})
+	return files, nil
+}
+
+// glob is fs.Glob with support for ** segments, which match any number of
+// directories, including none: data/**/predictions.json matches
+// data/predictions.json and data/2024-01-01/predictions.json. Patterns
+// without a ** segment are matched by fs.Glob unchanged.
+func glob(fsys fs.FS, pattern string) ([]string, error) {
+	segments := strings.Split(pattern, "/")
+	first := -1
+	for i, segment := range segments {
+		if segment == "**" {
+			if first < 0 {
+				first = i
+			}
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, err
+		}
+	}
+	if first < 0 {
+		return fs.Glob(fsys, pattern)
+	}
+
+	// Walk each directory matched by the segments before the first **
+	bases := []string{"."}
+	if first > 0 {
+		var err error
+		if bases, err = fs.Glob(fsys, path.Join(segments[:first]...)); err != nil {
+			return nil, err
+		}
+	}
+
+	rest := segments[first:]
+	var matches []string
+	for _, base := range bases {
+		err := fs.WalkDir(fsys, base, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable directories are skipped, as fs.Glob skips them
+				return nil
+			}
+			var relSegments []string
+			switch {
+			case name == base:
+			case base == ".":
+				relSegments = strings.Split(name, "/")
+			default:
+				relSegments = strings.Split(strings.TrimPrefix(name, base+"/"), "/")
+			}
+			if matchSegments(rest, relSegments) {
+				matches = append(matches, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// matchSegments reports whether the path segments of name match the pattern
+// segments, where ** matches zero or more segments. The patterns have been
+// checked with path.Match already.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// remoteFileInfo describes a file of a remote filesystem, such as an S3 object
+// or a downloaded document, or a directory formed by the keys beneath a prefix
+type remoteFileInfo struct {
+	name    string
+	size    int64 // -1 when unknown
+	modTime time.Time
+	dir     bool
+}
+
+func (i remoteFileInfo) Name() string       { return i.name }
+func (i remoteFileInfo) Size() int64        { return i.size }
+func (i remoteFileInfo) ModTime() time.Time { return i.modTime }
+func (i remoteFileInfo) IsDir() bool        { return i.dir }
+func (i remoteFileInfo) Sys() interface{}   { return nil }
+
+func (i remoteFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
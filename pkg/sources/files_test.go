@@ -0,0 +1,201 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func newFilesTestFS() fstest.MapFS {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return fstest.MapFS{
+		"data/b.json":        {Data: []byte(`[]`), ModTime: base.Add(1 * time.Hour)},
+		"data/a.json":        {Data: []byte(`[]`), ModTime: base.Add(2 * time.Hour)},
+		"data/c.csv":         {Data: []byte(`x`), ModTime: base},
+		"data/nested/d.json": {Data: []byte(`[]`), ModTime: base.Add(3 * time.Hour)},
+	}
+}
+
+func TestResolveFilesFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		opts    FileOptions
+		want    []string
+	}{
+		{"wildcard sorted by name", "data/*.json", FileOptions{}, []string{"data/a.json", "data/b.json"}},
+		{"directories skipped", "data/*", FileOptions{}, []string{"data/a.json", "data/b.json", "data/c.csv"}},
+		{"recursive", "data", FileOptions{Recursive: true}, []string{"data/a.json", "data/b.json", "data/c.csv", "data/nested/d.json"}},
+		{"sorted by mtime", "data/*", FileOptions{Sort: SortByModTime}, []string{"data/c.csv", "data/b.json", "data/a.json"}},
+		{"literal", "data/c.csv", FileOptions{}, []string{"data/c.csv"}},
+		{"skip missing", "data/*.parquet", FileOptions{OnMissing: MissingSkip}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveFilesFS(newFilesTestFS(), tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to resolve files: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveFilesFS_Errors(t *testing.T) {
+	fsys := newFilesTestFS()
+
+	if _, err := ResolveFilesFS(fsys, "data/*.parquet", FileOptions{}); err == nil {
+		t.Error("Expected error for a pattern matching nothing, got nil")
+	}
+
+	_, err := ResolveFilesFS(fsys, "data/missing.json", FileOptions{})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist for a missing literal path, got %v", err)
+	}
+
+	if _, err := ResolveFilesFS(fsys, "data", FileOptions{}); err == nil {
+		t.Error("Expected error for a directory without recursive, got nil")
+	}
+
+	if _, err := ResolveFilesFS(fsys, "data/*", FileOptions{Sort: "size"}); err == nil {
+		t.Error("Expected error for an unsupported sort, got nil")
+	}
+}
+
+func TestResolveFiles_OSPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2.json", "1.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`[]`), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	got, err := ResolveFiles(filepath.Join(dir, "*.json"), FileOptions{})
+	if err != nil {
+		t.Fatalf("Failed to resolve files: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "1.json"), filepath.Join(dir, "2.json")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestJSONSource_Recursive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/a.json":        {Data: []byte(`[{"text": "a"}]`)},
+		"data/nested/b.json": {Data: []byte(`[{"text": "b"}]`)},
+	}
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+
+	source, err := NewJSONSourceFromFS(fsys, "data", map[string]interface{}{"recursive": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(records) != 2 || records[0]["text"] != "a" || records[1]["text"] != "b" {
+		t.Errorf("Expected records from every file under data, got %v", records)
+	}
+}
+
+func TestResolveFilesFS_DoubleStar(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/predictions.json":                       {Data: []byte(`[]`)},
+		"data/2024-01-01/predictions.json":            {Data: []byte(`[]`)},
+		"data/2024-01-02/predictions.json":            {Data: []byte(`[]`)},
+		"data/2024-01-02/labels.json":                 {Data: []byte(`[]`)},
+		"data/2024-01-03/run/predictions.json":        {Data: []byte(`[]`)},
+		"data/2024-01-03/run/predictions.json.bak":    {Data: []byte(`x`)},
+		"data/predictions.json.d/predictions.json":    {Data: []byte(`[]`)},
+		"other/2024-01-01/predictions.json":           {Data: []byte(`[]`)},
+		"data/.hidden/predictions.json":               {Data: []byte(`[]`)},
+		"archive/2023/q4/data/final/predictions.json": {Data: []byte(`[]`)},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"any depth", "data/**/predictions.json", []string{
+			"data/.hidden/predictions.json",
+			"data/2024-01-01/predictions.json",
+			"data/2024-01-02/predictions.json",
+			"data/2024-01-03/run/predictions.json",
+			"data/predictions.json",
+			"data/predictions.json.d/predictions.json",
+		}},
+		{"wildcard after", "data/**/*-02/*.json", []string{"data/2024-01-02/labels.json", "data/2024-01-02/predictions.json"}},
+		{"wildcard before", "*/2024-01-01/**/*.json", []string{"data/2024-01-01/predictions.json", "other/2024-01-01/predictions.json"}},
+		{"leading", "**/final/predictions.json", []string{"archive/2023/q4/data/final/predictions.json"}},
+		{"two double stars", "archive/**/data/**/predictions.json", []string{"archive/2023/q4/data/final/predictions.json"}},
+		{"trailing matches files only", "data/2024-01-03/**", []string{"data/2024-01-03/run/predictions.json", "data/2024-01-03/run/predictions.json.bak"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveFilesFS(fsys, tt.pattern, FileOptions{})
+			if err != nil {
+				t.Fatalf("Failed to resolve files: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ResolveFilesFS(fsys, "data/**/[.json", FileOptions{}); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("Expected path.ErrBadPattern, got %v", err)
+	}
+}
+
+func TestResolveFiles_DoubleStarOSPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"predictions.json", "2024-01-01/predictions.json", "2024-01-02/nested/predictions.json", "2024-01-02/other.json"} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(`[]`), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	got, err := ResolveFiles(filepath.Join(dir, "**", "predictions.json"), FileOptions{})
+	if err != nil {
+		t.Fatalf("Failed to resolve files: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "2024-01-01", "predictions.json"),
+		filepath.Join(dir, "2024-01-02", "nested", "predictions.json"),
+		filepath.Join(dir, "predictions.json"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Single-level wildcards still match one directory only
+	got, err = ResolveFiles(filepath.Join(dir, "*", "predictions.json"), FileOptions{})
+	if err != nil {
+		t.Fatalf("Failed to resolve files: %v", err)
+	}
+	if want := []string{filepath.Join(dir, "2024-01-01", "predictions.json")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,65 @@
+package sources
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultFlattenSeparator joins nested keys into column names when no separator is configured
+const DefaultFlattenSeparator = "."
+
+// FlattenRecord collapses nested maps into single-level keys joined by sep,
+// e.g. {"usage": {"totalTokens": 5}} becomes {"usage.totalTokens": 5}
+func FlattenRecord(record Record, sep string) Record {
+	flat := make(Record, len(record))
+	for key, value := range record {
+		flattenValue(flat, key, value, sep)
+	}
+	return flat
+}
+
+func flattenValue(flat Record, key string, value interface{}, sep string) {
+	nested, ok := value.(map[string]interface{})
+	if !ok || len(nested) == 0 {
+		flat[key] = value
+		return
+	}
+	for k, v := range nested {
+		flattenValue(flat, key+sep+k, v, sep)
+	}
+}
+
+// UnflattenRecord expands keys joined by sep back into nested maps.
+// Keys in keep are left as-is so declared field names that contain sep survive.
+func UnflattenRecord(record Record, sep string, keep map[string]bool) Record {
+	nested := make(Record, len(record))
+
+	// Process keys in order so conflicts resolve deterministically
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := record[key]
+		if keep[key] || !strings.Contains(key, sep) {
+			nested[key] = value
+			continue
+		}
+
+		parts := strings.Split(key, sep)
+		current := map[string]interface{}(nested)
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := current[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				current[part] = child
+			}
+			current = child
+		}
+		current[parts[len(parts)-1]] = value
+	}
+
+	return nested
+}
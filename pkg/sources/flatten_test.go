@@ -0,0 +1,94 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestFlattenRecord(t *testing.T) {
+	record := Record{
+		"label": "positive",
+		"usage": map[string]interface{}{
+			"totalTokens": 12.0,
+			"details":     map[string]interface{}{"cached": 2.0},
+		},
+	}
+
+	flat := FlattenRecord(record, "_")
+
+	if flat["usage_totalTokens"] != 12.0 || flat["usage_details_cached"] != 2.0 {
+		t.Errorf("Expected nested keys to be flattened, got %v", flat)
+	}
+
+	nested := UnflattenRecord(flat, "_", nil)
+	usage, ok := nested["usage"].(map[string]interface{})
+	if !ok || usage["totalTokens"] != 12.0 {
+		t.Errorf("Expected usage to be unflattened, got %v", nested)
+	}
+}
+
+func TestJSONSource_FlattenRoundTrip(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "output.jsonl")
+
+	cfg := map[string]interface{}{
+		"path":              testFile,
+		"mode":              "lines",
+		"flatten":           true,
+		"flatten_separator": "_",
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "predicted_sentiment", Type: "string"},
+			{Name: "usage", Type: "object"},
+		},
+	}
+
+	writer, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	record := Record{
+		"predicted_sentiment": "positive",
+		"usage":               map[string]interface{}{"totalTokens": 12.0},
+	}
+	if err := writer.Write(context.Background(), []Record{record}); err != nil {
+		t.Fatalf("Failed to write record: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if !strings.Contains(string(data), `"usage_totalTokens"`) {
+		t.Errorf("Expected flattened column in output, got %s", data)
+	}
+
+	reader, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := reader.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	if records[0]["predicted_sentiment"] != "positive" {
+		t.Errorf("Expected declared field with separator to be kept, got %v", records[0])
+	}
+
+	usage, ok := records[0]["usage"].(map[string]interface{})
+	if !ok || usage["totalTokens"] != 12.0 {
+		t.Errorf("Expected usage to round-trip as a nested object, got %v", records[0]["usage"])
+	}
+}
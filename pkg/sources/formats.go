@@ -0,0 +1,74 @@
+package sources
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+)
+
+// validateEmail checks that a value is a bare email address such as user@example.com
+func validateEmail(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected email string, got %T", value)
+	}
+
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return fmt.Errorf("invalid email %q: %v", s, err)
+	}
+	if addr.Address != s {
+		return fmt.Errorf("invalid email %q: must be a bare address without a display name", s)
+	}
+	return nil
+}
+
+// validateURL checks that a value is an absolute URL with a scheme and host
+func validateURL(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected url string, got %T", value)
+	}
+
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", s, err)
+	}
+	if parsed.Scheme == "" {
+		return fmt.Errorf("invalid url %q: missing scheme", s)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid url %q: missing host", s)
+	}
+	return nil
+}
+
+// validateUUID checks that a value is a hyphenated UUID such as 123e4567-e89b-12d3-a456-426614174000
+func validateUUID(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected uuid string, got %T", value)
+	}
+
+	if len(s) != 36 {
+		return fmt.Errorf("invalid uuid %q: expected 36 characters, got %d", s, len(s))
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return fmt.Errorf("invalid uuid %q: expected '-' at position %d", s, i)
+			}
+		default:
+			if !isHexDigit(c) {
+				return fmt.Errorf("invalid uuid %q: invalid hex character %q at position %d", s, c, i)
+			}
+		}
+	}
+	return nil
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,46 @@
+package sources
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFieldType_Formats(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		fieldType string
+		wantErr   string
+	}{
+		{"valid email", "user@example.com", "email", ""},
+		{"valid email subdomain", "first.last+tag@mail.example.co.uk", "email", ""},
+		{"email missing at", "user.example.com", "email", "invalid email"},
+		{"email display name", "User <user@example.com>", "email", "without a display name"},
+		{"email not string", 42, "email", "expected email string"},
+		{"valid url", "https://example.com/path?q=1", "url", ""},
+		{"url missing scheme", "example.com/path", "url", "missing scheme"},
+		{"url missing host", "file:///tmp/data.json", "url", "missing host"},
+		{"url not string", true, "url", "expected url string"},
+		{"valid uuid", "123e4567-e89b-12d3-a456-426614174000", "uuid", ""},
+		{"valid uuid uppercase", "123E4567-E89B-12D3-A456-426614174000", "uuid", ""},
+		{"uuid too short", "123e4567-e89b-12d3-a456", "uuid", "expected 36 characters, got 23"},
+		{"uuid misplaced hyphen", "123e4567e-89b-12d3-a456-426614174000", "uuid", "expected '-' at position 8"},
+		{"uuid bad hex", "123e4567-e89b-12d3-a456-42661417400g", "uuid", "invalid hex character 'g' at position 35"},
+		{"uuid not string", 1.5, "uuid", "expected uuid string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFieldType(tt.value, tt.fieldType)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected %v to be a valid %s, got %v", tt.value, tt.fieldType, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package sources
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// syncFile is an output file that can commit written data to stable storage, such as *os.File
+type syncFile interface {
+	io.WriteCloser
+	Sync() error
+}
+
+// syncingFile commits the file it wraps to stable storage before closing it
+type syncingFile struct {
+	syncFile
+}
+
+func (s syncingFile) Close() error {
+	if err := s.Sync(); err != nil {
+		s.syncFile.Close()
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	return s.syncFile.Close()
+}
+
+// writeFile writes data to path like os.WriteFile, syncing it to disk before
+// closing it when sync is set
+func writeFile(path string, data []byte, sync bool) error {
+	if !sync {
+		return os.WriteFile(path, data, 0644)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	return writeAndClose(syncingFile{file}, data)
+}
+
+// writeAndClose writes data to file in one call and closes it, reporting the first error
+func writeAndClose(file io.WriteCloser, data []byte) error {
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,198 @@
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// fakeSyncFile records writes, syncs, and closes, in order
+type fakeSyncFile struct {
+	bytes.Buffer
+	events  []string
+	synced  []int // buffer length at each sync
+	syncErr error
+}
+
+func (f *fakeSyncFile) Sync() error {
+	f.events = append(f.events, "sync")
+	f.synced = append(f.synced, f.Len())
+	return f.syncErr
+}
+
+func (f *fakeSyncFile) Close() error {
+	f.events = append(f.events, "close")
+	return nil
+}
+
+// plainFile is an output that cannot be synced, like an S3 upload
+type plainFile struct {
+	bytes.Buffer
+}
+
+func (p *plainFile) Close() error {
+	return nil
+}
+
+// newSyncTestSource returns a JSON source writing to output instead of a local file
+func newSyncTestSource(t *testing.T, cfg map[string]interface{}, output io.WriteCloser) *JSONSource {
+	t.Helper()
+
+	source, err := NewJSONSource(cfg, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	source.create = func(ctx context.Context) (io.WriteCloser, error) {
+		return output, nil
+	}
+	return source
+}
+
+func TestJSONSource_Fsync(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsync bool
+		want  []string
+	}{
+		{"enabled", true, []string{"sync", "sync", "sync", "close"}},
+		{"disabled", false, []string{"close"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &fakeSyncFile{}
+			source := newSyncTestSource(t, map[string]interface{}{"path": "out.json", "fsync": tt.fsync}, file)
+
+			for _, record := range []Record{{"n": 1.0}, {"n": 2.0}} {
+				if err := source.Write(context.Background(), []Record{record}); err != nil {
+					t.Fatalf("Failed to write records: %v", err)
+				}
+			}
+			if err := source.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			if !reflect.DeepEqual(file.events, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, file.events)
+			}
+			// The final sync covers the closing bracket
+			if tt.fsync && file.synced[2] != file.Len() {
+				t.Errorf("Expected the last sync after every byte was written, got %d of %d", file.synced[2], file.Len())
+			}
+		})
+	}
+}
+
+func TestJSONSource_FsyncFlushesGzip(t *testing.T) {
+	file := &fakeSyncFile{}
+	source := newSyncTestSource(t, map[string]interface{}{"path": "out.jsonl.gz", "mode": "lines", "fsync": true}, file)
+
+	if err := source.Write(context.Background(), []Record{{"text": "durable"}}); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+
+	// The synced bytes already decode to the record, before the gzip footer is written
+	reader, err := gzip.NewReader(bytes.NewReader(file.Bytes()[:file.synced[0]]))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	if !strings.Contains(string(data), "durable") {
+		t.Errorf("Expected the synced output to hold the record, got %q", data)
+	}
+
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+}
+
+func TestJSONSource_FsyncErrors(t *testing.T) {
+	file := &fakeSyncFile{syncErr: errors.New("disk full")}
+	source := newSyncTestSource(t, map[string]interface{}{"path": "out.json", "mode": "lines", "fsync": true}, file)
+
+	if err := source.Write(context.Background(), []Record{{"n": 1.0}}); err == nil || !strings.Contains(err.Error(), "failed to sync file: disk full") {
+		t.Errorf("Expected the sync error from Write, got %v", err)
+	}
+	if err := source.Close(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected the sync error from Close, got %v", err)
+	}
+	if file.events[len(file.events)-1] != "close" {
+		t.Errorf("Expected the file closed after a failed sync, got %v", file.events)
+	}
+}
+
+func TestJSONSource_FsyncIgnoresUnsyncableOutput(t *testing.T) {
+	file := &plainFile{}
+	source := newSyncTestSource(t, map[string]interface{}{"path": "out.json", "fsync": true}, file)
+
+	if err := source.Write(context.Background(), []Record{{"n": 1.0}}); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+	if !strings.Contains(file.String(), `"n": 1`) {
+		t.Errorf("Expected the record written, got %q", file.String())
+	}
+}
+
+func TestFsync_FileOutputs(t *testing.T) {
+	dir := t.TempDir()
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	records := []Record{{"text": "a"}, {"text": "b"}}
+
+	tests := []struct {
+		name   string
+		format string
+		cfg    map[string]interface{}
+		append bool
+	}{
+		{"json", "json", map[string]interface{}{"path": filepath.Join(dir, "out.json")}, false},
+		{"csv", "csv", map[string]interface{}{"path": filepath.Join(dir, "out.csv")}, false},
+		{"split", "json", map[string]interface{}{"path": filepath.Join(dir, "split"), "split": "per_record"}, false},
+		{"append lines", "json", map[string]interface{}{"path": filepath.Join(dir, "append.jsonl"), "mode": "lines"}, true},
+		{"append array", "json", map[string]interface{}{"path": filepath.Join(dir, "append.json")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg["fsync"] = true
+			source, err := NewDefaultFactory().CreateSource(tt.cfg, tt.format, schema)
+			if err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+			if tt.append {
+				err = Append(context.Background(), source, records)
+			} else {
+				err = source.Write(context.Background(), records)
+			}
+			if err != nil {
+				t.Fatalf("Failed to write records: %v", err)
+			}
+			if err := source.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			path := tt.cfg["path"].(string)
+			if tt.cfg["split"] != nil {
+				path = filepath.Join(path, "000002.json")
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+			if !strings.Contains(string(data), "b") {
+				t.Errorf("Expected the records written, got %q", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// DefaultHTTPTimeout bounds a whole HTTP download, including reading the body, when timeout is not set
+const DefaultHTTPTimeout = 30 * time.Second
+
+// IsHTTPPath reports whether path is an http:// or https:// URL
+func IsHTTPPath(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// HTTPSource implements Source interface for a JSON document fetched with a
+// GET request. The body is streamed through the same decoding and validation
+// as JSONSource files. It is read-only.
+type HTTPSource struct {
+	url  string // redacted, for messages
+	fsys *httpFS
+	json *JSONSource
+}
+
+// NewHTTPSource creates a source for the URL in cfg's path, sending the
+// headers in cfg (e.g. an Authorization token) with the request
+func NewHTTPSource(cfg map[string]interface{}, schema config.SchemaConfig) (*HTTPSource, error) {
+	raw, _ := cfg["path"].(string)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("path must be an http:// or https:// URL for HTTP source, got %q", raw)
+	}
+
+	headers, err := stringMapOption(cfg, "headers")
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, ok, err := durationOption(cfg, "timeout")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		timeout = DefaultHTTPTimeout
+	}
+
+	if _, ok := cfg["split"]; ok {
+		return nil, fmt.Errorf("split is not supported for HTTP sources")
+	}
+
+	// The document is presented as one file named after the last path segment,
+	// so .gz detection applies to it
+	name := httpFileName(u)
+	merged := make(map[string]interface{}, len(cfg))
+	for k, v := range cfg {
+		merged[k] = v
+	}
+	merged["path"] = name
+
+	source, err := NewJSONSource(merged, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// Queries often carry credentials, so messages show the URL without them
+	display := *u
+	display.RawQuery, display.Fragment = "", ""
+	redacted := display.Redacted()
+
+	fsys := &httpFS{
+		ctx:     context.Background(),
+		client:  &http.Client{Timeout: timeout},
+		url:     raw,
+		name:    name,
+		headers: headers,
+	}
+	source.fsys = fsys
+	source.displayRoot = strings.TrimSuffix(redacted, name)
+
+	return &HTTPSource{url: redacted, fsys: fsys, json: source}, nil
+}
+
+// httpFileName returns a file name for the document at u: its last path
+// segment, with glob metacharacters replaced, or "response" when it has none
+func httpFileName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." || !fs.ValidPath(name) {
+		return "response"
+	}
+	return strings.NewReplacer("*", "_", "?", "_", "[", "_", "\\", "_").Replace(name)
+}
+
+// Read downloads the document and decodes its records
+func (h *HTTPSource) Read(ctx context.Context) ([]Record, error) {
+	h.fsys.ctx = ctx
+	return h.json.Read(ctx)
+}
+
+// ReadStream emits records on the returned channel as the body is downloaded and decoded
+func (h *HTTPSource) ReadStream(ctx context.Context) (<-chan Record, <-chan error) {
+	h.fsys.ctx = ctx
+	return h.json.ReadStream(ctx)
+}
+
+// Write is not supported; HTTP sources are read-only
+func (h *HTTPSource) Write(ctx context.Context, records []Record) error {
+	return fmt.Errorf("writing to %s is not supported: HTTP sources are read-only", h.url)
+}
+
+// Capabilities reports the features supported by the HTTP source
+func (h *HTTPSource) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, Compression: true}
+}
+
+// Schema returns the schema the source was configured with
+func (h *HTTPSource) Schema() config.SchemaConfig {
+	return h.json.Schema()
+}
+
+// Close closes the source
+func (h *HTTPSource) Close() error {
+	return nil
+}
+
+// httpFS presents a URL as a filesystem holding a single file, fetched when opened
+type httpFS struct {
+	ctx     context.Context // used for the request; fs.FS methods take none
+	client  *http.Client
+	url     string
+	name    string
+	headers map[string]string
+}
+
+// Open sends the GET request; the returned file streams the response body
+func (f *httpFS) Open(name string) (fs.File, error) {
+	if name != f.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range f.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET request failed: %w", errorWithoutURL(err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+			return nil, fmt.Errorf("GET request returned status %d: %w", resp.StatusCode, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("GET request returned status %d", resp.StatusCode)
+	}
+	return &httpFile{fsys: f, resp: resp}, nil
+}
+
+// errorWithoutURL drops the request URL, which may hold credentials, from a client error
+func errorWithoutURL(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	return err
+}
+
+// Stat describes the single file without a request; its size is unknown until opened
+func (f *httpFS) Stat(name string) (fs.FileInfo, error) {
+	switch name {
+	case ".":
+		return remoteFileInfo{name: ".", dir: true}, nil
+	case f.name:
+		return remoteFileInfo{name: f.name, size: -1}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// httpFile is a response body being read
+type httpFile struct {
+	fsys *httpFS
+	resp *http.Response
+}
+
+func (f *httpFile) Read(p []byte) (int, error) {
+	return f.resp.Body.Read(p)
+}
+
+func (f *httpFile) Stat() (fs.FileInfo, error) {
+	info := remoteFileInfo{name: f.fsys.name, size: f.resp.ContentLength}
+	if modified, err := http.ParseTime(f.resp.Header.Get("Last-Modified")); err == nil {
+		info.modTime = modified
+	}
+	return info, nil
+}
+
+func (f *httpFile) Close() error {
+	return f.resp.Body.Close()
+}
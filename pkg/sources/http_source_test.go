@@ -0,0 +1,215 @@
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// newDatasetServer serves JSON documents by path, requiring a bearer token
+func newDatasetServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("{\"text\": \"zipped\"}\n"))
+	writer.Close()
+
+	documents := map[string][]byte{
+		"/eval/batch.json":   []byte(`[{"text": "a"}, {"text": "b"}]`),
+		"/eval/batch.jsonl":  []byte("{\"text\": \"a\"}\n{\"text\": \"b\"}\n"),
+		"/eval/lines.gz":     compressed.Bytes(),
+		"/eval/invalid.json": []byte(`[{"text": 1}]`),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPSource_Read(t *testing.T) {
+	server := newDatasetServer(t)
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	headers := map[string]interface{}{"Authorization": "Bearer secret"}
+
+	tests := []struct {
+		name string
+		cfg  map[string]interface{}
+		want []Record
+	}{
+		{"array", map[string]interface{}{"path": server.URL + "/eval/batch.json"}, []Record{{"text": "a"}, {"text": "b"}}},
+		{"lines", map[string]interface{}{"path": server.URL + "/eval/batch.jsonl", "mode": "lines"}, []Record{{"text": "a"}, {"text": "b"}}},
+		{"auto with query", map[string]interface{}{"path": server.URL + "/eval/batch.jsonl?version=2", "mode": "auto"}, []Record{{"text": "a"}, {"text": "b"}}},
+		{"gzip", map[string]interface{}{"path": server.URL + "/eval/lines.gz", "mode": "lines"}, []Record{{"text": "zipped"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg["headers"] = headers
+			source, err := NewDefaultFactory().CreateSource(tt.cfg, "json", schema)
+			if err != nil {
+				t.Fatalf("Failed to create HTTP source: %v", err)
+			}
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read records: %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, records)
+			}
+		})
+	}
+}
+
+func TestHTTPSource_ReadStream(t *testing.T) {
+	server := newDatasetServer(t)
+	source, err := NewHTTPSource(map[string]interface{}{
+		"path":    server.URL + "/eval/batch.jsonl",
+		"mode":    "lines",
+		"headers": map[string]interface{}{"Authorization": "Bearer secret"},
+	}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP source: %v", err)
+	}
+
+	records, errs := source.ReadStream(context.Background())
+	var got []Record
+	for record := range records {
+		got = append(got, record)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Failed to stream records: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("Expected 2 records, got %v", got)
+	}
+}
+
+func TestHTTPSource_ReadErrors(t *testing.T) {
+	server := newDatasetServer(t)
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+
+	tests := []struct {
+		name    string
+		path    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{"unauthorized", "/eval/batch.json", map[string]interface{}{}, "eval/batch.json: GET request returned status 401"},
+		{"not found", "/eval/missing.json", nil, "GET request returned status 404: file does not exist"},
+		{"server error", "/error", nil, "status 500"},
+		{"validation", "/eval/invalid.json", nil, "invalid.json: record 0"},
+		{"timeout", "/slow", map[string]interface{}{"timeout": "50ms"}, "GET request failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]interface{}{
+				"path":    server.URL + tt.path + "?token=hidden",
+				"headers": map[string]interface{}{"Authorization": "Bearer secret"},
+			}
+			for k, v := range tt.cfg {
+				cfg[k] = v
+			}
+			if tt.name == "unauthorized" {
+				delete(cfg, "headers")
+			}
+
+			source, err := NewHTTPSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create HTTP source: %v", err)
+			}
+			_, err = source.Read(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if strings.Contains(err.Error(), "hidden") {
+				t.Errorf("Expected the query string left out of errors, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPSource_WriteUnsupported(t *testing.T) {
+	source, err := NewHTTPSource(map[string]interface{}{"path": "https://example.com/eval/batch.json"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP source: %v", err)
+	}
+	if source.Capabilities().Write {
+		t.Errorf("Expected HTTP sources to be read-only")
+	}
+	if err := source.Write(context.Background(), []Record{{"text": "a"}}); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected Write to be rejected, got %v", err)
+	}
+}
+
+func TestNewHTTPSource_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{"no host", map[string]interface{}{"path": "https:///batch.json"}, "http:// or https:// URL"},
+		{"headers list", map[string]interface{}{"path": "https://example.com/a.json", "headers": []interface{}{"x"}}, "headers must be a map of strings"},
+		{"header number", map[string]interface{}{"path": "https://example.com/a.json", "headers": map[string]interface{}{"X-Retry": 1}}, "got int for X-Retry"},
+		{"bad timeout", map[string]interface{}{"path": "https://example.com/a.json", "timeout": "soon"}, "timeout must be a duration"},
+		{"negative timeout", map[string]interface{}{"path": "https://example.com/a.json", "timeout": -1}, "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPSource(tt.cfg, config.SchemaConfig{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHTTPSource_Timeout(t *testing.T) {
+	tests := []struct {
+		timeout interface{}
+		want    time.Duration
+	}{
+		{nil, DefaultHTTPTimeout},
+		{"2m", 2 * time.Minute},
+		{5, 5 * time.Second},
+		{1.5, 1500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		source, err := NewHTTPSource(map[string]interface{}{"path": "https://example.com/a.json", "timeout": tt.timeout}, config.SchemaConfig{})
+		if err != nil {
+			t.Fatalf("Failed to create HTTP source: %v", err)
+		}
+		if got := source.fsys.client.Timeout; got != tt.want {
+			t.Errorf("Expected timeout %s for %v, got %s", tt.want, tt.timeout, got)
+		}
+	}
+}
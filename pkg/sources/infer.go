@@ -0,0 +1,78 @@
+package sources
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// InferSchema builds a starting schema from sample records. Each field gets
+// the JSON type all its non-null values share; a field seen with several types
+// falls back to string, which widen_types can coerce scalars to. Fields absent
+// from some records are optional and fields seen as null are nullable. Nested
+// objects stay object fields, and fields are sorted by name.
+func InferSchema(records []Record) config.SchemaConfig {
+	type fieldStats struct {
+		present int
+		null    bool
+		types   map[string]bool
+	}
+
+	stats := make(map[string]*fieldStats)
+	for _, record := range records {
+		for name, value := range record {
+			s := stats[name]
+			if s == nil {
+				s = &fieldStats{types: make(map[string]bool)}
+				stats[name] = s
+			}
+			s.present++
+			if value == nil {
+				s.null = true
+				continue
+			}
+			s.types[jsonTypeName(value)] = true
+		}
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var schema config.SchemaConfig
+	for _, name := range names {
+		s := stats[name]
+		field := config.FieldConfig{
+			Name:     name,
+			Type:     "string",
+			Optional: s.present < len(records),
+			Nullable: s.null,
+		}
+		if len(s.types) == 1 {
+			for fieldType := range s.types {
+				field.Type = fieldType
+			}
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	return schema
+}
+
+// FormatSchemaYAML renders a schema as a schema: block to paste under an
+// input or output in meval.yaml
+func FormatSchemaYAML(schema config.SchemaConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]config.SchemaConfig{"schema": schema}); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,60 @@
+package sources
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestInferSchema(t *testing.T) {
+	records := []Record{
+		{"text": "great", "score": 0.9, "tags": []interface{}{"a"}, "meta": map[string]interface{}{"lang": "en"}, "label": nil, "rating": 4.0},
+		{"text": "awful", "score": 1.0, "tags": []interface{}{}, "label": "negative", "rating": "four", "reviewed": true},
+	}
+
+	schema := InferSchema(records)
+	want := []config.FieldConfig{
+		{Name: "label", Type: "string", Nullable: true},
+		{Name: "meta", Type: "object", Optional: true},
+		{Name: "rating", Type: "string"},
+		{Name: "reviewed", Type: "boolean", Optional: true},
+		{Name: "score", Type: "number"},
+		{Name: "tags", Type: "array"},
+		{Name: "text", Type: "string"},
+	}
+	if !reflect.DeepEqual(schema.Fields, want) {
+		t.Errorf("Expected fields\n%+v\ngot\n%+v", want, schema.Fields)
+	}
+
+	// The inferred schema validates the records it was inferred from, once mixed scalars are widened
+	if err := widenRecord(records[0], schema); err != nil {
+		t.Fatalf("Failed to widen record: %v", err)
+	}
+	validator := newTestValidator(t, schema)
+	for i, record := range records {
+		if err := validator.validateSchemaFields(record); err != nil {
+			t.Errorf("Expected record %d to validate against the inferred schema, got %v", i, err)
+		}
+	}
+
+	if got := InferSchema(nil); len(got.Fields) != 0 {
+		t.Errorf("Expected no fields for no records, got %+v", got.Fields)
+	}
+}
+
+func TestFormatSchemaYAML(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "text", Type: "string"},
+		{Name: "score", Type: "number", Optional: true},
+	}}
+
+	data, err := FormatSchemaYAML(schema)
+	if err != nil {
+		t.Fatalf("Failed to format schema: %v", err)
+	}
+	want := "schema:\n  fields:\n    - name: text\n      type: string\n    - name: score\n      type: number\n      optional: true\n"
+	if string(data) != want {
+		t.Errorf("Expected YAML\n%s\ngot\n%s", want, data)
+	}
+}
@@ -2,23 +2,63 @@ package sources
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/adhaamehab/meval.ai/pkg/config"
 )
 
+// errLimitReached stops a read once the limit option's records were read
+var errLimitReached = errors.New("limit reached")
+
 // JSONSource implements Source interface for JSON files
 type JSONSource struct {
 	path       string
-	mode       string // "array" or "lines"
+	mode       string // "array", "lines", "object" or "auto"
 	schema     config.SchemaConfig
 	isWritable bool
 	writer     io.WriteCloser
+	fsys       fs.FS // read from this filesystem instead of the OS when set
+
+	create      func(ctx context.Context) (io.WriteCloser, error) // opens the output in place of a local file when set
+	displayRoot string                                            // prefixed to matched names in messages, e.g. s3://bucket/
+
+	floatPrecision   int    // decimal places for number fields on write, -1 to disable
+	onMissingFile    string // "fail" or "skip" when a matched file disappears before reading
+	compression      string // CompressionAuto, CompressionGzip, or CompressionNone
+	files            FileOptions
+	validator        *recordValidator
+	detectedModes    map[string]string
+	objectRecords    []Record // buffered until Close in object mode
+	written          int      // records written so far, across Write calls
+	tolerantLastLine bool     // skip an unterminated final JSON line that fails to parse
+	partialLines     int
+	skippedFiles     int
+	continueOnError  bool              // skip files that fail to read or validate instead of failing the read
+	failedFiles      []FileError       // files skipped by continueOnError
+	split            *recordFileWriter // writes one file per record under path when set
+	fsync            bool              // sync output files to disk after each Write and before closing
+	synced           syncFile          // the output file synced after each Write, when fsync is set
+	errorField       string            // written records with an error here may lack schema fields
+	encoder          EncoderFunc       // encodes written records
+	appendWrites     bool              // Write adds lines to an existing file instead of truncating it
+	readConcurrency  int               // files Read decodes at once
+	limit            int               // stop reading after this many records, -1 for no limit
+	skip             int               // records dropped from the start of a read, before the limit
+	writeEmpty       bool              // Close creates an empty output when nothing was written
+	mu               sync.Mutex        // guards detectedModes and partialLines while files are read concurrently
 }
 
 // NewJSONSource creates a new JSON source
@@ -33,60 +73,381 @@ func NewJSONSource(cfg map[string]interface{}, schema config.SchemaConfig) (*JSO
 		mode = "array" // default to JSON array
 	}
 
-	if mode != "array" && mode != "lines" {
-		return nil, fmt.Errorf("unsupported mode: %s (must be 'array' or 'lines')", mode)
+	if mode != "array" && mode != "lines" && mode != "object" && mode != "auto" {
+		return nil, fmt.Errorf("unsupported mode: %s (must be 'array', 'lines', 'object' or 'auto')", mode)
+	}
+
+	floatPrecision, ok, err := intOption(cfg, "float_precision")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		floatPrecision = -1
+	} else if floatPrecision < 0 {
+		return nil, fmt.Errorf("float_precision must not be negative, got %d", floatPrecision)
+	}
+
+	onMissingFile, err := choiceOption(cfg, "on_missing_file", "fail", "fail", "skip")
+	if err != nil {
+		return nil, err
+	}
+
+	compression, err := choiceOption(cfg, "compression", CompressionAuto, CompressionAuto, CompressionGzip, CompressionNone)
+	if err != nil {
+		return nil, err
+	}
+
+	recursive, err := boolOption(cfg, "recursive")
+	if err != nil {
+		return nil, err
+	}
+
+	sortFiles, err := choiceOption(cfg, "sort", SortByName, SortByName, SortByModTime)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := newRecordValidator(cfg, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	tolerantLastLine, err := boolOption(cfg, "tolerate_partial_last_line")
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := choiceOption(cfg, "split", "", SplitPerRecord)
+	if err != nil {
+		return nil, err
+	}
+	var splitWriter *recordFileWriter
+	if split == SplitPerRecord {
+		template, _ := cfg["filename"].(string)
+		if splitWriter, err = newRecordFileWriter(path, template); err != nil {
+			return nil, err
+		}
+	}
+
+	continueOnError, err := boolOption(cfg, "continue_on_file_error")
+	if err != nil {
+		return nil, err
+	}
+
+	fsync, err := boolOption(cfg, "fsync")
+	if err != nil {
+		return nil, err
+	}
+	appendWrites, err := boolOption(cfg, "append")
+	if err != nil {
+		return nil, err
+	}
+	if appendWrites && mode != "lines" {
+		return nil, fmt.Errorf("append is only supported in lines mode; %s mode files cannot be appended to safely", mode)
+	}
+	if appendWrites && splitWriter != nil {
+		return nil, fmt.Errorf("append is not supported with split")
+	}
+
+	encoder, err := encoderOption(cfg, "encoder")
+	if err != nil {
+		return nil, err
+	}
+	if splitWriter != nil {
+		splitWriter.fsync = fsync
+		splitWriter.encoder = encoder
+	}
+
+	errorField, _ := cfg["error_field"].(string)
+
+	readConcurrency, ok, err := intOption(cfg, "read_concurrency")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		readConcurrency = runtime.GOMAXPROCS(0)
+	} else if readConcurrency < 1 {
+		return nil, fmt.Errorf("read_concurrency must be at least 1, got %d", readConcurrency)
+	}
+
+	limit, err := pushdownLimit(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	skip, _, err := intOption(cfg, "skip")
+	if err != nil {
+		return nil, err
+	}
+	if skip < 0 {
+		return nil, fmt.Errorf("skip must not be negative, got %d", skip)
+	}
+
+	writeEmpty, err := boolOption(cfg, "write_empty")
+	if err != nil {
+		return nil, err
+	}
+
+	if IsStdioPath(path) && splitWriter != nil {
+		return nil, fmt.Errorf("split is not supported when writing to stdout")
+	}
+
+	source := &JSONSource{
+		path:             path,
+		mode:             mode,
+		schema:           schema,
+		floatPrecision:   floatPrecision,
+		onMissingFile:    onMissingFile,
+		compression:      compression,
+		files:            FileOptions{Recursive: recursive, Sort: sortFiles},
+		validator:        validator,
+		detectedModes:    make(map[string]string),
+		tolerantLastLine: tolerantLastLine,
+		continueOnError:  continueOnError,
+		split:            splitWriter,
+		fsync:            fsync,
+		errorField:       errorField,
+		encoder:          encoder,
+		appendWrites:     appendWrites,
+		readConcurrency:  readConcurrency,
+		limit:            limit,
+		skip:             skip,
+		writeEmpty:       writeEmpty,
+	}
+	if IsStdioPath(path) {
+		source.useStdio()
+	}
+	return source, nil
+}
+
+// NewJSONSourceFromFS creates a read-only JSON source over fsys, e.g. an embed.FS.
+// The pattern is an fs.FS path and may contain wildcards.
+func NewJSONSourceFromFS(fsys fs.FS, pattern string, cfg map[string]interface{}, schema config.SchemaConfig) (*JSONSource, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("filesystem is required for JSON source")
+	}
+
+	merged := make(map[string]interface{}, len(cfg)+1)
+	for k, v := range cfg {
+		merged[k] = v
+	}
+	merged["path"] = pattern
+
+	source, err := NewJSONSource(merged, schema)
+	if err != nil {
+		return nil, err
 	}
+	source.fsys = fsys
+	source.create = nil
 
-	return &JSONSource{
-		path:   path,
-		mode:   mode,
-		schema: schema,
-	}, nil
+	return source, nil
 }
 
 // Read reads records from JSON files
 func (j *JSONSource) Read(ctx context.Context) ([]Record, error) {
-	files, err := j.findFiles()
+	var allRecords, fileRecords []Record
+	err := j.readFiles(ctx, j.readConcurrency, func(record Record) error {
+		fileRecords = append(fileRecords, record)
+		return nil
+	}, func(ok bool) {
+		// Records of a file that failed part way are dropped along with it
+		if ok {
+			allRecords = append(allRecords, fileRecords...)
+		}
+		fileRecords = nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to find files: %w", err)
+		if ctx.Err() != nil {
+			return allRecords, err
+		}
+		return nil, err
 	}
+	return allRecords, nil
+}
 
-	if len(files) == 0 {
-		return nil, fmt.Errorf("no files found matching pattern: %s", j.path)
+// readFiles reads every file matched by the path, passing each record to emit
+// as it is decoded and calling fileDone, when set, after each file with
+// whether it was read in full. Files that fail are skipped or abort the read
+// as on_missing_file and continue_on_file_error decide. With concurrency above
+// 1, up to that many files are decoded at once and their records are passed
+// on in file-match order once each file is read. The first skip records are
+// dropped, and with a limit, reading stops once that many records were passed
+// on, keeping those of the file in progress. A limit reads files one at a
+// time, so no file past the limit is decoded ahead.
+func (j *JSONSource) readFiles(ctx context.Context, concurrency int, emit func(Record) error, fileDone func(ok bool)) error {
+	fsys, pattern, root, err := j.filesystem()
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
 	}
 
-	var allRecords []Record
+	files, err := resolveFiles(fsys, pattern, j.displayRoot+j.path, j.files)
+	if err != nil {
+		return fmt.Errorf("failed to find files: %w", err)
+	}
 
-	for _, file := range files {
-		select {
-		case <-ctx.Done():
-			return allRecords, ctx.Err()
-		default:
-			records, err := j.readFile(file)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+	j.failedFiles = nil
+
+	// kept and skipped count the records of finished files, pending and
+	// pendingSkipped those of the file in progress
+	var kept, pending, skipped, pendingSkipped int
+	if j.skip > 0 || j.limit >= 0 {
+		next := emit
+		emit = func(record Record) error {
+			if skipped+pendingSkipped < j.skip {
+				pendingSkipped++
+				return nil
+			}
+			if j.limit >= 0 && kept+pending >= j.limit {
+				return errLimitReached
 			}
-			allRecords = append(allRecords, records...)
+			pending++
+			return next(record)
 		}
 	}
 
-	return allRecords, nil
+	read := func(i int) error { return j.readFile(fsys, files[i], emit) }
+	if concurrency > 1 && len(files) > 1 && j.limit < 0 {
+		prefetch := j.prefetchFiles(ctx, fsys, files, concurrency)
+		defer prefetch.stop()
+		read = func(i int) error { return prefetch.replay(ctx, i, emit) }
+	}
+
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := read(i)
+		if errors.Is(err, errLimitReached) {
+			if fileDone != nil {
+				fileDone(true)
+			}
+			return nil
+		}
+		if fileDone != nil {
+			fileDone(err == nil)
+		}
+		// Records of a failed file count only where they were already passed on for good
+		if err == nil || fileDone == nil {
+			kept += pending
+			skipped += pendingSkipped
+		}
+		pending, pendingSkipped = 0, 0
+		if err == nil {
+			continue
+		}
+
+		displayPath := j.displayRoot + filepath.Join(root, filepath.FromSlash(file))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if j.onMissingFile == "skip" && errors.Is(err, fs.ErrNotExist) {
+			// The file matched the pattern but disappeared before it could be read
+			j.skippedFiles++
+			log.Printf("warning: skipping file %s that disappeared before reading", displayPath)
+			continue
+		}
+		if j.continueOnError {
+			j.failedFiles = append(j.failedFiles, FileError{Path: displayPath, Err: err})
+			log.Printf("warning: skipping file %s that failed to read: %v", displayPath, err)
+			continue
+		}
+		return fmt.Errorf("failed to read file %s: %w", displayPath, err)
+	}
+
+	if len(j.failedFiles) > 0 {
+		if len(j.failedFiles) == len(files) {
+			return fmt.Errorf("every matched file failed to read: %w", joinFileErrors(j.failedFiles))
+		}
+		log.Printf("warning: skipped %d of %d files that failed to read", len(j.failedFiles), len(files))
+	}
+
+	return nil
+}
+
+// ReadStream emits records on the returned channel as they are decoded, so
+// files need not fit in memory: lines mode sends each line as it is scanned
+// and array mode each element as it is decoded. Unlike Read, records of a file
+// skipped by continue_on_file_error may already have been sent. Statistics
+// such as FailedFiles are complete once the error channel is closed. Files
+// are read one at a time; read_concurrency only applies to Read.
+func (j *JSONSource) ReadStream(ctx context.Context) (<-chan Record, <-chan error) {
+	records := make(chan Record)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(records)
+
+		err := j.readFiles(ctx, 1, func(record Record) error {
+			select {
+			case records <- record:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, nil)
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return records, errs
+}
+
+// DetectedModes returns the layout detected for each file read with mode auto
+func (j *JSONSource) DetectedModes() map[string]string {
+	return j.detectedModes
+}
+
+// ObservedTypes returns, per schema field, how often each JSON type was seen on read
+func (j *JSONSource) ObservedTypes() map[string]map[string]int {
+	return j.validator.types.observed
+}
+
+// PartialLines returns the number of partial last lines skipped in lines mode
+func (j *JSONSource) PartialLines() int {
+	return j.partialLines
+}
+
+// SkippedFiles returns the number of matched files skipped because they disappeared before reading
+func (j *JSONSource) SkippedFiles() int {
+	return j.skippedFiles
+}
+
+// FailedFiles returns the files skipped by continue_on_file_error, with the error each failed with
+func (j *JSONSource) FailedFiles() []FileError {
+	return j.failedFiles
 }
 
 // Write writes records to a JSON file
 func (j *JSONSource) Write(ctx context.Context, records []Record) error {
-	if j.writer == nil {
-		// Ensure directory exists
-		dir := filepath.Dir(j.path)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
+	if j.fsys != nil && j.create == nil {
+		return fmt.Errorf("JSON source backed by fs.FS is read-only")
+	}
+
+	if j.mode == "auto" {
+		return fmt.Errorf("mode auto is only supported for reading")
+	}
+
+	if j.split != nil {
+		return j.writeSplit(ctx, records)
+	}
 
-		file, err := os.Create(j.path)
+	if j.writer == nil {
+		file, err := j.createOutput(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to create file: %w", err)
+			return err
+		}
+		// Outputs that cannot be synced, such as S3 uploads, ignore fsync
+		if synced, ok := file.(syncFile); ok && j.fsync {
+			j.synced = synced
+			file = syncingFile{synced}
 		}
 		j.writer = file
+		if isCompressed(j.compression, j.path) {
+			j.writer = newGzipWriter(file)
+		}
 		j.isWritable = true
 
 		if j.mode == "array" {
@@ -97,167 +458,456 @@ func (j *JSONSource) Write(ctx context.Context, records []Record) error {
 		}
 	}
 
-	encoder := json.NewEncoder(j.writer)
-	encoder.SetIndent("", "  ")
+	encoder := j.encoder(j.writer)
+	if j.mode != "lines" {
+		// JSON lines must keep each record on a single line
+		encoder.SetIndent("", "  ")
+	}
 
-	for i, record := range records {
+	for _, record := range records {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			if j.mode == "array" && i > 0 {
+			if j.mode == "array" && j.written > 0 {
 				if _, err := j.writer.Write([]byte(",\n")); err != nil {
 					return err
 				}
 			}
 
 			// Validate record against schema
-			if err := j.validateRecord(record); err != nil {
+			if err := j.validator.validateOutputFields(record, j.errorField); err != nil {
 				return fmt.Errorf("record validation failed: %w", err)
 			}
 
-			if err := encoder.Encode(record); err != nil {
+			if j.mode == "object" {
+				// The layout depends on the final record count, so defer to Close
+				j.objectRecords = append(j.objectRecords, j.formatRecord(record))
+				continue
+			}
+
+			if err := encoder.Encode(j.formatRecord(record)); err != nil {
 				return fmt.Errorf("failed to encode record: %w", err)
 			}
+			j.written++
+		}
+	}
+
+	return j.syncOutput()
+}
+
+// syncOutput flushes compressed output and commits the output file to disk when fsync is set
+func (j *JSONSource) syncOutput() error {
+	if j.synced == nil {
+		return nil
+	}
+	if flusher, ok := j.writer.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return fmt.Errorf("failed to flush output: %w", err)
+		}
+	}
+	if err := j.synced.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	return nil
+}
+
+// createOutput opens the output file, creating its directory if needed. With
+// append set, an existing file is added to instead of truncated.
+func (j *JSONSource) createOutput(ctx context.Context) (io.WriteCloser, error) {
+	if j.create != nil {
+		return j.create(ctx)
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(j.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if j.appendWrites {
+		file, unterminated, err := openAppend(j.path, isCompressed(j.compression, j.path))
+		if err != nil {
+			return nil, err
+		}
+		if unterminated {
+			if _, err := file.Write([]byte("\n")); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to terminate last line: %w", err)
+			}
 		}
+		return file, nil
 	}
 
+	file, err := os.Create(j.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return file, nil
+}
+
+// writeSplit writes each record to its own file named by the filename template
+func (j *JSONSource) writeSplit(ctx context.Context, records []Record) error {
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := j.validator.validateOutputFields(record, j.errorField); err != nil {
+			return fmt.Errorf("record validation failed: %w", err)
+		}
+
+		if err := j.split.write(j.formatRecord(record), j.written+1); err != nil {
+			return err
+		}
+		j.written++
+	}
 	return nil
 }
 
-// Close closes the source
+// Capabilities reports the features supported by the JSON source
+func (j *JSONSource) Capabilities() Capabilities {
+	return Capabilities{
+		Write:       j.fsys == nil || j.create != nil,
+		Streaming:   true,
+		Compression: true,
+		Append:      j.fsys == nil && j.mode != "auto",
+	}
+}
+
+// Schema returns the schema the source was configured with
+func (j *JSONSource) Schema() config.SchemaConfig {
+	return j.schema
+}
+
+// Close closes the source. With write_empty, an output nothing was written to
+// is still created, as an empty array, an empty JSON lines file, or an empty
+// directory with split.
 func (j *JSONSource) Close() error {
+	if j.writeEmpty && j.writer == nil && j.written == 0 {
+		if err := j.writeEmptyOutput(); err != nil {
+			return fmt.Errorf("failed to create empty output: %w", err)
+		}
+	}
 	if j.writer != nil {
-		if j.mode == "array" {
+		var bracketErr error
+		switch j.mode {
+		case "array":
 			// Write closing bracket for array mode
 			if _, err := j.writer.Write([]byte("\n]")); err != nil {
-				return err
+				bracketErr = fmt.Errorf("failed to write closing bracket: %w", err)
 			}
+		case "object":
+			bracketErr = j.writeObjectRecords()
 		}
-		return j.writer.Close()
+		// Always close the underlying file, even if the bracket write failed
+		return errors.Join(bracketErr, j.writer.Close())
 	}
 	return nil
 }
 
-// findFiles finds all files matching the path pattern
-func (j *JSONSource) findFiles() ([]string, error) {
-	// Check if path contains wildcards
-	if filepath.IsAbs(j.path) && !containsWildcard(j.path) {
-		// Direct file path
-		if _, err := os.Stat(j.path); err != nil {
-			return nil, err
+// writeEmptyOutput creates the output of a writable source as if Write was called with no records
+func (j *JSONSource) writeEmptyOutput() error {
+	if !j.Capabilities().Write || j.mode == "auto" {
+		return nil
+	}
+	if j.split != nil {
+		return os.MkdirAll(j.path, 0755)
+	}
+	return j.Write(context.Background(), nil)
+}
+
+// writeObjectRecords writes buffered object mode records as a single object
+// when exactly one record was written, and as an array otherwise
+func (j *JSONSource) writeObjectRecords() error {
+	var value interface{} = j.objectRecords
+	if len(j.objectRecords) == 1 {
+		value = j.objectRecords[0]
+	} else if j.objectRecords == nil {
+		value = []Record{}
+	}
+
+	encoder := j.encoder(j.writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+	return nil
+}
+
+// filesystem returns the filesystem to read from, the pattern relative to it,
+// and the root used to turn matched names back into display paths
+func (j *JSONSource) filesystem() (fs.FS, string, string, error) {
+	if j.fsys != nil {
+		return j.fsys, j.path, "", nil
+	}
+
+	return osFilesystem(j.path)
+}
+
+// readFile decodes the records of a single JSON file in fsys, passing each to emit
+func (j *JSONSource) readFile(fsys fs.FS, name string, emit func(Record) error) error {
+	var file io.ReadCloser
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	if isCompressed(j.compression, name) {
+		if file, err = newGzipReader(file); err != nil {
+			return err
 		}
-		return []string{j.path}, nil
 	}
+	defer file.Close()
 
-	// Use glob for wildcard patterns
-	matches, err := filepath.Glob(j.path)
+	reader := bufio.NewReader(file)
+	first, err := peekFirstByte(reader)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Filter only files (not directories)
-	var files []string
-	for _, match := range matches {
-		info, err := os.Stat(match)
+	mode := j.mode
+	var input io.Reader = reader
+	if mode == "auto" {
+		mode, input, err = detectMode(reader, first)
 		if err != nil {
-			continue
+			return err
 		}
-		if !info.IsDir() {
-			files = append(files, match)
+		j.mu.Lock()
+		j.detectedModes[name] = mode
+		j.mu.Unlock()
+	}
+
+	switch mode {
+	case "array":
+		if first != 0 && first != '[' {
+			return fmt.Errorf("expected a JSON array but file starts with %q; use mode: lines for JSON lines files or mode: auto to detect the layout", first)
+		}
+		return j.readJSONArray(input, emit)
+	case "object":
+		if first != 0 && first != '{' {
+			return fmt.Errorf("expected a JSON object but file starts with %q; use mode: array or mode: auto to detect the layout", first)
+		}
+		return j.readJSONObject(input, emit)
+	default:
+		return j.readJSONLines(input, emit)
+	}
+}
+
+// detectMode decides the layout of a file from its first non-whitespace byte.
+// Files starting with '{' are buffered to tell a single object from JSON lines.
+func detectMode(reader *bufio.Reader, first byte) (string, io.Reader, error) {
+	switch first {
+	case '[':
+		return "array", reader, nil
+	case '{':
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return "", nil, err
+		}
+		if isSingleJSONValue(data) {
+			return "object", bytes.NewReader(data), nil
 		}
+		return "lines", bytes.NewReader(data), nil
+	default:
+		// Fall back to lines, which reports line-numbered errors
+		return "lines", reader, nil
 	}
+}
 
-	return files, nil
+// isSingleJSONValue reports whether data holds exactly one JSON value
+func isSingleJSONValue(data []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	var value json.RawMessage
+	if err := decoder.Decode(&value); err != nil {
+		return false
+	}
+
+	_, err := decoder.Token()
+	return err == io.EOF
 }
 
-// readFile reads records from a single JSON file
-func (j *JSONSource) readFile(path string) ([]Record, error) {
-	file, err := os.Open(path)
+// readJSONObject reads a file holding a single JSON object as one record
+func (j *JSONSource) readJSONObject(reader io.Reader, emit func(Record) error) error {
+	decoder := json.NewDecoder(reader)
+
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return fmt.Errorf("failed to decode JSON object: %w", err)
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after JSON object")
+	}
+
+	record, err := j.validator.decodeAndValidate(raw)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("record: %w", err)
 	}
-	defer file.Close()
 
-	if j.mode == "array" {
-		return j.readJSONArray(file)
+	return emit(record)
+}
+
+// peekFirstByte skips leading whitespace and returns the next byte without consuming it.
+// It returns 0 if the reader is empty.
+func peekFirstByte(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			return b, reader.UnreadByte()
+		}
 	}
-	return j.readJSONLines(file)
 }
 
-// readJSONArray reads a JSON array file
-func (j *JSONSource) readJSONArray(reader io.Reader) ([]Record, error) {
-	var rawRecords []json.RawMessage
+// readJSONArray reads a JSON array file one element at a time, so the array
+// is never held in memory as a whole
+func (j *JSONSource) readJSONArray(reader io.Reader, emit func(Record) error) error {
 	decoder := json.NewDecoder(reader)
-	
-	if err := decoder.Decode(&rawRecords); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON array: %w", err)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode JSON array: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("failed to decode JSON array: expected '[', got %v", token)
 	}
 
-	records := make([]Record, 0, len(rawRecords))
-	for i, raw := range rawRecords {
-		var record Record
-		if err := json.Unmarshal(raw, &record); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal record %d: %w", i, err)
+	for i := 0; decoder.More(); i++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return fmt.Errorf("failed to decode JSON array: %w", err)
 		}
 
-		if err := j.validateRecord(record); err != nil {
-			return nil, fmt.Errorf("record %d validation failed: %w", i, err)
+		record, err := j.validator.decodeAndValidate(raw)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		if err := emit(record); err != nil {
+			return err
 		}
-
-		records = append(records, record)
 	}
 
-	return records, nil
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to decode JSON array: %w", err)
+	}
+	return nil
 }
 
 // readJSONLines reads a JSON lines file (one JSON object per line)
-func (j *JSONSource) readJSONLines(reader io.Reader) ([]Record, error) {
-	var records []Record
+func (j *JSONSource) readJSONLines(reader io.Reader, emit func(Record) error) error {
 	scanner := bufio.NewScanner(reader)
 	lineNum := 0
 
+	// Track whether the final line ended without a newline, e.g. while still being written
+	unterminated := false
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if atEOF && token != nil && advance == len(data) && bytes.IndexByte(data, '\n') < 0 {
+			unterminated = true
+		}
+		return advance, token, err
+	})
+
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Bytes()
-		
+
 		// Skip empty lines
 		if len(line) == 0 {
 			continue
 		}
 
-		var record Record
-		if err := json.Unmarshal(line, &record); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal line %d: %w", lineNum, err)
+		record, err := j.validator.decodeAndValidate(line)
+		if err != nil {
+			if unterminated && j.tolerantLastLine && errors.Is(err, errMalformedRecord) {
+				j.mu.Lock()
+				j.partialLines++
+				j.mu.Unlock()
+				log.Printf("warning: skipping partial last line %d", lineNum)
+				continue
+			}
+			return fmt.Errorf("line %d: %w", lineNum, err)
 		}
 
-		if err := j.validateRecord(record); err != nil {
-			return nil, fmt.Errorf("line %d validation failed: %w", lineNum, err)
+		if err := emit(record); err != nil {
+			return err
 		}
-
-		records = append(records, record)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+		return fmt.Errorf("error reading file: %w", err)
 	}
 
-	return records, nil
+	return nil
 }
 
-// validateRecord validates a record against the schema
-func (j *JSONSource) validateRecord(record Record) error {
-	for _, field := range j.schema.Fields {
-		value, exists := record[field.Name]
-		if !exists {
-			return fmt.Errorf("missing required field: %s", field.Name)
+// formatRecord applies output formatting options to a copy of the record
+func (j *JSONSource) formatRecord(record Record) Record {
+	formatted := record
+
+	if j.floatPrecision >= 0 {
+		formatted = make(Record, len(record))
+		for k, v := range record {
+			formatted[k] = v
 		}
 
-		if err := validateFieldType(value, field.Type); err != nil {
-			return fmt.Errorf("field %s: %w", field.Name, err)
+		for _, field := range j.schema.Fields {
+			if field.Type != "number" {
+				continue
+			}
+			raw, _ := LookupField(formatted, field.Name)
+			value, ok := raw.(float64)
+			if !ok {
+				continue
+			}
+			if _, top := formatted[field.Name]; top {
+				formatted[field.Name] = roundFloat(value, j.floatPrecision)
+				continue
+			}
+			copyParents(formatted, field.Name)
+			setField(formatted, field.Name, roundFloat(value, j.floatPrecision))
 		}
 	}
-	return nil
+
+	if j.validator.flatten {
+		formatted = FlattenRecord(formatted, j.validator.flattenSep)
+	}
+
+	return formatted
+}
+
+// copyParents replaces the nested objects along a dotted path with copies, so
+// setting its leaf leaves the objects of the record it was copied from unchanged
+func copyParents(record Record, path string) {
+	keys := strings.Split(path, ".")
+	current := map[string]interface{}(record)
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := current[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		copied := make(map[string]interface{}, len(child))
+		for k, v := range child {
+			copied[k] = v
+		}
+		current[key] = copied
+		current = copied
+	}
+}
+
+// roundFloat rounds a value to the given number of decimal places, leaving integers untouched
+func roundFloat(value float64, precision int) float64 {
+	if value == math.Trunc(value) || math.IsInf(value, 0) || math.IsNaN(value) {
+		return value
+	}
+	scale := math.Pow(10, float64(precision))
+	return math.Round(value*scale) / scale
 }
 
 // validateFieldType validates that a value matches the expected type
@@ -274,6 +924,17 @@ func validateFieldType(value interface{}, expectedType string) error {
 		default:
 			return fmt.Errorf("expected number, got %T", value)
 		}
+	case "integer":
+		// JSON decodes every number as a float64, so whole floats are integers
+		switch v := value.(type) {
+		case int, int32, int64:
+		case float64:
+			if v != math.Trunc(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("expected integer, got %v", v)
+			}
+		default:
+			return fmt.Errorf("expected integer, got %T", value)
+		}
 	case "boolean":
 		if _, ok := value.(bool); !ok {
 			return fmt.Errorf("expected boolean, got %T", value)
@@ -286,27 +947,18 @@ func validateFieldType(value interface{}, expectedType string) error {
 		if _, ok := value.(map[string]interface{}); !ok {
 			return fmt.Errorf("expected object, got %T", value)
 		}
+	case "email":
+		return validateEmail(value)
+	case "url":
+		return validateURL(value)
+	case "uuid":
+		return validateUUID(value)
 	default:
-		return fmt.Errorf("unsupported type: %s", expectedType)
+		validate, ok := config.LookupFieldType(expectedType)
+		if !ok {
+			return fmt.Errorf("unsupported type: %s", expectedType)
+		}
+		return validate(value)
 	}
 	return nil
 }
-
-// containsWildcard checks if a path contains wildcard characters
-func containsWildcard(path string) bool {
-	return filepath.Base(path) != path || 
-		   filepath.Dir(path) == "." ||
-		   containsAny(path, "*?[")
-}
-
-// containsAny checks if string contains any of the given characters
-func containsAny(s string, chars string) bool {
-	for _, char := range chars {
-		for _, c := range s {
-			if c == char {
-				return true
-			}
-		}
-	}
-	return false
-}
\ No newline at end of file
@@ -3,10 +3,16 @@ package sources
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 
 	"github.com/adhaamehab/meval.ai/pkg/config"
 )
@@ -18,11 +24,11 @@ func TestJSONSource_ReadArray(t *testing.T) {
 
 	testData := []map[string]interface{}{
 		{
-			"text":               "This is positive",
+			"text":                "This is positive",
 			"predicted_sentiment": "positive",
 		},
 		{
-			"text":               "This is negative",
+			"text":                "This is negative",
 			"predicted_sentiment": "negative",
 		},
 	}
@@ -121,13 +127,13 @@ func TestJSONSource_ReadLines(t *testing.T) {
 func TestJSONSource_Wildcards(t *testing.T) {
 	// Create temporary test files
 	tmpDir := t.TempDir()
-	
+
 	// Create multiple JSON files
 	for i := 1; i <= 3; i++ {
 		testFile := filepath.Join(tmpDir, fmt.Sprintf("data%d.json", i))
 		testData := []map[string]interface{}{
 			{
-				"text":               fmt.Sprintf("Text from file %d", i),
+				"text":                fmt.Sprintf("Text from file %d", i),
 				"predicted_sentiment": "positive",
 			},
 		}
@@ -284,6 +290,11 @@ func TestJSONSource_TypeValidation(t *testing.T) {
 		{"valid number", 42.5, "number", false},
 		{"valid int", 42, "number", false},
 		{"invalid number", "not a number", "number", true},
+		{"valid integer", 42, "integer", false},
+		{"valid int64 integer", int64(42), "integer", false},
+		{"whole float integer", 7.0, "integer", false},
+		{"fractional integer", 3.14, "integer", true},
+		{"invalid integer", "7", "integer", true},
 		{"valid boolean", true, "boolean", false},
 		{"invalid boolean", "true", "boolean", true},
 		{"valid array", []interface{}{1, 2, 3}, "array", false},
@@ -300,4 +311,881 @@ func TestJSONSource_TypeValidation(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestJSONSource_Capabilities(t *testing.T) {
+	source, err := NewJSONSource(map[string]interface{}{"path": "data.json"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	expected := Capabilities{Write: true, Streaming: true, Compression: true, Append: true}
+	if caps := source.Capabilities(); caps != expected {
+		t.Errorf("Expected capabilities %+v, got %+v", expected, caps)
+	}
+}
+
+func TestJSONSource_WriteFloatPrecision(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "output.jsonl")
+
+	cfg := map[string]interface{}{
+		"path":            testFile,
+		"mode":            "lines",
+		"float_precision": 3,
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "score", Type: "number"},
+			{Name: "count", Type: "number"},
+		},
+	}
+
+	source, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records := []Record{
+		{"score": 0.333333, "count": 42.0},
+	}
+
+	if err := source.Write(context.Background(), records); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"score":0.333`) || strings.Contains(string(data), "0.3333") {
+		t.Errorf("Expected score written as 0.333, got %s", data)
+	}
+
+	if !strings.Contains(string(data), `"count":42`) || strings.Contains(string(data), "42.") {
+		t.Errorf("Expected count written as 42, got %s", data)
+	}
+
+	if records[0]["score"] != 0.333333 {
+		t.Errorf("Expected input record to be left unmodified, got %v", records[0]["score"])
+	}
+}
+
+func TestJSONSource_WriteFloatPrecisionNested(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "output.jsonl")
+
+	cfg := map[string]interface{}{
+		"path":            testFile,
+		"mode":            "lines",
+		"float_precision": 2,
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "scores.overall", Type: "number"},
+		},
+	}
+
+	source, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records := []Record{
+		{"scores": map[string]interface{}{"overall": 0.87654, "label": "good"}},
+	}
+
+	if err := source.Write(context.Background(), records); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"overall":0.88`) || strings.Contains(string(data), "0.876") {
+		t.Errorf("Expected nested score written as 0.88, got %s", data)
+	}
+
+	if !strings.Contains(string(data), `"label":"good"`) {
+		t.Errorf("Expected sibling nested field to be kept, got %s", data)
+	}
+
+	if overall := records[0]["scores"].(map[string]interface{})["overall"]; overall != 0.87654 {
+		t.Errorf("Expected input record to be left unmodified, got %v", overall)
+	}
+}
+
+func TestJSONSource_ReadFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/batch_1.json":  {Data: []byte(`[{"text": "From FS 1", "predicted_sentiment": "positive"}]`)},
+		"data/batch_2.json":  {Data: []byte(`[{"text": "From FS 2", "predicted_sentiment": "negative"}]`)},
+		"data/ignored.jsonl": {Data: []byte(`{"text": "ignored", "predicted_sentiment": "neutral"}`)},
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "predicted_sentiment", Type: "string"},
+		},
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "data/batch_*.json", map[string]interface{}{"mode": "array"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	if records[1]["text"] != "From FS 2" {
+		t.Errorf("Expected second text to be 'From FS 2', got %v", records[1]["text"])
+	}
+
+	if source.Capabilities().Write {
+		t.Error("Expected fs.FS backed source to be read-only")
+	}
+
+	if err := source.Write(context.Background(), records); err == nil {
+		t.Error("Expected write to fs.FS backed source to fail, got nil")
+	}
+
+	// A missing literal path keeps the underlying not-exist error
+	missing, err := NewJSONSourceFromFS(fsys, "data/missing.json", nil, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	_, err = missing.Read(context.Background())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist for a missing path, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "data/missing.json: file does not exist") {
+		t.Errorf("Expected error to name the missing path, got %v", err)
+	}
+}
+
+// vanishingFS lists a file during globbing but removes it from disk on first Open
+type vanishingFS struct {
+	fs.FS
+	dir     string
+	vanish  string
+	removed bool
+}
+
+func (v *vanishingFS) Open(name string) (fs.File, error) {
+	if name == v.vanish && !v.removed {
+		v.removed = true
+		if err := os.Remove(filepath.Join(v.dir, filepath.FromSlash(name))); err != nil {
+			return nil, err
+		}
+	}
+	return v.FS.Open(name)
+}
+
+func (v *vanishingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(v.FS, name)
+}
+
+func (v *vanishingFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(v.FS, name)
+}
+
+func TestJSONSource_OnMissingFile(t *testing.T) {
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+		},
+	}
+
+	tests := []struct {
+		onMissingFile string
+		shouldError   bool
+	}{
+		{"skip", false},
+		{"fail", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onMissingFile, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for i := 1; i <= 2; i++ {
+				testFile := filepath.Join(tmpDir, fmt.Sprintf("data%d.json", i))
+				data := fmt.Sprintf(`[{"text": "file %d"}]`, i)
+				if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+					t.Fatalf("Failed to create test file: %v", err)
+				}
+			}
+
+			fsys := &vanishingFS{FS: os.DirFS(tmpDir), dir: tmpDir, vanish: "data1.json"}
+			cfg := map[string]interface{}{"on_missing_file": tt.onMissingFile}
+
+			source, err := NewJSONSourceFromFS(fsys, "data*.json", cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+
+			records, err := source.Read(context.Background())
+			if tt.shouldError {
+				if err == nil {
+					t.Error("Expected error for missing file, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to read records: %v", err)
+			}
+
+			if len(records) != 1 || records[0]["text"] != "file 2" {
+				t.Errorf("Expected only the remaining file to be read, got %v", records)
+			}
+
+			if source.SkippedFiles() != 1 {
+				t.Errorf("Expected 1 skipped file, got %d", source.SkippedFiles())
+			}
+		})
+	}
+}
+
+func TestJSONSource_ContinueOnFileError(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	fsys := fstest.MapFS{
+		"data/1.json": {Data: []byte(`[{"text": "one"}]`)},
+		"data/2.json": {Data: []byte(`[{"text": "two"`)},
+		"data/3.json": {Data: []byte(`[{"text": 3}]`)},
+		"data/4.json": {Data: []byte(`[{"text": "four"}]`)},
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "data/*.json", map[string]interface{}{"continue_on_file_error": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 2 || records[0]["text"] != "one" || records[1]["text"] != "four" {
+		t.Errorf("Expected the records of the good files, got %v", records)
+	}
+
+	failed := source.FailedFiles()
+	if len(failed) != 2 || failed[0].Path != "data/2.json" || failed[1].Path != "data/3.json" {
+		t.Fatalf("Expected the corrupt and invalid files to be reported, got %v", failed)
+	}
+	if failed[0].Err == nil || !strings.Contains(failed[1].Error(), "data/3.json") {
+		t.Errorf("Expected each failure to carry its path and error, got %v", failed)
+	}
+
+	// Without the option the first bad file fails the read
+	strict, err := NewJSONSourceFromFS(fsys, "data/*.json", nil, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := strict.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "data/2.json") {
+		t.Errorf("Expected the read to fail on data/2.json, got %v", err)
+	}
+}
+
+func TestJSONSource_ContinueOnFileErrorAllFail(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	fsys := fstest.MapFS{
+		"data/1.json": {Data: []byte(`{`)},
+		"data/2.json": {Data: []byte(`nope`)},
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "data/*.json", map[string]interface{}{"continue_on_file_error": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "every matched file failed") {
+		t.Errorf("Expected an error when no file could be read, got %v", err)
+	}
+}
+
+func TestJSONSource_StrictSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.jsonl")
+
+	testData := `{"text": "ok", "predicted_sentiment": "positive"}
+{"text": "drifted", "predicted_sentiment": "negative", "source": "upstream", "annotator": "a1"}`
+
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "predicted_sentiment", Type: "string"},
+		},
+	}
+
+	// Lenient by default
+	lenient, err := NewJSONSource(map[string]interface{}{"path": testFile, "mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := lenient.Read(context.Background()); err != nil {
+		t.Fatalf("Expected lenient read to succeed, got %v", err)
+	}
+
+	strict, err := NewJSONSource(map[string]interface{}{"path": testFile, "mode": "lines", "strict_schema": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	_, err = strict.Read(context.Background())
+	if err == nil {
+		t.Fatal("Expected strict schema error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "annotator, source") {
+		t.Errorf("Expected error to name the extra fields, got %v", err)
+	}
+}
+
+func TestJSONSource_ArrayModeOnJSONLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.json")
+
+	testData := `{"text": "Line 1", "predicted_sentiment": "positive"}
+{"text": "Line 2", "predicted_sentiment": "negative"}`
+
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "predicted_sentiment", Type: "string"},
+		},
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": testFile, "mode": "array"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	_, err = source.Read(context.Background())
+	if err == nil {
+		t.Fatal("Expected error reading JSON lines in array mode, got nil")
+	}
+	if !strings.Contains(err.Error(), "mode: lines") {
+		t.Errorf("Expected error to suggest mode: lines, got %v", err)
+	}
+
+	// mode: auto falls back to lines
+	source, err = NewJSONSource(map[string]interface{}{"path": testFile, "mode": "auto"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Expected auto mode to read JSON lines, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected 2 records, got %d", len(records))
+	}
+}
+
+func TestJSONSource_AutoMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+		records  int
+	}{
+		{"array", `[{"text": "a"}, {"text": "b"}]`, "array", 2},
+		{"lines", "{\"text\": \"a\"}\n{\"text\": \"b\"}\n{\"text\": \"c\"}\n", "lines", 3},
+		{"single object", "{\n  \"text\": \"a\"\n}\n", "object", 1},
+		{"leading whitespace", "\n\n  [{\"text\": \"a\"}]", "array", 1},
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"data.json": {Data: []byte(tt.data)}}
+
+			source, err := NewJSONSourceFromFS(fsys, "data.json", map[string]interface{}{"mode": "auto"}, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read records: %v", err)
+			}
+
+			if len(records) != tt.records {
+				t.Errorf("Expected %d records, got %d", tt.records, len(records))
+			}
+
+			if mode := source.DetectedModes()["data.json"]; mode != tt.expected {
+				t.Errorf("Expected detected mode %s, got %s", tt.expected, mode)
+			}
+		})
+	}
+}
+
+func TestJSONSource_ObjectMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "record.json")
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "predicted_sentiment", Type: "string"},
+		},
+	}
+
+	cfg := map[string]interface{}{
+		"path": testFile,
+		"mode": "object",
+	}
+
+	writer, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	record := Record{"text": "Only one", "predicted_sentiment": "neutral"}
+	if err := writer.Write(context.Background(), []Record{record}); err != nil {
+		t.Fatalf("Failed to write record: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+
+	var written Record
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Expected a single JSON object, got %s: %v", data, err)
+	}
+
+	reader, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := reader.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	if len(records) != 1 || records[0]["text"] != "Only one" {
+		t.Errorf("Expected the single record back, got %v", records)
+	}
+}
+
+func TestJSONSource_ObjectModeMultipleRecords(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "records.json")
+
+	source, err := NewJSONSource(map[string]interface{}{"path": testFile, "mode": "object"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	if err := source.Write(context.Background(), []Record{{"n": 1.0}, {"n": 2.0}}); err != nil {
+		t.Fatalf("Failed to write records: %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+
+	var written []Record
+	if err := json.Unmarshal(data, &written); err != nil || len(written) != 2 {
+		t.Errorf("Expected an array of 2 records, got %s", data)
+	}
+}
+
+func TestJSONSource_WriteMultipleCalls(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "output.json")
+
+	source, err := NewJSONSource(map[string]interface{}{"path": testFile, "mode": "array"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := source.Write(context.Background(), []Record{{"n": float64(i)}}); err != nil {
+			t.Fatalf("Failed to write record %d: %v", i, err)
+		}
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+
+	var written []Record
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Expected valid JSON array across Write calls, got %s: %v", data, err)
+	}
+	if len(written) != 3 {
+		t.Errorf("Expected 3 written records, got %d", len(written))
+	}
+}
+
+func TestJSONSource_PartialLastLine(t *testing.T) {
+	truncated := "{\"text\": \"complete 1\"}\n{\"text\": \"complete 2\"}\n{\"text\": \"still being wri"
+	fsys := fstest.MapFS{
+		"log.jsonl":       {Data: []byte(truncated)},
+		"corrupted.jsonl": {Data: []byte("{\"text\": \"bad\n{\"text\": \"complete\"}\n")},
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{{Name: "text", Type: "string"}},
+	}
+
+	// Without the option the truncated line is an error
+	source, err := NewJSONSourceFromFS(fsys, "log.jsonl", map[string]interface{}{"mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil {
+		t.Fatal("Expected error for truncated last line, got nil")
+	}
+
+	cfg := map[string]interface{}{"mode": "lines", "tolerate_partial_last_line": true}
+
+	source, err = NewJSONSourceFromFS(fsys, "log.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Expected truncated last line to be skipped, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected 2 complete records, got %d", len(records))
+	}
+	if source.PartialLines() != 1 {
+		t.Errorf("Expected 1 partial line, got %d", source.PartialLines())
+	}
+
+	// Malformed terminated lines are still errors
+	source, err = NewJSONSourceFromFS(fsys, "corrupted.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil {
+		t.Error("Expected error for malformed terminated line, got nil")
+	}
+}
+
+var registerSKUOnce sync.Once
+
+// registerTestSKUType registers a sku field type once per test binary
+func registerTestSKUType() {
+	registerSKUOnce.Do(func() {
+		pattern := regexp.MustCompile(`^[A-Z]{3}-[0-9]{4}$`)
+		config.RegisterFieldType("sku", func(value interface{}) error {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("expected sku string, got %T", value)
+			}
+			if !pattern.MatchString(s) {
+				return fmt.Errorf("invalid sku %q", s)
+			}
+			return nil
+		})
+	})
+}
+
+func TestJSONSource_CustomFieldType(t *testing.T) {
+	registerTestSKUType()
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{{Name: "id", Type: "sku"}},
+	}
+
+	fsys := fstest.MapFS{
+		"valid.jsonl":   {Data: []byte("{\"id\": \"ABC-1234\"}\n")},
+		"invalid.jsonl": {Data: []byte("{\"id\": \"abc-12\"}\n")},
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "valid.jsonl", map[string]interface{}{"mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Expected valid sku to pass, got %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("Expected 1 record, got %d", len(records))
+	}
+
+	source, err = NewJSONSourceFromFS(fsys, "invalid.jsonl", map[string]interface{}{"mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	_, err = source.Read(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid sku") {
+		t.Errorf("Expected invalid sku error, got %v", err)
+	}
+}
+
+func TestJSONSource_LimitStopsEarly(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}}}
+	fsys := newShardsTestFS(3, 5)
+	// Reading any of these would fail, so the limit must stop before them. The
+	// failure alone would not show it: records decoded ahead are dropped on replay.
+	fsys["shards/001.jsonl"].Data = append(fsys["shards/001.jsonl"].Data, "not json\n"...)
+	fsys["shards/002.jsonl"] = &fstest.MapFile{Data: []byte("not json\n")}
+
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("read_concurrency %d", concurrency), func(t *testing.T) {
+			cfg := map[string]interface{}{"mode": "lines", "limit": 7, "read_concurrency": concurrency}
+			source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Expected the read to stop at the limit, got %v", err)
+			}
+			if len(records) != 7 || records[0]["id"] != "000-0" || records[6]["id"] != "001-1" {
+				t.Errorf("Expected the first 7 records across files, got %v", records)
+			}
+			// Only the record that reached the limit is decoded past it
+			if decoded := source.ObservedTypes()["id"]["string"]; decoded > 8 {
+				t.Errorf("Expected at most 8 records decoded, got %d", decoded)
+			}
+
+			streamed, err := collectStream(source.ReadStream(context.Background()))
+			if err != nil {
+				t.Fatalf("Expected the stream to stop at the limit, got %v", err)
+			}
+			if len(streamed) != 7 {
+				t.Errorf("Expected 7 streamed records, got %d", len(streamed))
+			}
+		})
+	}
+}
+
+func TestJSONSource_LimitSkippedFiles(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}}}
+	fsys := newShardsTestFS(3, 2)
+	fsys["shards/000.jsonl"].Data = append(fsys["shards/000.jsonl"].Data, "{\"id\": 1}\n"...)
+
+	cfg := map[string]interface{}{"mode": "lines", "limit": 3, "continue_on_file_error": true, "read_concurrency": 1}
+	source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	// The skipped file's records do not count toward the limit
+	if len(records) != 3 || records[0]["id"] != "001-0" || records[2]["id"] != "002-0" {
+		t.Errorf("Expected 3 records after the skipped file, got %v", records)
+	}
+}
+
+func TestJSONSource_LimitAfterFilter(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}, {Name: "score", Type: "number"}}}
+	fsys := newShardsTestFS(4, 3)
+
+	// filter applies before limit, so the source must read every record
+	cfg := map[string]interface{}{"mode": "lines", "limit": 3, "filter": map[string]interface{}{"score": 2.0}}
+	source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	middlewares, err := MiddlewareFor(cfg)
+	if err != nil {
+		t.Fatalf("Failed to build middlewares: %v", err)
+	}
+	records, err := Chain(source, middlewares...).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 3 || records[0]["id"] != "000-2" || records[2]["id"] != "002-2" {
+		t.Errorf("Expected the first 3 filtered records, got %v", records)
+	}
+
+	if _, err := NewJSONSource(map[string]interface{}{"path": "in.json", "limit": -1}, schema); err == nil {
+		t.Error("Expected error for negative limit, got nil")
+	}
+}
+
+func TestJSONSource_WriteEmpty(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   map[string]interface{}
+		file  string
+		array bool // the file holds an empty JSON array
+	}{
+		{"array", map[string]interface{}{"mode": "array"}, "output.json", true},
+		{"object", map[string]interface{}{"mode": "object"}, "output.json", true},
+		{"lines", map[string]interface{}{"mode": "lines"}, "output.jsonl", false},
+		{"gzip array", map[string]interface{}{"mode": "array"}, "output.json.gz", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", tt.file)
+			tt.cfg["path"] = path
+			tt.cfg["write_empty"] = true
+			source, err := NewJSONSource(tt.cfg, config.SchemaConfig{})
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+			if err := source.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			// The empty output reads back as zero records
+			reader, err := NewJSONSource(map[string]interface{}{"path": path, "mode": "auto"}, config.SchemaConfig{})
+			if err != nil {
+				t.Fatalf("Failed to create reader: %v", err)
+			}
+			records, err := reader.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Expected a valid empty output, got %v", err)
+			}
+			if len(records) != 0 {
+				t.Errorf("Expected no records, got %v", records)
+			}
+
+			if tt.array {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("Failed to read output: %v", err)
+				}
+				var got []interface{}
+				if err := json.Unmarshal(data, &got); err != nil || got == nil || len(got) != 0 {
+					t.Errorf("Expected an empty JSON array, got %q (%v)", data, err)
+				}
+			}
+		})
+	}
+
+	t.Run("split", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "records")
+		source, err := NewJSONSource(map[string]interface{}{"path": dir, "split": SplitPerRecord, "write_empty": true}, config.SchemaConfig{})
+		if err != nil {
+			t.Fatalf("Failed to create JSON source: %v", err)
+		}
+		if err := source.Close(); err != nil {
+			t.Fatalf("Failed to close source: %v", err)
+		}
+		if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+			t.Errorf("Expected an empty output directory, got %v (%v)", entries, err)
+		}
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		source, err := NewJSONSourceFromFS(fstest.MapFS{}, "data.json", map[string]interface{}{"write_empty": true}, config.SchemaConfig{})
+		if err != nil {
+			t.Fatalf("Failed to create JSON source: %v", err)
+		}
+		if err := source.Close(); err != nil {
+			t.Errorf("Expected closing a read-only source to create nothing, got %v", err)
+		}
+	})
+}
+
+func TestJSONSource_SkipPages(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}}}
+	fsys := newShardsTestFS(3, 5)
+
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("read_concurrency %d", concurrency), func(t *testing.T) {
+			var paged []string
+			for skip := 0; skip < 15; skip += 4 {
+				cfg := map[string]interface{}{"mode": "lines", "skip": skip, "limit": 4, "read_concurrency": concurrency}
+				source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+				if err != nil {
+					t.Fatalf("Failed to create JSON source: %v", err)
+				}
+				records, err := source.Read(context.Background())
+				if err != nil {
+					t.Fatalf("Failed to read the page at %d: %v", skip, err)
+				}
+				for _, record := range records {
+					paged = append(paged, record["id"].(string))
+				}
+			}
+
+			if len(paged) != 15 || paged[0] != "000-0" || paged[4] != "000-4" || paged[5] != "001-0" || paged[14] != "002-4" {
+				t.Errorf("Expected the pages to cover every record once in order, got %v", paged)
+			}
+		})
+	}
+}
+
+func TestJSONSource_SkipValidatesSkippedRecords(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}}}
+	fsys := newShardsTestFS(2, 3)
+	fsys["shards/000.jsonl"].Data = []byte("{\"id\": 1}\n{\"id\": \"000-1\"}\n")
+
+	source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", map[string]interface{}{"mode": "lines", "skip": 1}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected the skipped invalid record to fail the read, got %v", err)
+	}
+
+	// Records of a file skipped by continue_on_file_error do not count toward skip
+	cfg := map[string]interface{}{"mode": "lines", "skip": 1, "continue_on_file_error": true}
+	tolerant, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	records, err := tolerant.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 2 || records[0]["id"] != "001-1" {
+		t.Errorf("Expected the second file less its first record, got %v", records)
+	}
+
+	if _, err := NewJSONSource(map[string]interface{}{"path": "in.json", "skip": -1}, schema); err == nil {
+		t.Error("Expected error for negative skip, got nil")
+	}
+}
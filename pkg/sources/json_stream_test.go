@@ -0,0 +1,214 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// pipeFS serves a single file whose content is written to a pipe while it is
+// being read, to observe records emitted before the file is complete
+type pipeFS struct {
+	name   string
+	reader *io.PipeReader
+}
+
+func (p pipeFS) Open(name string) (fs.File, error) {
+	if name != p.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return pipeFile{p}, nil
+}
+
+type pipeFile struct{ fs pipeFS }
+
+func (f pipeFile) Read(b []byte) (int, error) { return f.fs.reader.Read(b) }
+func (f pipeFile) Close() error               { return nil }
+func (f pipeFile) Stat() (fs.FileInfo, error) { return pipeInfo{f.fs.name}, nil }
+
+type pipeInfo struct{ name string }
+
+func (i pipeInfo) Name() string       { return i.name }
+func (i pipeInfo) Size() int64        { return 0 }
+func (i pipeInfo) Mode() fs.FileMode  { return 0444 }
+func (i pipeInfo) ModTime() time.Time { return time.Time{} }
+func (i pipeInfo) IsDir() bool        { return false }
+func (i pipeInfo) Sys() interface{}   { return nil }
+
+// collectStream drains a stream, returning its records and final error
+func collectStream(records <-chan Record, errs <-chan error) ([]Record, error) {
+	var all []Record
+	for record := range records {
+		all = append(all, record)
+	}
+	return all, <-errs
+}
+
+func TestJSONSource_ReadStreamEmitsBeforeEOF(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "number"}}}
+
+	tests := []struct {
+		mode        string
+		first, rest string
+	}{
+		{"lines", "{\"id\": 0}\n", "{\"id\": 1}\n"},
+		{"array", "[{\"id\": 0},", " {\"id\": 1}]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			reader, writer := io.Pipe()
+			source, err := NewJSONSourceFromFS(pipeFS{"data.json", reader}, "data.json", map[string]interface{}{"mode": tt.mode}, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+
+			records, errs := source.ReadStream(context.Background())
+			go writer.Write([]byte(tt.first))
+
+			select {
+			case record := <-records:
+				if record["id"] != 0.0 {
+					t.Errorf("Expected the first record, got %v", record)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Expected the first record before the file was complete")
+			}
+
+			go func() {
+				writer.Write([]byte(tt.rest))
+				writer.Close()
+			}()
+			rest, err := collectStream(records, errs)
+			if err != nil {
+				t.Fatalf("Failed to stream records: %v", err)
+			}
+			if len(rest) != 1 || rest[0]["id"] != 1.0 {
+				t.Errorf("Expected the second record after the first, got %v", rest)
+			}
+		})
+	}
+}
+
+func TestJSONSource_ReadStreamMatchesRead(t *testing.T) {
+	dir := t.TempDir()
+	for i, content := range []string{
+		"[{\"text\": \"a\"}, {\"text\": \"b\"}]",
+		"[]",
+		"[{\"text\": \"c\"}]",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.json", i)), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := map[string]interface{}{"path": filepath.Join(dir, "*.json")}
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string", Normalize: []string{"upper"}}}}
+	source, err := NewJSONSource(cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	streamed, err := collectStream(source.ReadStream(context.Background()))
+	if err != nil {
+		t.Fatalf("Failed to stream records: %v", err)
+	}
+	read, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if fmt.Sprint(streamed) != fmt.Sprint(read) || len(read) != 3 || read[0]["text"] != "A" {
+		t.Errorf("Expected the stream to match Read, got %v and %v", streamed, read)
+	}
+}
+
+func TestJSONSource_ReadStreamErrors(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "number"}}}
+
+	tests := []struct {
+		mode    string
+		content string
+		wantErr string
+		records int
+	}{
+		{"lines", "{\"id\": 1}\n{\"id\": \"x\"}\n{\"id\": 3}\n", "line 2: validation failed", 1},
+		{"array", "[{\"id\": 1}, {\"id\": 2}, {\"id\": ", "failed to decode JSON array", 2},
+		{"array", "{\"id\": 1}", "expected a JSON array", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			source, err := NewJSONSource(map[string]interface{}{"path": path, "mode": tt.mode}, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+
+			records, err := collectStream(source.ReadStream(context.Background()))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if len(records) != tt.records {
+				t.Errorf("Expected %d records before the error, got %v", tt.records, records)
+			}
+		})
+	}
+}
+
+func TestJSONSource_ReadStreamCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	var lines strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&lines, "{\"id\": %d}\n", i)
+	}
+	if err := os.WriteFile(path, []byte(lines.String()), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": path, "mode": "lines"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	records, errs := source.ReadStream(ctx)
+	<-records
+	cancel()
+
+	// The stream stops at the next emit, without the consumer draining it
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected the stream to end after cancellation")
+	}
+	if _, ok := <-records; ok {
+		t.Errorf("Expected the record channel to be closed")
+	}
+}
+
+func TestReadStream_FallsBackToRead(t *testing.T) {
+	src := &staticSource{records: []Record{{"id": 1}, {"id": 2}}}
+
+	records, err := collectStream(ReadStream(context.Background(), src))
+	if err != nil {
+		t.Fatalf("Failed to stream records: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected the records from Read, got %v", records)
+	}
+}
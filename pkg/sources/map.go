@@ -0,0 +1,76 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// Error policies for record transforms, matching the controls.on_error values
+const (
+	ErrorPolicyFail = "fail"
+	ErrorPolicySkip = "skip"
+)
+
+// MapFunc transforms a single record
+type MapFunc func(Record) (Record, error)
+
+// mapSource is a Source whose Read applies a transform to each record
+type mapSource struct {
+	src     Source
+	fn      MapFunc
+	onError string
+}
+
+// Map returns a Source whose Read applies fn to every record read from src.
+// When fn fails, onError decides whether the read fails or the record is skipped.
+// Write and Close are passed through to src unchanged.
+func Map(src Source, fn MapFunc, onError string) Source {
+	if onError == "" {
+		onError = ErrorPolicyFail
+	}
+	return &mapSource{src: src, fn: fn, onError: onError}
+}
+
+// Read reads records from the wrapped source and transforms them
+func (m *mapSource) Read(ctx context.Context) ([]Record, error) {
+	records, err := m.src.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make([]Record, 0, len(records))
+	for i, record := range records {
+		result, err := m.fn(record)
+		if err != nil {
+			if m.onError == ErrorPolicySkip {
+				continue
+			}
+			return nil, fmt.Errorf("transform failed for record %d: %w", i, err)
+		}
+		mapped = append(mapped, result)
+	}
+
+	return mapped, nil
+}
+
+// Write writes records to the wrapped source
+func (m *mapSource) Write(ctx context.Context, records []Record) error {
+	return m.src.Write(ctx, records)
+}
+
+// Close closes the wrapped source
+func (m *mapSource) Close() error {
+	return m.src.Close()
+}
+
+// Capabilities reports the capabilities of the wrapped source
+func (m *mapSource) Capabilities() Capabilities {
+	return m.src.Capabilities()
+}
+
+// Schema returns the schema of the wrapped source
+func (m *mapSource) Schema() config.SchemaConfig {
+	return m.src.Schema()
+}
@@ -0,0 +1,66 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type staticSource struct {
+	BaseSource
+	records []Record
+}
+
+func (s *staticSource) Read(ctx context.Context) ([]Record, error) { return s.records, nil }
+
+func (s *staticSource) Write(ctx context.Context, records []Record) error { return nil }
+
+func (s *staticSource) Close() error { return nil }
+
+func TestMap_TransformsRecords(t *testing.T) {
+	src := &staticSource{records: []Record{
+		{"text": "hello"},
+		{"text": "world"},
+	}}
+
+	upper := func(r Record) (Record, error) {
+		text, _ := r["text"].(string)
+		return Record{"text": strings.ToUpper(text)}, nil
+	}
+
+	records, err := Map(src, upper, ErrorPolicyFail).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	if len(records) != 2 || records[0]["text"] != "HELLO" || records[1]["text"] != "WORLD" {
+		t.Errorf("Expected transformed records, got %v", records)
+	}
+}
+
+func TestMap_ErrorPolicy(t *testing.T) {
+	src := &staticSource{records: []Record{
+		{"text": "keep"},
+		{"text": "drop"},
+	}}
+
+	failOnDrop := func(r Record) (Record, error) {
+		if r["text"] == "drop" {
+			return nil, fmt.Errorf("cannot transform")
+		}
+		return r, nil
+	}
+
+	if _, err := Map(src, failOnDrop, ErrorPolicyFail).Read(context.Background()); err == nil {
+		t.Error("Expected error with fail policy, got nil")
+	}
+
+	records, err := Map(src, failOnDrop, ErrorPolicySkip).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Expected skip policy to succeed, got %v", err)
+	}
+	if len(records) != 1 || records[0]["text"] != "keep" {
+		t.Errorf("Expected only the successful record, got %v", records)
+	}
+}
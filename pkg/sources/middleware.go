@@ -0,0 +1,459 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// Middleware decorates a source with a cross-cutting concern
+type Middleware func(Source) Source
+
+// Chain wraps src with the given middlewares; the first middleware is the outermost,
+// so on Read the last middleware sees the records first
+func Chain(src Source, middlewares ...Middleware) Source {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		src = middlewares[i](src)
+	}
+	return src
+}
+
+// FilterFunc reports whether a record should be kept
+type FilterFunc func(Record) bool
+
+// readTransformSource applies a transform to the records read from src.
+// Write, Append, and Close are passed through to src unchanged.
+type readTransformSource struct {
+	src       Source
+	transform func([]Record) ([]Record, error)
+}
+
+// Read reads records from the wrapped source and transforms them
+func (r *readTransformSource) Read(ctx context.Context) ([]Record, error) {
+	records, err := r.src.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.transform(records)
+}
+
+// Write writes records to the wrapped source
+func (r *readTransformSource) Write(ctx context.Context, records []Record) error {
+	return r.src.Write(ctx, records)
+}
+
+// Append appends records to the wrapped source
+func (r *readTransformSource) Append(ctx context.Context, records []Record) error {
+	return Append(ctx, r.src, records)
+}
+
+// Close closes the wrapped source
+func (r *readTransformSource) Close() error {
+	return r.src.Close()
+}
+
+// Capabilities reports the capabilities of the wrapped source
+func (r *readTransformSource) Capabilities() Capabilities {
+	return r.src.Capabilities()
+}
+
+// Schema returns the schema of the wrapped source
+func (r *readTransformSource) Schema() config.SchemaConfig {
+	return r.src.Schema()
+}
+
+// readTransform builds a middleware from a read-side transform
+func readTransform(transform func([]Record) ([]Record, error)) Middleware {
+	return func(src Source) Source {
+		return &readTransformSource{src: src, transform: transform}
+	}
+}
+
+// WithLimit keeps at most n records
+func WithLimit(n int) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		if len(records) > n {
+			records = records[:n]
+		}
+		return records, nil
+	})
+}
+
+// WithSample keeps each record with probability rate. The same seed selects the
+// same records on every read.
+func WithSample(rate float64, seed int64) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		rng := rand.New(rand.NewSource(seed))
+		sampled := make([]Record, 0, int(float64(len(records))*rate))
+		for _, record := range records {
+			if rng.Float64() < rate {
+				sampled = append(sampled, record)
+			}
+		}
+		return sampled, nil
+	})
+}
+
+// WithSampleSize keeps a uniformly random sample of n records, in the order
+// they were read, or every record when there are no more than n. The same seed
+// selects the same records on every read.
+func WithSampleSize(n int, seed int64) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		if len(records) <= n {
+			return records, nil
+		}
+
+		// A partial Fisher-Yates shuffle of the indexes picks n of them uniformly
+		rng := rand.New(rand.NewSource(seed))
+		indexes := make([]int, len(records))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		for i := 0; i < n; i++ {
+			j := i + rng.Intn(len(indexes)-i)
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		}
+		chosen := indexes[:n]
+		sort.Ints(chosen)
+
+		sampled := make([]Record, n)
+		for i, index := range chosen {
+			sampled[i] = records[index]
+		}
+		return sampled, nil
+	})
+}
+
+// WithShuffle permutes records with a generator seeded by seed, so the same
+// seed yields the same order on every read
+func WithShuffle(seed int64) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		shuffled := make([]Record, len(records))
+		copy(shuffled, records)
+		rng := rand.New(rand.NewSource(seed))
+		rng.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		return shuffled, nil
+	})
+}
+
+// WithFilter keeps only records for which keep returns true
+func WithFilter(keep FilterFunc) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		filtered := make([]Record, 0, len(records))
+		for _, record := range records {
+			if keep(record) {
+				filtered = append(filtered, record)
+			}
+		}
+		return filtered, nil
+	})
+}
+
+// FieldEquals returns a filter keeping records whose fields equal every expected value.
+// Values are compared by their printed form so YAML integers match JSON numbers.
+func FieldEquals(expected map[string]interface{}) FilterFunc {
+	return func(record Record) bool {
+		for field, want := range expected {
+			got, ok := record[field]
+			if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WithDedup drops records identical to an earlier record, keeping the first occurrence
+func WithDedup() Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		seen := make(map[string]bool, len(records))
+		unique := make([]Record, 0, len(records))
+		for i, record := range records {
+			// encoding/json sorts map keys, so the encoding is canonical
+			data, err := json.Marshal(record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode record %d: %w", i, err)
+			}
+			if seen[string(data)] {
+				continue
+			}
+			seen[string(data)] = true
+			unique = append(unique, record)
+		}
+		return unique, nil
+	})
+}
+
+// WithDedupKey drops records whose key fields hold the same values as an
+// earlier record's, keeping the first occurrence. Fields may be dotted paths; a
+// record missing one is an error rather than a match on the missing value.
+func WithDedupKey(fields ...string) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		seen := make(map[string]bool, len(records))
+		unique := make([]Record, 0, len(records))
+		key := make([]interface{}, len(fields))
+		for i, record := range records {
+			for j, field := range fields {
+				value, exists := LookupField(record, field)
+				if !exists {
+					return nil, fmt.Errorf("record %d: dedupe_key field %s is missing", i, field)
+				}
+				key[j] = value
+			}
+			data, err := json.Marshal(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode the key of record %d: %w", i, err)
+			}
+			if seen[string(data)] {
+				continue
+			}
+			seen[string(data)] = true
+			unique = append(unique, record)
+		}
+		return unique, nil
+	})
+}
+
+// WithNormalize applies the schema's per-field normalize transforms on read.
+// JSONSource already normalizes its own records; this is for sources that do not.
+func WithNormalize(schema config.SchemaConfig) Middleware {
+	return readTransform(func(records []Record) ([]Record, error) {
+		for _, record := range records {
+			normalizeRecord(record, schema)
+		}
+		return records, nil
+	})
+}
+
+// WithLogging logs record counts for each read and write; a nil logger uses the standard logger
+func WithLogging(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(src Source) Source {
+		return &loggingSource{src: src, logger: logger}
+	}
+}
+
+type loggingSource struct {
+	src    Source
+	logger *log.Logger
+}
+
+// Read reads records from the wrapped source and logs the count
+func (l *loggingSource) Read(ctx context.Context) ([]Record, error) {
+	start := time.Now()
+	records, err := l.src.Read(ctx)
+	if err != nil {
+		l.logger.Printf("read failed after %s: %v", time.Since(start), err)
+		return nil, err
+	}
+	l.logger.Printf("read %d records in %s", len(records), time.Since(start))
+	return records, nil
+}
+
+// Write writes records to the wrapped source and logs the count
+func (l *loggingSource) Write(ctx context.Context, records []Record) error {
+	if err := l.src.Write(ctx, records); err != nil {
+		l.logger.Printf("write of %d records failed: %v", len(records), err)
+		return err
+	}
+	l.logger.Printf("wrote %d records", len(records))
+	return nil
+}
+
+// Append appends records to the wrapped source and logs the count
+func (l *loggingSource) Append(ctx context.Context, records []Record) error {
+	if err := Append(ctx, l.src, records); err != nil {
+		l.logger.Printf("append of %d records failed: %v", len(records), err)
+		return err
+	}
+	l.logger.Printf("appended %d records", len(records))
+	return nil
+}
+
+// Close closes the wrapped source
+func (l *loggingSource) Close() error {
+	return l.src.Close()
+}
+
+// Capabilities reports the capabilities of the wrapped source
+func (l *loggingSource) Capabilities() Capabilities {
+	return l.src.Capabilities()
+}
+
+// Schema returns the schema of the wrapped source
+func (l *loggingSource) Schema() config.SchemaConfig {
+	return l.src.Schema()
+}
+
+// MiddlewareFor returns the middlewares enabled by a source config. On read,
+// records are filtered, then deduplicated, then sampled, then shuffled, then
+// limited, then sampled down to sample_size.
+func MiddlewareFor(cfg map[string]interface{}) ([]Middleware, error) {
+	var middlewares []Middleware
+
+	logging, err := boolOption(cfg, "log")
+	if err != nil {
+		return nil, err
+	}
+	if logging {
+		middlewares = append(middlewares, WithLogging(nil))
+	}
+
+	size, ok, err := intOption(cfg, "sample_size")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if size < 1 {
+			return nil, fmt.Errorf("sample_size must be positive, got %d", size)
+		}
+		seed, err := sampleSeedOption(cfg)
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, WithSampleSize(size, seed))
+	}
+
+	limit, ok, err := intOption(cfg, "limit")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if limit < 0 {
+			return nil, fmt.Errorf("limit must not be negative, got %d", limit)
+		}
+		middlewares = append(middlewares, WithLimit(limit))
+	}
+
+	seed, err := seedOption(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	shuffle, err := boolOption(cfg, "shuffle")
+	if err != nil {
+		return nil, err
+	}
+	if shuffle {
+		middlewares = append(middlewares, WithShuffle(seed))
+	}
+
+	rate, ok, err := floatOption(cfg, "sample")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("sample must be between 0 and 1, got %v", rate)
+		}
+		middlewares = append(middlewares, WithSample(rate, seed))
+	}
+
+	dedup, err := boolOption(cfg, "dedup")
+	if err != nil {
+		return nil, err
+	}
+	if dedup {
+		middlewares = append(middlewares, WithDedup())
+	}
+
+	keys, err := dedupeKeyOption(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if keys != nil {
+		middlewares = append(middlewares, WithDedupKey(keys...))
+	}
+
+	if raw, exists := cfg["filter"]; exists && raw != nil {
+		expected, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("filter must be a map of field values, got %T", raw)
+		}
+		middlewares = append(middlewares, WithFilter(FieldEquals(expected)))
+	}
+
+	return middlewares, nil
+}
+
+// dedupeKeyOption reads dedupe_key, a field name or a list of field names
+func dedupeKeyOption(cfg map[string]interface{}) ([]string, error) {
+	var keys []string
+	if s, ok := cfg["dedupe_key"].(string); ok {
+		keys = []string{s}
+	} else {
+		var err error
+		if keys, err = stringsOption(cfg, "dedupe_key"); err != nil {
+			return nil, err
+		}
+		if keys == nil {
+			return nil, nil
+		}
+	}
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("dedupe_key field names must not be empty")
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("dedupe_key must name at least one field")
+	}
+	return keys, nil
+}
+
+// sampleSeedOption reads the seed of sample_size: sample_seed, falling back to seed
+func sampleSeedOption(cfg map[string]interface{}) (int64, error) {
+	seed, ok, err := intOption(cfg, "sample_seed")
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return seedOption(cfg)
+	}
+	return int64(seed), nil
+}
+
+// pushdownLimit returns the limit option a source may stop reading at, or -1
+// when it is unset or when filter, dedup, dedupe_key, sample, or shuffle,
+// which apply before it, need every record first
+func pushdownLimit(cfg map[string]interface{}) (int, error) {
+	limit, ok, err := intOption(cfg, "limit")
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return -1, nil
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("limit must not be negative, got %d", limit)
+	}
+	for _, key := range []string{"filter", "dedup", "dedupe_key", "sample", "shuffle"} {
+		if raw, exists := cfg[key]; exists && raw != nil && raw != false {
+			return -1, nil
+		}
+	}
+	return limit, nil
+}
+
+// seedOption reads the seed shared by sampling and shuffling, falling back to
+// a time-based seed when unset
+func seedOption(cfg map[string]interface{}) (int64, error) {
+	seed, ok, err := intOption(cfg, "seed")
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return time.Now().UnixNano(), nil
+	}
+	return int64(seed), nil
+}
@@ -0,0 +1,344 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func labeledRecords() []Record {
+	return []Record{
+		{"id": 1, "label": "positive"},
+		{"id": 2, "label": "negative"},
+		{"id": 3, "label": "positive"},
+		{"id": 4, "label": "positive"},
+		{"id": 5, "label": "negative"},
+		{"id": 6, "label": "positive"},
+	}
+}
+
+func TestMiddleware_LimitAndFilter(t *testing.T) {
+	src := &staticSource{records: labeledRecords()}
+	positive := FieldEquals(map[string]interface{}{"label": "positive"})
+
+	// Limit is outermost, so it applies to the filtered records
+	records, err := Chain(src, WithLimit(2), WithFilter(positive)).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	for i, want := range []int{1, 3} {
+		if records[i]["id"] != want {
+			t.Errorf("Expected record %d to have id %d, got %v", i, want, records[i]["id"])
+		}
+	}
+
+	// Reversed, the filter only sees the first two records
+	records, err = Chain(src, WithFilter(positive), WithLimit(2)).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(records) != 1 || records[0]["id"] != 1 {
+		t.Errorf("Expected only record 1, got %v", records)
+	}
+}
+
+func TestMiddlewareFor_Config(t *testing.T) {
+	records := append(labeledRecords(), Record{"id": 1, "label": "positive"})
+	src := &staticSource{records: records}
+
+	middlewares, err := MiddlewareFor(map[string]interface{}{
+		"limit":  3,
+		"dedup":  true,
+		"filter": map[string]interface{}{"label": "positive"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build middlewares: %v", err)
+	}
+
+	got, err := Chain(src, middlewares...).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(got))
+	}
+	for i, want := range []int{1, 3, 4} {
+		if got[i]["id"] != want {
+			t.Errorf("Expected record %d to have id %d, got %v", i, want, got[i]["id"])
+		}
+	}
+
+	tests := []map[string]interface{}{
+		{"limit": -1},
+		{"sample": 1.5},
+		{"filter": "label"},
+	}
+	for _, cfg := range tests {
+		if _, err := MiddlewareFor(cfg); err == nil {
+			t.Errorf("Expected error for config %v, got nil", cfg)
+		}
+	}
+}
+
+func TestMiddleware_SampleIsSeeded(t *testing.T) {
+	src := &staticSource{records: labeledRecords()}
+	sampled := Chain(src, WithSample(0.5, 42))
+
+	first, err := sampled.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	second, err := sampled.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+
+	if len(first) != len(second) {
+		t.Fatalf("Expected repeated reads to sample the same records, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i]["id"] != second[i]["id"] {
+			t.Errorf("Expected record %d to match, got %v and %v", i, first[i]["id"], second[i]["id"])
+		}
+	}
+}
+
+func TestMiddleware_SeededShuffle(t *testing.T) {
+	src := &staticSource{records: labeledRecords()}
+
+	read := func(seed int64) []int {
+		records, err := Chain(src, WithShuffle(seed)).Read(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		ids := make([]int, len(records))
+		for i, record := range records {
+			ids[i] = record["id"].(int)
+		}
+		return ids
+	}
+
+	first, second := read(7), read(7)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected the same seed to give the same order, got %v and %v", first, second)
+		}
+	}
+
+	changed := false
+	for i, id := range first {
+		if id != i+1 {
+			changed = true
+		}
+	}
+	if !changed {
+		t.Errorf("Expected shuffle to change the order, got %v", first)
+	}
+
+	// The source's own records are left in order
+	if src.records[0]["id"] != 1 || src.records[5]["id"] != 6 {
+		t.Errorf("Expected source records to be unmodified, got %v", src.records)
+	}
+
+	// Shuffle applies before limit, so the subset is drawn from all records
+	middlewares, err := MiddlewareFor(map[string]interface{}{"shuffle": true, "seed": 7, "limit": 3})
+	if err != nil {
+		t.Fatalf("Failed to build middlewares: %v", err)
+	}
+	records, err := Chain(src, middlewares...).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	for i, record := range records {
+		if record["id"] != first[i] {
+			t.Errorf("Expected record %d to have id %d, got %v", i, first[i], record["id"])
+		}
+	}
+}
+
+func TestMiddleware_SampleSize(t *testing.T) {
+	records := make([]Record, 100)
+	for i := range records {
+		records[i] = Record{"id": i}
+	}
+	src := &staticSource{records: records}
+
+	read := func(cfg map[string]interface{}) []int {
+		middlewares, err := MiddlewareFor(cfg)
+		if err != nil {
+			t.Fatalf("Failed to build middlewares: %v", err)
+		}
+		got, err := Chain(src, middlewares...).Read(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		ids := make([]int, len(got))
+		for i, record := range got {
+			ids[i] = record["id"].(int)
+		}
+		return ids
+	}
+
+	first := read(map[string]interface{}{"sample_size": 10, "sample_seed": 3})
+	if len(first) != 10 {
+		t.Fatalf("Expected 10 sampled records, got %d", len(first))
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i] <= first[i-1] {
+			t.Fatalf("Expected the sample in read order without repeats, got %v", first)
+		}
+	}
+	if first[len(first)-1] < 10 {
+		t.Errorf("Expected a random sample rather than the first records, got %v", first)
+	}
+
+	// sample_seed falls back to seed
+	second := read(map[string]interface{}{"sample_size": 10, "seed": 3})
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Errorf("Expected the same seed to sample the same records, got %v and %v", first, second)
+	}
+
+	if all := read(map[string]interface{}{"sample_size": 500, "sample_seed": 3}); len(all) != 100 {
+		t.Errorf("Expected every record when sample_size exceeds the count, got %d", len(all))
+	}
+
+	// limit applies first, so the sample is drawn from the first 20 records
+	for _, id := range read(map[string]interface{}{"limit": 20, "sample_size": 5, "sample_seed": 3}) {
+		if id >= 20 {
+			t.Errorf("Expected a sample of the limited records, got id %d", id)
+		}
+	}
+
+	for _, cfg := range []map[string]interface{}{{"sample_size": 0}, {"sample_size": 5, "sample_seed": "x"}} {
+		if _, err := MiddlewareFor(cfg); err == nil {
+			t.Errorf("Expected error for config %v, got nil", cfg)
+		}
+	}
+}
+
+func TestMiddleware_SampleSizeIsUniform(t *testing.T) {
+	records := make([]Record, 10)
+	for i := range records {
+		records[i] = Record{"id": i}
+	}
+	src := &staticSource{records: records}
+
+	counts := make([]int, len(records))
+	for seed := int64(0); seed < 2000; seed++ {
+		sampled, err := Chain(src, WithSampleSize(3, seed)).Read(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		for _, record := range sampled {
+			counts[record["id"].(int)]++
+		}
+	}
+	// Each record is expected in 600 of the 2000 samples
+	for id, count := range counts {
+		if count < 500 || count > 700 {
+			t.Errorf("Expected record %d to be sampled about 600 times, got %d", id, count)
+		}
+	}
+}
+
+func TestMiddleware_DedupKey(t *testing.T) {
+	records := []Record{
+		{"id": "a", "split": "train", "text": "first"},
+		{"id": "b", "split": "train", "text": "second"},
+		{"id": "a", "split": "test", "text": "third"},
+		{"id": "a", "split": "train", "text": "fourth"},
+	}
+
+	tests := []struct {
+		key  interface{}
+		want []string
+	}{
+		{"id", []string{"first", "second"}},
+		{[]interface{}{"id", "split"}, []string{"first", "second", "third"}},
+	}
+
+	for _, tt := range tests {
+		middlewares, err := MiddlewareFor(map[string]interface{}{"dedupe_key": tt.key})
+		if err != nil {
+			t.Fatalf("Failed to build middlewares: %v", err)
+		}
+		got, err := Chain(&staticSource{records: records}, middlewares...).Read(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		var texts []string
+		for _, record := range got {
+			texts = append(texts, record["text"].(string))
+		}
+		if fmt.Sprint(texts) != fmt.Sprint(tt.want) {
+			t.Errorf("Expected dedupe_key %v to keep %v, got %v", tt.key, tt.want, texts)
+		}
+	}
+
+	missing := append(records, Record{"split": "train", "text": "no id"})
+	_, err := Chain(&staticSource{records: missing}, WithDedupKey("id")).Read(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "record 4: dedupe_key field id is missing") {
+		t.Errorf("Expected a missing key field error, got %v", err)
+	}
+
+	for _, key := range []interface{}{"", []interface{}{}, []interface{}{"id", 1}, 7} {
+		if _, err := MiddlewareFor(map[string]interface{}{"dedupe_key": key}); err == nil {
+			t.Errorf("Expected error for dedupe_key %v, got nil", key)
+		}
+	}
+}
+
+func TestJSONSource_DedupKeyAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"part-1.jsonl": "{\"id\": \"1\", \"text\": \"one\"}\n{\"id\": \"2\", \"text\": \"two\"}\n",
+		"part-2.jsonl": "{\"id\": \"2\", \"text\": \"two again\"}\n{\"id\": \"3\", \"text\": \"three\"}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := map[string]interface{}{"path": filepath.Join(dir, "*.jsonl"), "mode": "lines", "dedupe_key": "id", "limit": 2}
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}, {Name: "text", Type: "string"}}}
+	source, err := NewDefaultFactory().CreateSource(cfg, "json", schema)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	defer source.Close()
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	// The limit applies after deduplication, so the duplicate does not use up a slot
+	if len(records) != 2 || records[0]["text"] != "one" || records[1]["text"] != "two" {
+		t.Errorf("Expected the first occurrences of ids 1 and 2, got %v", records)
+	}
+
+	cfg["limit"] = 3
+	source, err = NewDefaultFactory().CreateSource(cfg, "json", schema)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	defer source.Close()
+	records, err = source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(records) != 3 || records[2]["text"] != "three" {
+		t.Errorf("Expected the duplicate id 2 dropped, got %v", records)
+	}
+}
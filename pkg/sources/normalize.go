@@ -0,0 +1,46 @@
+package sources
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// whitespaceRun matches consecutive whitespace characters
+var whitespaceRun = regexp.MustCompile(`\s+`)
+
+// normalizeRecord applies each field's normalize transforms to string values in place
+func normalizeRecord(record Record, schema config.SchemaConfig) {
+	for _, field := range schema.Fields {
+		if len(field.Normalize) == 0 {
+			continue
+		}
+
+		value, ok := record[field.Name].(string)
+		if !ok {
+			continue
+		}
+
+		for _, transform := range field.Normalize {
+			value = applyTransform(value, transform)
+		}
+		record[field.Name] = value
+	}
+}
+
+// applyTransform applies a single named string transform
+func applyTransform(value string, transform string) string {
+	switch transform {
+	case "trim":
+		return strings.TrimSpace(value)
+	case "lower":
+		return strings.ToLower(value)
+	case "upper":
+		return strings.ToUpper(value)
+	case "collapse_spaces":
+		return whitespaceRun.ReplaceAllString(value, " ")
+	default:
+		return value
+	}
+}
@@ -0,0 +1,86 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestNormalize_Transforms(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		transforms []string
+		expected   string
+	}{
+		{"trim", "  positive \n", []string{"trim"}, "positive"},
+		{"lower", "Positive", []string{"lower"}, "positive"},
+		{"upper", "positive", []string{"upper"}, "POSITIVE"},
+		{"collapse spaces", "very   \t positive", []string{"collapse_spaces"}, "very positive"},
+		{"trim then lower", " Positive ", []string{"trim", "lower"}, "positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := Record{"label": tt.value}
+			schema := config.SchemaConfig{
+				Fields: []config.FieldConfig{{Name: "label", Type: "string", Normalize: tt.transforms}},
+			}
+
+			normalizeRecord(record, schema)
+
+			if record["label"] != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, record["label"])
+			}
+		})
+	}
+}
+
+func TestJSONSource_NormalizeOnRead(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.jsonl")
+
+	if err := os.WriteFile(testFile, []byte(`{"predicted_sentiment": " Positive ", "count": 1}`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "predicted_sentiment", Type: "string", Normalize: []string{"trim", "lower"}, Enum: []string{"positive", "negative", "neutral"}},
+			{Name: "count", Type: "number", Normalize: []string{"trim"}},
+		},
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": testFile, "mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	// The enum check runs on the normalized value, so " Positive " passes
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	if records[0]["predicted_sentiment"] != "positive" {
+		t.Errorf("Expected normalized value 'positive', got %q", records[0]["predicted_sentiment"])
+	}
+
+	if records[0]["count"] != 1.0 {
+		t.Errorf("Expected non-string value to be left untouched, got %v", records[0]["count"])
+	}
+
+	// Without normalizing, the raw value is not in the enum
+	schema.Fields[0].Normalize = nil
+	source, err = NewJSONSource(map[string]interface{}{"path": testFile, "mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "not in enum") {
+		t.Errorf("Expected the untrimmed value to fail the enum check, got %v", err)
+	}
+}
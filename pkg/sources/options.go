@@ -0,0 +1,167 @@
+package sources
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// intOption reads an integer option from a source config map.
+// YAML and JSON decoders produce different numeric types, so all of them are accepted.
+func intOption(cfg map[string]interface{}, key string) (int, bool, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return 0, false, nil
+	}
+
+	switch v := raw.(type) {
+	case int:
+		return v, true, nil
+	case int64:
+		return int(v), true, nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false, fmt.Errorf("%s must be an integer, got %v", key, v)
+		}
+		return int(v), true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be an integer, got %T", key, raw)
+	}
+}
+
+// choiceOption reads a string option that must be one of allowed, returning def when unset
+func choiceOption(cfg map[string]interface{}, key string, def string, allowed ...string) (string, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return def, nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %T", key, raw)
+	}
+	if value == "" {
+		return def, nil
+	}
+
+	for _, a := range allowed {
+		if value == a {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported %s: %s (must be one of %s)", key, value, strings.Join(allowed, ", "))
+}
+
+// boolOption reads a boolean option, returning false when unset
+func boolOption(cfg map[string]interface{}, key string) (bool, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return false, nil
+	}
+
+	value, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean, got %T", key, raw)
+	}
+	return value, nil
+}
+
+// floatOption reads a numeric option as a float64
+func floatOption(cfg map[string]interface{}, key string) (float64, bool, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return 0, false, nil
+	}
+
+	switch v := raw.(type) {
+	case float64:
+		return v, true, nil
+	case int:
+		return float64(v), true, nil
+	case int64:
+		return float64(v), true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be a number, got %T", key, raw)
+	}
+}
+
+// stringsOption reads a list of strings, returning nil when unset
+func stringsOption(cfg map[string]interface{}, key string) ([]string, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	switch v := raw.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings, got %T at index %d", key, item, i)
+			}
+			values[i] = s
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings, got %T", key, raw)
+	}
+}
+
+// stringMapOption reads a map of string values, returning nil when unset
+func stringMapOption(cfg map[string]interface{}, key string) (map[string]string, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	switch v := raw.(type) {
+	case map[string]string:
+		return v, nil
+	case map[string]interface{}:
+		values := make(map[string]string, len(v))
+		for name, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a map of strings, got %T for %s", key, item, name)
+			}
+			values[name] = s
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%s must be a map of strings, got %T", key, raw)
+	}
+}
+
+// durationOption reads a duration such as "30s"; plain numbers are seconds
+func durationOption(cfg map[string]interface{}, key string) (time.Duration, bool, error) {
+	raw, exists := cfg[key]
+	if !exists || raw == nil {
+		return 0, false, nil
+	}
+
+	var value time.Duration
+	switch v := raw.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s must be a duration such as 30s, got %q", key, v)
+		}
+		value = parsed
+	case time.Duration:
+		value = v
+	default:
+		seconds, _, err := floatOption(cfg, key)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s must be a duration such as 30s, got %T", key, raw)
+		}
+		value = time.Duration(seconds * float64(time.Second))
+	}
+
+	if value < 0 {
+		return 0, false, fmt.Errorf("%s must not be negative, got %s", key, value)
+	}
+	return value, true, nil
+}
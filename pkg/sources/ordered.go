@@ -0,0 +1,47 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckDeterministic rejects source configs whose record order depends on the
+// environment rather than the data: files sorted by modification time, and
+// shuffle or sample without an explicit seed (which default to the clock), and
+// sample_size without a sample_seed or seed
+func CheckDeterministic(cfg map[string]interface{}) error {
+	if sortBy, _ := cfg["sort"].(string); sortBy == SortByModTime {
+		return fmt.Errorf("sort: %s depends on file modification times; use sort: %s", SortByModTime, SortByName)
+	}
+
+	if _, seeded := cfg["seed"]; !seeded {
+		if shuffle, _ := cfg["shuffle"].(bool); shuffle {
+			return fmt.Errorf("shuffle requires a seed")
+		}
+		if _, sampled := cfg["sample"]; sampled {
+			return fmt.Errorf("sample requires a seed")
+		}
+		if _, sampled := cfg["sample_size"]; sampled {
+			if _, ok := cfg["sample_seed"]; !ok {
+				return fmt.Errorf("sample_size requires a sample_seed or seed")
+			}
+		}
+	}
+	return nil
+}
+
+// OrderedRead reads every record of src in a stable order, the same on every
+// run and platform, after checking the config src was created from with
+// CheckDeterministic. The order is:
+//
+//  1. matched files in lexical order of their slash-separated paths, with
+//     recursive directories walked in lexical order
+//  2. each file's records in file order
+//  3. the read middlewares in the order MiddlewareFor documents, where a
+//     seeded shuffle or sample uses math/rand's stable generator
+func OrderedRead(ctx context.Context, src Source, cfg map[string]interface{}) ([]Record, error) {
+	if err := CheckDeterministic(cfg); err != nil {
+		return nil, fmt.Errorf("nondeterministic read: %w", err)
+	}
+	return src.Read(ctx)
+}
@@ -0,0 +1,99 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestCheckDeterministic(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{"plain", map[string]interface{}{"path": "data/*.json"}, ""},
+		{"name sort", map[string]interface{}{"sort": "name"}, ""},
+		{"seeded shuffle", map[string]interface{}{"shuffle": true, "seed": 7}, ""},
+		{"seeded sample", map[string]interface{}{"sample": 0.5, "seed": 7}, ""},
+		{"mtime sort", map[string]interface{}{"sort": "mtime"}, "sort: mtime"},
+		{"unseeded shuffle", map[string]interface{}{"shuffle": true}, "shuffle requires a seed"},
+		{"unseeded sample", map[string]interface{}{"sample": 0.5}, "sample requires a seed"},
+		{"sample_seed", map[string]interface{}{"sample_size": 10, "sample_seed": 7}, ""},
+		{"seeded sample_size", map[string]interface{}{"sample_size": 10, "seed": 7}, ""},
+		{"unseeded sample_size", map[string]interface{}{"sample_size": 10}, "sample_size requires a sample_seed or seed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckDeterministic(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOrderedRead(t *testing.T) {
+	dir := t.TempDir()
+	// Written in reverse so creation order differs from name order
+	for _, name := range []string{"c.jsonl", "b/a.jsonl", "a.jsonl"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		content := fmt.Sprintf("{\"file\": %q, \"n\": 1}\n{\"file\": %q, \"n\": 2}\n", name, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	read := func(cfg map[string]interface{}) []Record {
+		t.Helper()
+		src, err := NewDefaultFactory().CreateSource(cfg, "json", config.SchemaConfig{})
+		if err != nil {
+			t.Fatalf("Failed to create source: %v", err)
+		}
+		defer src.Close()
+		records, err := OrderedRead(context.Background(), src, cfg)
+		if err != nil {
+			t.Fatalf("Failed to read records: %v", err)
+		}
+		return records
+	}
+
+	cfg := map[string]interface{}{"path": dir, "recursive": true, "mode": "lines"}
+	var got []string
+	for _, record := range read(cfg) {
+		got = append(got, fmt.Sprintf("%s:%v", record["file"], record["n"]))
+	}
+	want := []string{"a.jsonl:1", "a.jsonl:2", "b/a.jsonl:1", "b/a.jsonl:2", "c.jsonl:1", "c.jsonl:2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	shuffled := map[string]interface{}{"path": dir, "recursive": true, "mode": "lines", "shuffle": true, "seed": 42}
+	first := read(shuffled)
+	if second := read(shuffled); !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the seeded shuffle to repeat, got %v and %v", first, second)
+	}
+
+	shuffled["sort"] = "mtime"
+	src, err := NewDefaultFactory().CreateSource(shuffled, "json", config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if _, err := OrderedRead(context.Background(), src, shuffled); err == nil || !strings.Contains(err.Error(), "nondeterministic read") {
+		t.Errorf("Expected sort: mtime to be rejected, got %v", err)
+	}
+}
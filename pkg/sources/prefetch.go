@@ -0,0 +1,88 @@
+package sources
+
+import (
+	"context"
+	"io/fs"
+	"sync"
+)
+
+// fileResult holds the records decoded from one file and the error that ended its read
+type fileResult struct {
+	records []Record
+	err     error
+	done    chan struct{} // closed once the file is read
+}
+
+// filePrefetcher decodes matched files on a pool of workers, ahead of the
+// loop that passes their records on in file-match order
+type filePrefetcher struct {
+	results []*fileResult
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// prefetchFiles starts up to concurrency workers reading files in match order
+func (j *JSONSource) prefetchFiles(ctx context.Context, fsys fs.FS, files []string, concurrency int) *filePrefetcher {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &filePrefetcher{results: make([]*fileResult, len(files)), cancel: cancel}
+	for i := range p.results {
+		p.results[i] = &fileResult{done: make(chan struct{})}
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range files {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	workers := min(concurrency, len(files))
+	p.workers.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer p.workers.Done()
+			for i := range jobs {
+				result := p.results[i]
+				result.err = j.readFile(fsys, files[i], func(record Record) error {
+					// Abort files part way once the read is cancelled or has failed
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					result.records = append(result.records, record)
+					return nil
+				})
+				close(result.done)
+			}
+		}()
+	}
+	return p
+}
+
+// replay waits for file i to be read, then emits its records and returns its read error
+func (p *filePrefetcher) replay(ctx context.Context, i int, emit func(Record) error) error {
+	result := p.results[i]
+	select {
+	case <-result.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	p.results[i] = nil // release the records once passed on
+
+	for _, record := range result.records {
+		if err := emit(record); err != nil {
+			return err
+		}
+	}
+	return result.err
+}
+
+// stop cancels the files not yet read and waits for the workers to return
+func (p *filePrefetcher) stop() {
+	p.cancel()
+	p.workers.Wait()
+}
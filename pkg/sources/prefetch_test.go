@@ -0,0 +1,156 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func newShardsTestFS(files, perFile int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for f := 0; f < files; f++ {
+		var lines strings.Builder
+		for r := 0; r < perFile; r++ {
+			fmt.Fprintf(&lines, "{\"id\": \"%03d-%d\", \"score\": %d}\n", f, r, r)
+		}
+		fsys[fmt.Sprintf("shards/%03d.jsonl", f)] = &fstest.MapFile{Data: []byte(lines.String())}
+	}
+	return fsys
+}
+
+func TestJSONSource_ReadConcurrencyPreservesOrder(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}, {Name: "score", Type: "number"}}}
+	fsys := newShardsTestFS(40, 25)
+
+	read := func(concurrency int) ([]Record, *JSONSource) {
+		source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", map[string]interface{}{"mode": "auto", "read_concurrency": concurrency}, schema)
+		if err != nil {
+			t.Fatalf("Failed to create JSON source: %v", err)
+		}
+		records, err := source.Read(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to read with read_concurrency %d: %v", concurrency, err)
+		}
+		return records, source
+	}
+
+	serial, serialSource := read(1)
+	if len(serial) != 1000 {
+		t.Fatalf("Expected 1000 records, got %d", len(serial))
+	}
+	for _, concurrency := range []int{2, 8, 64} {
+		records, source := read(concurrency)
+		if !reflect.DeepEqual(records, serial) {
+			t.Errorf("Expected read_concurrency %d to return records in file-match order", concurrency)
+		}
+		if !reflect.DeepEqual(source.ObservedTypes(), serialSource.ObservedTypes()) {
+			t.Errorf("Expected the same observed types, got %v and %v", source.ObservedTypes(), serialSource.ObservedTypes())
+		}
+		if len(source.DetectedModes()) != 40 {
+			t.Errorf("Expected a detected mode per file, got %d", len(source.DetectedModes()))
+		}
+	}
+}
+
+func TestJSONSource_ReadConcurrencyErrors(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}}}
+	fsys := newShardsTestFS(20, 3)
+	fsys["shards/007.jsonl"] = &fstest.MapFile{Data: []byte("{\"id\": \"ok\"}\n{\"id\": 7}\n")}
+	fsys["shards/012.jsonl"] = &fstest.MapFile{Data: []byte("{\"id\": \n")}
+
+	cfg := map[string]interface{}{"mode": "lines", "read_concurrency": 4}
+	source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	// The first failing file in match order fails the read, as when reading serially
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to read file shards/007.jsonl: line 2") {
+		t.Errorf("Expected the read to fail on shards/007.jsonl, got %v", err)
+	}
+
+	cfg["continue_on_file_error"] = true
+	tolerant, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", cfg, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	records, err := tolerant.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 18*3 || records[0]["id"] != "000-0" || records[len(records)-1]["id"] != "019-2" {
+		t.Errorf("Expected the records of the 18 good files in order, got %d records", len(records))
+	}
+	failed := tolerant.FailedFiles()
+	if len(failed) != 2 || failed[0].Path != "shards/007.jsonl" || failed[1].Path != "shards/012.jsonl" {
+		t.Errorf("Expected the two bad files in match order, got %v", failed)
+	}
+}
+
+// blockingFS holds the first Open of one file, made once the file is read, until released
+type blockingFS struct {
+	fs.FS
+	name     string
+	once     sync.Once
+	opened   chan struct{}
+	released chan struct{}
+}
+
+func (b *blockingFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(b.FS, name)
+}
+
+func (b *blockingFS) Open(name string) (fs.File, error) {
+	if name == b.name {
+		b.once.Do(func() {
+			close(b.opened)
+			<-b.released
+		})
+	}
+	return b.FS.Open(name)
+}
+
+func TestJSONSource_ReadConcurrencyCancel(t *testing.T) {
+	fsys := &blockingFS{
+		FS:       newShardsTestFS(20, 3),
+		name:     "shards/000.jsonl",
+		opened:   make(chan struct{}),
+		released: make(chan struct{}),
+	}
+	source, err := NewJSONSourceFromFS(fsys, "shards/*.jsonl", map[string]interface{}{"mode": "lines", "read_concurrency": 4}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		records, err := source.Read(ctx)
+		if len(records) != 0 {
+			err = fmt.Errorf("expected no records, got %d", len(records))
+		}
+		done <- err
+	}()
+
+	<-fsys.opened
+	cancel()
+	close(fsys.released)
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled read to fail with context.Canceled, got %v", err)
+	}
+}
+
+func TestJSONSource_ReadConcurrencyOption(t *testing.T) {
+	for _, value := range []interface{}{0, -2, "many"} {
+		if _, err := NewJSONSource(map[string]interface{}{"path": "in.json", "read_concurrency": value}, config.SchemaConfig{}); err == nil {
+			t.Errorf("Expected error for read_concurrency %v, got nil", value)
+		}
+	}
+}
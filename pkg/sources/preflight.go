@@ -0,0 +1,38 @@
+package sources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CheckWritable verifies that the file at path can be created by creating its
+// directory and writing and removing a probe file next to it
+func CheckWritable(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".meval-probe-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	probePath := probe.Name()
+
+	_, writeErr := probe.Write([]byte("probe"))
+	closeErr := probe.Close()
+	removeErr := os.Remove(probePath)
+
+	if writeErr != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, closeErr)
+	}
+	if removeErr != nil {
+		return fmt.Errorf("failed to remove probe file %s: %w", probePath, removeErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritable(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "nested", "output.json")
+
+	if err := CheckWritable(target); err != nil {
+		t.Fatalf("Expected directory to be writable, got %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(target))
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected probe file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestCheckWritable_ReadOnlyDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root bypasses directory permissions")
+	}
+
+	tmpDir := t.TempDir()
+	readOnly := filepath.Join(tmpDir, "readonly")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatalf("Failed to create read-only directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(readOnly, 0755) })
+
+	if err := CheckWritable(filepath.Join(readOnly, "output.json")); err == nil {
+		t.Error("Expected error for read-only directory, got nil")
+	}
+}
+
+func TestCheckWritable_ParentIsFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	parent := filepath.Join(tmpDir, "file")
+	if err := os.WriteFile(parent, []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	if err := CheckWritable(filepath.Join(parent, "output.json")); err == nil {
+		t.Error("Expected error when parent is a file, got nil")
+	}
+}
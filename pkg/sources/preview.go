@@ -0,0 +1,99 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// DefaultPreviewFields is how many fields a record preview shows when preview_fields is unset
+const DefaultPreviewFields = 3
+
+// previewValueLength caps each previewed value so large fields do not flood errors
+const previewValueLength = 40
+
+// redactedValue replaces pii fields in record previews
+const redactedValue = "[redacted]"
+
+// recordPreview renders a short, identifying preview of a failing record for
+// validation errors, hiding schema fields marked pii
+type recordPreview struct {
+	enabled bool
+	fields  []string // fields to show; the first DefaultPreviewFields by name when empty
+	pii     map[string]bool
+}
+
+// newRecordPreview reads the error_preview and preview_fields options; setting
+// preview_fields enables the preview on its own
+func newRecordPreview(cfg map[string]interface{}, schema config.SchemaConfig) (recordPreview, error) {
+	enabled, err := boolOption(cfg, "error_preview")
+	if err != nil {
+		return recordPreview{}, err
+	}
+
+	fields, err := stringsOption(cfg, "preview_fields")
+	if err != nil {
+		return recordPreview{}, err
+	}
+
+	pii := make(map[string]bool)
+	for _, field := range schema.Fields {
+		if field.PII {
+			pii[field.Name] = true
+		}
+	}
+
+	return recordPreview{enabled: enabled || len(fields) > 0, fields: fields, pii: pii}, nil
+}
+
+// wrap appends the record preview to err when previews are enabled
+func (p recordPreview) wrap(err error, record Record) error {
+	if err == nil || !p.enabled {
+		return err
+	}
+	return fmt.Errorf("%w (record: %s)", err, p.render(record))
+}
+
+// render formats the previewed fields as {name: value, ...}
+func (p recordPreview) render(record Record) string {
+	fields := p.fields
+	if len(fields) == 0 {
+		for name := range record {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		if len(fields) > DefaultPreviewFields {
+			fields = fields[:DefaultPreviewFields]
+		}
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, name := range fields {
+		value, ok := record[name]
+		if !ok {
+			continue
+		}
+		parts = append(parts, name+": "+p.formatValue(name, value))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// formatValue renders a single value as JSON, redacted or truncated as needed
+func (p recordPreview) formatValue(name string, value interface{}) string {
+	if p.pii[name] {
+		return redactedValue
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	text := string(data)
+	if runes := []rune(text); len(runes) > previewValueLength {
+		text = string(runes[:previewValueLength]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,71 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestJSONSource_ErrorPreview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.jsonl")
+	data := `{"id": 1, "email": "a@example.com", "age": 30}
+{"id": 2, "email": "b@example.com", "age": "unknown"}
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "id", Type: "number"},
+		{Name: "email", Type: "email", PII: true},
+		{Name: "age", Type: "number"},
+	}}
+
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		want    string
+		wantNot string
+	}{
+		{"disabled", map[string]interface{}{}, "", "record:"},
+		{"first fields", map[string]interface{}{"error_preview": true}, `(record: {age: "unknown", email: [redacted], id: 2})`, "b@example.com"},
+		{"configured fields", map[string]interface{}{"preview_fields": []interface{}{"id", "email"}}, `(record: {id: 2, email: [redacted]})`, "b@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]interface{}{"path": path, "mode": "lines"}
+			for k, v := range tt.cfg {
+				cfg[k] = v
+			}
+			source, err := NewJSONSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+
+			_, err = source.Read(context.Background())
+			if err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+			if tt.want != "" && !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error to contain %q, got %v", tt.want, err)
+			}
+			if strings.Contains(err.Error(), tt.wantNot) {
+				t.Errorf("Expected error not to contain %q, got %v", tt.wantNot, err)
+			}
+		})
+	}
+}
+
+func TestRecordPreview_TruncatesLongValues(t *testing.T) {
+	preview := recordPreview{enabled: true, fields: []string{"text"}}
+
+	got := preview.render(Record{"text": strings.Repeat("x", 100)})
+	want := `{text: "` + strings.Repeat("x", previewValueLength-1) + `...}`
+	if got != want {
+		t.Errorf("Expected a truncated value, got %s", got)
+	}
+}
@@ -0,0 +1,386 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Object describes an object returned by ListObjects or HeadObject
+type S3Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// S3Client is the subset of the S3 API used by S3Source. Missing objects are
+// reported as errors wrapping fs.ErrNotExist.
+type S3Client interface {
+	MultipartClient
+	// ListObjects lists the objects under prefix; with a delimiter, keys sharing
+	// a prefix up to the next delimiter are returned once as common prefixes
+	ListObjects(ctx context.Context, bucket, prefix, delimiter string) (objects []S3Object, prefixes []string, err error)
+	HeadObject(ctx context.Context, bucket, key string) (S3Object, error)
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+}
+
+// httpS3Client calls the S3 REST API, signing requests with AWS Signature Version 4
+type httpS3Client struct {
+	httpClient *http.Client
+	endpoint   *url.URL // custom endpoint, e.g. a MinIO server; nil for AWS
+	region     string
+	creds      awsCredentials
+	now        func() time.Time
+}
+
+// newHTTPS3Client creates a client for region, using endpoint instead of AWS when not empty
+func newHTTPS3Client(endpoint, region string, creds awsCredentials) (*httpS3Client, error) {
+	client := &httpS3Client{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		region:     region,
+		creds:      creds,
+		now:        time.Now,
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+		}
+		client.endpoint = u
+	}
+	return client, nil
+}
+
+// objectURL returns the URL of key in bucket with the given query. AWS buckets
+// are addressed virtual-hosted style unless their name holds dots; custom
+// endpoints use path style.
+func (c *httpS3Client) objectURL(bucket, key string, query url.Values) string {
+	scheme, host, path := "https", "", "/"
+	switch {
+	case c.endpoint != nil:
+		scheme, host = c.endpoint.Scheme, c.endpoint.Host
+		path = strings.TrimSuffix(c.endpoint.Path, "/") + "/" + bucket + "/"
+	case strings.Contains(bucket, "."):
+		host = "s3." + c.region + ".amazonaws.com"
+		path = "/" + bucket + "/"
+	default:
+		host = bucket + ".s3." + c.region + ".amazonaws.com"
+	}
+	path += s3Escape(key, false)
+
+	u := scheme + "://" + host + path
+	if len(query) > 0 {
+		u += "?" + canonicalQuery(query)
+	}
+	return u
+}
+
+// do signs and sends a request, returning the response when its status is 2xx
+func (c *httpS3Client) do(ctx context.Context, method, bucket, key string, query url.Values, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(bucket, key, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	signV4(req, c.creds, c.region, payloadHash, c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, s3ResponseError(resp, bucket, key)
+	}
+	return resp, nil
+}
+
+// s3Error is the error document S3 returns
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3ResponseError describes a failed response, wrapping fs.ErrNotExist for missing objects
+func s3ResponseError(resp *http.Response, bucket, key string) error {
+	var doc s3Error
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	xml.Unmarshal(data, &doc)
+
+	if resp.StatusCode == http.StatusNotFound && (doc.Code == "" || doc.Code == "NoSuchKey") {
+		return fmt.Errorf("s3://%s/%s: %w", bucket, key, fs.ErrNotExist)
+	}
+	if doc.Code == "" {
+		return fmt.Errorf("s3://%s/%s: status %d", bucket, key, resp.StatusCode)
+	}
+	return fmt.Errorf("s3://%s/%s: %s: %s (status %d)", bucket, key, doc.Code, doc.Message, resp.StatusCode)
+}
+
+// listBucketResult is the ListObjectsV2 response document
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
+
+// ListObjects lists the objects under prefix with ListObjectsV2, following continuation tokens
+func (c *httpS3Client) ListObjects(ctx context.Context, bucket, prefix, delimiter string) ([]S3Object, []string, error) {
+	var objects []S3Object
+	var prefixes []string
+
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	for {
+		resp, err := c.do(ctx, http.MethodGet, bucket, "", query, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode object listing: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			objects = append(objects, S3Object{Key: object.Key, Size: object.Size, LastModified: object.LastModified})
+		}
+		for _, common := range result.CommonPrefixes {
+			prefixes = append(prefixes, common.Prefix)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, prefixes, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// HeadObject returns the size and modification time of an object
+func (c *httpS3Client) HeadObject(ctx context.Context, bucket, key string) (S3Object, error) {
+	resp, err := c.do(ctx, http.MethodHead, bucket, key, nil, nil)
+	if err != nil {
+		return S3Object{}, err
+	}
+	resp.Body.Close()
+
+	object := S3Object{Key: key, Size: resp.ContentLength}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = modified
+	}
+	return object, nil
+}
+
+// GetObject returns the body of an object, which the caller must close
+func (c *httpS3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// CreateMultipartUpload starts a multipart upload and returns its ID
+func (c *httpS3Client) CreateMultipartUpload(ctx context.Context, bucket, key string) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode upload: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("response has no upload ID")
+	}
+	return result.UploadID, nil
+}
+
+// UploadPart uploads one part of a multipart upload and returns its ETag
+func (c *httpS3Client) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	resp, err := c.do(ctx, http.MethodPut, bucket, key, query, body)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+// completeMultipartUpload is the CompleteMultipartUpload request document
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the object
+func (c *httpS3Client) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []CompletedPart) error {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("failed to encode parts: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 can report a failed completion in the body of a 200 response
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var failure s3Error
+	if xml.Unmarshal(data, &failure) == nil && failure.Code != "" {
+		return fmt.Errorf("s3://%s/%s: %s: %s", bucket, key, failure.Code, failure.Message)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts
+func (c *httpS3Client) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, bucket, key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// signV4 adds AWS Signature Version 4 headers to req. The host, Content-Type,
+// Content-MD5, Range, and every x-amz-* header are signed.
+func signV4(req *http.Request, creds awsCredentials, region, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "content-md5" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by key, as Signature Version 4 requires
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent-encodes every byte but the RFC 3986 unreserved characters,
+// and slashes unless encodeSlash is set
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
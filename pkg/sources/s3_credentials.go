@@ -0,0 +1,133 @@
+package sources
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultS3Region is used when neither the config nor the AWS environment sets a region
+const defaultS3Region = "us-east-1"
+
+// awsCredentials are the keys S3 requests are signed with
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // set for temporary credentials
+}
+
+// loadAWSCredentials resolves credentials the way the AWS CLI does for static
+// keys: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY (with AWS_SESSION_TOKEN),
+// then the AWS_PROFILE profile (default "default") of the shared credentials
+// file, AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials
+func loadAWSCredentials() (awsCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	path := awsFile("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+	profile := awsProfile()
+	values, err := readAWSProfile(path, profile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	creds := awsCredentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("no AWS credentials found: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or profile %s in %s", profile, path)
+	}
+	return creds, nil
+}
+
+// resolveS3Region returns region when set, otherwise AWS_REGION, AWS_DEFAULT_REGION,
+// the profile's region in the shared config file, or us-east-1
+func resolveS3Region(region string) string {
+	if region != "" {
+		return region
+	}
+	if region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+
+	// The config file names profiles other than default "profile <name>"
+	section := awsProfile()
+	if section != "default" {
+		section = "profile " + section
+	}
+	values, _ := readAWSProfile(awsFile("AWS_CONFIG_FILE", "config"), section)
+	if values["region"] != "" {
+		return values["region"]
+	}
+	return defaultS3Region
+}
+
+// firstEnv returns the value of the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// awsProfile returns the profile named by AWS_PROFILE, or default
+func awsProfile() string {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// awsFile returns the path named by the environment variable env, or name under ~/.aws
+func awsFile(env, name string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// readAWSProfile returns the keys of section in an AWS INI file. A missing
+// file has no sections.
+func readAWSProfile(path, section string) (map[string]string, error) {
+	values := make(map[string]string)
+	if path == "" {
+		return values, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			current = strings.TrimSpace(line[1 : len(line)-1])
+		case current == section:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return values, scanner.Err()
+}
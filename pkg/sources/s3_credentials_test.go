@@ -0,0 +1,87 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearAWSEnv unsets the AWS environment and points the shared files into a temp directory
+func clearAWSEnv(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	return dir
+}
+
+func TestLoadAWSCredentials(t *testing.T) {
+	dir := clearAWSEnv(t)
+
+	if _, err := loadAWSCredentials(); err == nil || !strings.Contains(err.Error(), "no AWS credentials found") {
+		t.Errorf("Expected missing credentials to fail, got %v", err)
+	}
+
+	credentials := "# shared credentials\n[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = default-secret\n\n" +
+		"[ci]\nAWS_ACCESS_KEY_ID=AKIDCI\naws_secret_access_key=ci-secret\naws_session_token=ci-token\n"
+	if err := os.WriteFile(filepath.Join(dir, "credentials"), []byte(credentials), 0600); err != nil {
+		t.Fatalf("Failed to create credentials file: %v", err)
+	}
+
+	creds, err := loadAWSCredentials()
+	if err != nil {
+		t.Fatalf("Failed to load credentials: %v", err)
+	}
+	if creds != (awsCredentials{AccessKeyID: "AKIDDEFAULT", SecretAccessKey: "default-secret"}) {
+		t.Errorf("Expected the default profile, got %+v", creds)
+	}
+
+	t.Setenv("AWS_PROFILE", "ci")
+	if creds, _ := loadAWSCredentials(); creds != (awsCredentials{AccessKeyID: "AKIDCI", SecretAccessKey: "ci-secret", SessionToken: "ci-token"}) {
+		t.Errorf("Expected the ci profile, got %+v", creds)
+	}
+
+	// The environment wins over the shared file
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	if creds, _ := loadAWSCredentials(); creds.AccessKeyID != "AKIDENV" || creds.SessionToken != "" {
+		t.Errorf("Expected the environment credentials, got %+v", creds)
+	}
+}
+
+func TestResolveS3Region(t *testing.T) {
+	dir := clearAWSEnv(t)
+
+	if got := resolveS3Region(""); got != defaultS3Region {
+		t.Errorf("Expected %s without configuration, got %s", defaultS3Region, got)
+	}
+
+	config := "[default]\nregion = eu-central-1\n[profile ci]\nregion = ap-south-1\n"
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	if got := resolveS3Region(""); got != "eu-central-1" {
+		t.Errorf("Expected the default profile region, got %s", got)
+	}
+	t.Setenv("AWS_PROFILE", "ci")
+	if got := resolveS3Region(""); got != "ap-south-1" {
+		t.Errorf("Expected the ci profile region, got %s", got)
+	}
+
+	t.Setenv("AWS_DEFAULT_REGION", "us-west-1")
+	if got := resolveS3Region(""); got != "us-west-1" {
+		t.Errorf("Expected AWS_DEFAULT_REGION, got %s", got)
+	}
+	t.Setenv("AWS_REGION", "us-west-2")
+	if got := resolveS3Region(""); got != "us-west-2" {
+		t.Errorf("Expected AWS_REGION, got %s", got)
+	}
+	if got := resolveS3Region("sa-east-1"); got != "sa-east-1" {
+		t.Errorf("Expected the configured region, got %s", got)
+	}
+}
@@ -0,0 +1,140 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MinPartSize is the smallest part S3 accepts for every part but the last
+const MinPartSize = 5 << 20
+
+// MultipartClient is the subset of the S3 API used to stream an object in parts
+type MultipartClient interface {
+	CreateMultipartUpload(ctx context.Context, bucket, key string) (uploadID string, err error)
+	UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (etag string, err error)
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+}
+
+// CompletedPart identifies an uploaded part when completing a multipart upload
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// MultipartUploader streams writes to an S3 object, uploading a part each time
+// the buffer reaches partSize so memory stays bounded by one part. Close uploads
+// the remainder and completes the upload; after any failure, Close aborts it so
+// no orphaned parts are left behind.
+type MultipartUploader struct {
+	ctx      context.Context
+	client   MultipartClient
+	bucket   string
+	key      string
+	partSize int
+
+	uploadID string
+	buf      []byte
+	parts    []CompletedPart
+	err      error // first failure; later writes fail with it
+	closed   bool
+}
+
+// NewMultipartUploader creates an uploader for bucket/key. partSize is raised to MinPartSize if smaller.
+func NewMultipartUploader(ctx context.Context, client MultipartClient, bucket, key string, partSize int) *MultipartUploader {
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	return &MultipartUploader{
+		ctx:      ctx,
+		client:   client,
+		bucket:   bucket,
+		key:      key,
+		partSize: partSize,
+	}
+}
+
+// Write buffers p, uploading a part whenever the buffer fills
+func (u *MultipartUploader) Write(p []byte) (int, error) {
+	if u.closed {
+		return 0, fmt.Errorf("write to closed uploader for s3://%s/%s", u.bucket, u.key)
+	}
+	if u.err != nil {
+		return 0, u.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := min(u.partSize-len(u.buf), len(p))
+		u.buf = append(u.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(u.buf) == u.partSize {
+			if err := u.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// flush uploads the buffered bytes as the next part, starting the upload if needed
+func (u *MultipartUploader) flush() error {
+	if u.uploadID == "" {
+		uploadID, err := u.client.CreateMultipartUpload(u.ctx, u.bucket, u.key)
+		if err != nil {
+			u.err = fmt.Errorf("failed to start multipart upload for s3://%s/%s: %w", u.bucket, u.key, err)
+			return u.err
+		}
+		u.uploadID = uploadID
+	}
+
+	partNumber := len(u.parts) + 1
+	etag, err := u.client.UploadPart(u.ctx, u.bucket, u.key, u.uploadID, partNumber, u.buf)
+	if err != nil {
+		u.err = fmt.Errorf("failed to upload part %d of s3://%s/%s: %w", partNumber, u.bucket, u.key, err)
+		return u.err
+	}
+
+	u.parts = append(u.parts, CompletedPart{PartNumber: partNumber, ETag: etag})
+	u.buf = u.buf[:0]
+	return nil
+}
+
+// Close uploads any remaining bytes and completes the upload, or aborts it
+// if a write failed or completion fails
+func (u *MultipartUploader) Close() error {
+	if u.closed {
+		return nil
+	}
+	u.closed = true
+
+	if u.err == nil && (len(u.buf) > 0 || len(u.parts) == 0) {
+		u.flush()
+	}
+	if u.err == nil {
+		err := u.client.CompleteMultipartUpload(u.ctx, u.bucket, u.key, u.uploadID, u.parts)
+		if err == nil {
+			return nil
+		}
+		u.err = fmt.Errorf("failed to complete multipart upload for s3://%s/%s: %w", u.bucket, u.key, err)
+	}
+
+	return errors.Join(u.err, u.Abort())
+}
+
+// Abort cancels the upload, discarding any uploaded parts
+func (u *MultipartUploader) Abort() error {
+	u.closed = true
+	if u.uploadID == "" {
+		return nil
+	}
+	uploadID := u.uploadID
+	u.uploadID = ""
+	if err := u.client.AbortMultipartUpload(u.ctx, u.bucket, u.key, uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload for s3://%s/%s: %w", u.bucket, u.key, err)
+	}
+	return nil
+}
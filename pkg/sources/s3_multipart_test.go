@@ -0,0 +1,113 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mockMultipartClient records multipart calls and can fail a given part
+type mockMultipartClient struct {
+	calls     []string
+	parts     map[int]string
+	completed []CompletedPart
+	failPart  int
+}
+
+func (m *mockMultipartClient) CreateMultipartUpload(ctx context.Context, bucket, key string) (string, error) {
+	m.calls = append(m.calls, "create")
+	return "upload-1", nil
+}
+
+func (m *mockMultipartClient) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (string, error) {
+	m.calls = append(m.calls, fmt.Sprintf("part %d", partNumber))
+	if partNumber == m.failPart {
+		return "", errors.New("connection reset")
+	}
+	if m.parts == nil {
+		m.parts = make(map[int]string)
+	}
+	m.parts[partNumber] = string(body)
+	return fmt.Sprintf("etag-%d", partNumber), nil
+}
+
+func (m *mockMultipartClient) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []CompletedPart) error {
+	m.calls = append(m.calls, "complete")
+	m.completed = parts
+	return nil
+}
+
+func (m *mockMultipartClient) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	m.calls = append(m.calls, "abort")
+	return nil
+}
+
+func TestMultipartUploader_FlushesParts(t *testing.T) {
+	client := &mockMultipartClient{}
+	uploader := NewMultipartUploader(context.Background(), client, "bucket", "results.json", 0)
+	uploader.partSize = 4
+
+	for _, chunk := range []string{"abc", "defgh", "ij"} {
+		if _, err := uploader.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	// Only full parts are uploaded before Close
+	if want := []string{"create", "part 1", "part 2"}; !reflect.DeepEqual(client.calls, want) {
+		t.Errorf("Expected calls %v before close, got %v", want, client.calls)
+	}
+
+	if err := uploader.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	if want := []string{"create", "part 1", "part 2", "part 3", "complete"}; !reflect.DeepEqual(client.calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, client.calls)
+	}
+	if got := client.parts[1] + client.parts[2] + client.parts[3]; got != "abcdefghij" {
+		t.Errorf("Expected parts to reassemble the written bytes, got %q", got)
+	}
+	wantParts := []CompletedPart{{1, "etag-1"}, {2, "etag-2"}, {3, "etag-3"}}
+	if !reflect.DeepEqual(client.completed, wantParts) {
+		t.Errorf("Expected completed parts %v, got %v", wantParts, client.completed)
+	}
+}
+
+func TestMultipartUploader_EmptyObject(t *testing.T) {
+	client := &mockMultipartClient{}
+	uploader := NewMultipartUploader(context.Background(), client, "bucket", "empty.json", 0)
+
+	if err := uploader.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	if want := []string{"create", "part 1", "complete"}; !reflect.DeepEqual(client.calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, client.calls)
+	}
+}
+
+func TestMultipartUploader_AbortsOnError(t *testing.T) {
+	client := &mockMultipartClient{failPart: 2}
+	uploader := NewMultipartUploader(context.Background(), client, "bucket", "results.json", 0)
+	uploader.partSize = 4
+
+	_, err := uploader.Write([]byte("abcdefghij"))
+	if err == nil || !strings.Contains(err.Error(), "part 2") {
+		t.Fatalf("Expected part 2 upload error, got %v", err)
+	}
+
+	if _, err := uploader.Write([]byte("more")); err == nil {
+		t.Error("Expected writes after a failure to fail, got nil")
+	}
+
+	if err := uploader.Close(); err == nil {
+		t.Error("Expected close to report the upload failure, got nil")
+	}
+
+	if want := []string{"create", "part 1", "part 2", "abort"}; !reflect.DeepEqual(client.calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, client.calls)
+	}
+}
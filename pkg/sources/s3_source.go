@@ -0,0 +1,250 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// s3Scheme prefixes paths that name S3 objects
+const s3Scheme = "s3://"
+
+// IsS3Path reports whether path names S3 objects, e.g. s3://bucket/prefix/*.json
+func IsS3Path(path string) bool {
+	return strings.HasPrefix(path, s3Scheme)
+}
+
+// S3Source implements Source interface for JSON objects in S3. Objects are
+// matched, decoded, and written exactly like JSONSource files, with the key
+// (or key pattern) in place of a local path.
+type S3Source struct {
+	bucket string
+	key    string // object key, or a key pattern when reading
+	fsys   *s3FS
+	json   *JSONSource
+}
+
+// NewS3Source creates a source for the S3 path in cfg. Requests are signed with
+// credentials from the AWS environment or shared credentials file, for the
+// region in cfg or the AWS environment; endpoint selects an S3-compatible
+// server instead of AWS.
+func NewS3Source(cfg map[string]interface{}, schema config.SchemaConfig) (*S3Source, error) {
+	creds, err := loadAWSCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	region, _ := cfg["region"].(string)
+	endpoint, _ := cfg["endpoint"].(string)
+	if endpoint == "" {
+		endpoint = firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL")
+	}
+	client, err := newHTTPS3Client(endpoint, resolveS3Region(region), creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewS3SourceWithClient(client, cfg, schema)
+}
+
+// NewS3SourceWithClient creates a source for the S3 path in cfg that sends its requests through client
+func NewS3SourceWithClient(client S3Client, cfg map[string]interface{}, schema config.SchemaConfig) (*S3Source, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required for S3 source")
+	}
+
+	raw, _ := cfg["path"].(string)
+	if !IsS3Path(raw) {
+		return nil, fmt.Errorf("path must be an s3://bucket/key URL for S3 source, got %q", raw)
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(raw, s3Scheme), "/")
+	key = strings.TrimSuffix(key, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("path must name a bucket and key, got %q", raw)
+	}
+	if !fs.ValidPath(key) {
+		return nil, fmt.Errorf("invalid S3 key %q", key)
+	}
+	if _, ok := cfg["split"]; ok {
+		return nil, fmt.Errorf("split is not supported for S3 sources")
+	}
+	if _, ok := cfg["append"]; ok {
+		return nil, fmt.Errorf("append is not supported for S3 sources; objects cannot be appended to")
+	}
+
+	merged := make(map[string]interface{}, len(cfg))
+	for k, v := range cfg {
+		merged[k] = v
+	}
+	merged["path"] = key
+
+	source, err := NewJSONSource(merged, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := &s3FS{ctx: context.Background(), client: client, bucket: bucket}
+	source.fsys = fsys
+	source.displayRoot = s3Scheme + bucket + "/"
+	source.create = func(ctx context.Context) (io.WriteCloser, error) {
+		if HasWildcard(key) {
+			return nil, fmt.Errorf("cannot write to key pattern %s", raw)
+		}
+		// The upload completes in Close, which must not fail because the
+		// context of the last Write was cancelled
+		return NewMultipartUploader(context.WithoutCancel(ctx), client, bucket, key, MinPartSize), nil
+	}
+
+	return &S3Source{bucket: bucket, key: key, fsys: fsys, json: source}, nil
+}
+
+// Read lists the objects matching the key pattern and decodes each in key order
+func (s *S3Source) Read(ctx context.Context) ([]Record, error) {
+	s.fsys.ctx = ctx
+	return s.json.Read(ctx)
+}
+
+// ReadStream emits records on the returned channel as objects are downloaded and decoded
+func (s *S3Source) ReadStream(ctx context.Context) (<-chan Record, <-chan error) {
+	s.fsys.ctx = ctx
+	return s.json.ReadStream(ctx)
+}
+
+// FailedFiles returns the objects skipped by continue_on_file_error, with the error each failed with
+func (s *S3Source) FailedFiles() []FileError {
+	return s.json.FailedFiles()
+}
+
+// Write encodes records into the object's upload buffer, uploading a part each
+// time it reaches MinPartSize; Close uploads the rest and completes the object
+func (s *S3Source) Write(ctx context.Context, records []Record) error {
+	return s.json.Write(ctx, records)
+}
+
+// Capabilities reports the features supported by the S3 source
+func (s *S3Source) Capabilities() Capabilities {
+	return Capabilities{
+		Write:       !HasWildcard(s.key),
+		Streaming:   true,
+		Compression: true,
+	}
+}
+
+// Schema returns the schema the source was configured with
+func (s *S3Source) Schema() config.SchemaConfig {
+	return s.json.Schema()
+}
+
+// Close completes the upload of written records, or aborts it if writing failed
+func (s *S3Source) Close() error {
+	return s.json.Close()
+}
+
+// s3FS presents the objects of a bucket as a read-only fs.FS, with prefixes
+// ending in a slash as directories, so JSONSource can match and read them
+type s3FS struct {
+	ctx    context.Context // used for every request; fs.FS methods take none
+	client S3Client
+	bucket string
+}
+
+// Open opens the object at name; directories can be listed with ReadDir but not opened
+func (f *s3FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	body, err := f.client.GetObject(f.ctx, f.bucket, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &s3File{fsys: f, name: name, body: body}, nil
+}
+
+// Stat describes the object at name, or the directory formed by keys beneath it
+func (f *s3FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return remoteFileInfo{name: ".", dir: true}, nil
+	}
+
+	object, err := f.client.HeadObject(f.ctx, f.bucket, name)
+	if err == nil {
+		return remoteFileInfo{name: path.Base(name), size: object.Size, modTime: object.LastModified}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	objects, prefixes, err := f.client.ListObjects(f.ctx, f.bucket, name+"/", "/")
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if len(objects) == 0 && len(prefixes) == 0 {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return remoteFileInfo{name: path.Base(name), dir: true}, nil
+}
+
+// ReadDir lists the objects and prefixes directly beneath name, sorted by name.
+// Keys that are not valid fs.FS paths, such as ones with empty segments, are skipped.
+func (f *s3FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	objects, prefixes, err := f.client.ListObjects(f.ctx, f.bucket, prefix, "/")
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	var entries []fs.DirEntry
+	for _, object := range objects {
+		base := strings.TrimPrefix(object.Key, prefix)
+		if base == "" || !fs.ValidPath(base) || strings.Contains(base, "/") {
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(remoteFileInfo{name: base, size: object.Size, modTime: object.LastModified}))
+	}
+	for _, common := range prefixes {
+		base := strings.TrimSuffix(strings.TrimPrefix(common, prefix), "/")
+		if base == "" || !fs.ValidPath(base) || strings.Contains(base, "/") {
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(remoteFileInfo{name: base, dir: true}))
+	}
+
+	sort.Slice(entries, func(i, k int) bool { return entries[i].Name() < entries[k].Name() })
+	return entries, nil
+}
+
+// s3File is an object being downloaded
+type s3File struct {
+	fsys *s3FS
+	name string
+	body io.ReadCloser
+}
+
+func (f *s3File) Read(p []byte) (int, error) {
+	return f.body.Read(p)
+}
+
+func (f *s3File) Stat() (fs.FileInfo, error) {
+	return f.fsys.Stat(f.name)
+}
+
+func (f *s3File) Close() error {
+	return f.body.Close()
+}
@@ -0,0 +1,364 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// fakeS3 serves the subset of the S3 REST API the S3 client uses, path style,
+// from objects held in memory. Listings return at most two entries per page.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte // "bucket/key" to content
+	uploads  map[string]map[int][]byte
+	requests []string
+}
+
+func newFakeS3(t *testing.T, objects map[string]string) (*fakeS3, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeS3{objects: make(map[string][]byte), uploads: make(map[string]map[int][]byte)}
+	for key, content := range objects {
+		fake.objects[key] = []byte(content)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>unsigned</Message></Error>", http.StatusForbidden)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	name := bucket + "/" + key
+	query := r.URL.Query()
+	f.requests = append(f.requests, r.Method+" "+name)
+
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		f.list(w, bucket, query.Get("prefix"), query.Get("delimiter"), query.Get("continuation-token"))
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		data, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Last-Modified", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+		f.uploads[id] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		var part int
+		fmt.Sscan(query.Get("partNumber"), &part)
+		body, _ := io.ReadAll(r.Body)
+		f.uploads[query.Get("uploadId")][part] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, part))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var doc completeMultipartUpload
+		if err := xml.NewDecoder(r.Body).Decode(&doc); err != nil {
+			http.Error(w, "<Error><Code>MalformedXML</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		var object []byte
+		for _, part := range doc.Parts {
+			object = append(object, f.uploads[query.Get("uploadId")][part.PartNumber]...)
+		}
+		f.objects[name] = object
+		delete(f.uploads, query.Get("uploadId"))
+		io.WriteString(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "<Error><Code>NotImplemented</Code></Error>", http.StatusNotImplemented)
+	}
+}
+
+// list writes a ListObjectsV2 page, grouping keys into common prefixes at delimiter
+func (f *fakeS3) list(w http.ResponseWriter, bucket, prefix, delimiter, token string) {
+	var entries []string
+	seen := make(map[string]bool)
+	for name := range f.objects {
+		key, ok := strings.CutPrefix(name, bucket+"/")
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				key = key[:len(prefix)+i+1]
+			}
+		}
+		if !seen[key] {
+			seen[key] = true
+			entries = append(entries, key)
+		}
+	}
+	sort.Strings(entries)
+
+	start := 0
+	fmt.Sscan(token, &start)
+	end := min(start+2, len(entries))
+
+	var b strings.Builder
+	b.WriteString("<ListBucketResult>")
+	for _, entry := range entries[start:end] {
+		if strings.HasSuffix(entry, delimiter) && delimiter != "" {
+			fmt.Fprintf(&b, "<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>", entry)
+			continue
+		}
+		fmt.Fprintf(&b, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>2024-01-02T03:04:05.000Z</LastModified></Contents>",
+			entry, len(f.objects[bucket+"/"+entry]))
+	}
+	if end < len(entries) {
+		fmt.Fprintf(&b, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", end)
+	}
+	b.WriteString("</ListBucketResult>")
+	io.WriteString(w, b.String())
+}
+
+// setS3Env points the AWS environment at static test credentials
+func setS3Env(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "eu-west-1")
+}
+
+func TestS3Source_Read(t *testing.T) {
+	setS3Env(t)
+	_, server := newFakeS3(t, map[string]string{
+		"data/evals/b.json":         `[{"text": "b1"}, {"text": "b2"}]`,
+		"data/evals/a.json":         `[{"text": "a1"}]`,
+		"data/evals/c.json":         `[{"text": "c1"}]`,
+		"data/evals/notes.txt":      `not json`,
+		"data/evals/nested/d.json":  `[{"text": "d1"}]`,
+		"data/other/e.json":         `[{"text": "e1"}]`,
+		"other-bucket/evals/x.json": `[{"text": "x"}]`,
+	})
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+
+	tests := []struct {
+		name string
+		cfg  map[string]interface{}
+		want []string
+	}{
+		{"wildcard", map[string]interface{}{"path": "s3://data/evals/*.json"}, []string{"a1", "b1", "b2", "c1"}},
+		{"single key", map[string]interface{}{"path": "s3://data/evals/b.json"}, []string{"b1", "b2"}},
+		{"wildcard prefix", map[string]interface{}{"path": "s3://data/*/e.json"}, []string{"e1"}},
+		{"recursive prefix", map[string]interface{}{"path": "s3://data/evals/", "recursive": true, "mode": "auto", "continue_on_file_error": true},
+			[]string{"a1", "b1", "b2", "c1", "d1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]interface{}{"endpoint": server.URL}
+			for k, v := range tt.cfg {
+				cfg[k] = v
+			}
+			source, err := NewDefaultFactory().CreateSource(cfg, "json", schema)
+			if err != nil {
+				t.Fatalf("Failed to create S3 source: %v", err)
+			}
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read records: %v", err)
+			}
+
+			var got []string
+			for _, record := range records {
+				got = append(got, record["text"].(string))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestS3Source_ReadErrors(t *testing.T) {
+	setS3Env(t)
+	_, server := newFakeS3(t, map[string]string{
+		"data/bad.json": `[{"text": 1}]`,
+	})
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"validation", "s3://data/bad.json", "failed to read file s3://data/bad.json: record 0"},
+		{"missing key", "s3://data/missing.json", "stat s3://data/missing.json: file does not exist"},
+		{"no match", "s3://data/*.jsonl", "no files found matching pattern: s3://data/*.jsonl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewS3Source(map[string]interface{}{"path": tt.path, "endpoint": server.URL}, schema)
+			if err != nil {
+				t.Fatalf("Failed to create S3 source: %v", err)
+			}
+			_, err = source.Read(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestS3Source_WriteRoundTrip(t *testing.T) {
+	setS3Env(t)
+	fake, server := newFakeS3(t, nil)
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+	records := []Record{{"text": "a"}, {"text": "b"}, {"text": "c"}}
+
+	for _, path := range []string{"s3://out/results.json", "s3://out/results.jsonl.gz"} {
+		t.Run(path, func(t *testing.T) {
+			cfg := map[string]interface{}{"path": path, "endpoint": server.URL}
+			if strings.Contains(path, ".jsonl") {
+				cfg["mode"] = "lines"
+			}
+
+			writer, err := NewS3Source(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create S3 source: %v", err)
+			}
+			if !writer.Capabilities().Write {
+				t.Errorf("Expected an S3 key to be writable")
+			}
+			for _, record := range records {
+				if err := writer.Write(context.Background(), []Record{record}); err != nil {
+					t.Fatalf("Failed to write records: %v", err)
+				}
+			}
+
+			// Nothing is uploaded until the buffer fills or the source is closed
+			fake.mu.Lock()
+			requests := len(fake.requests)
+			fake.mu.Unlock()
+			if requests != 0 {
+				t.Errorf("Expected writes to be buffered, got %d requests", requests)
+			}
+
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			reader, err := NewS3Source(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create S3 source: %v", err)
+			}
+			got, err := reader.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read back records: %v", err)
+			}
+			if !reflect.DeepEqual(got, records) {
+				t.Errorf("Expected %v, got %v", records, got)
+			}
+
+			fake.mu.Lock()
+			fake.requests = nil
+			fake.mu.Unlock()
+		})
+	}
+}
+
+func TestS3Source_WriteToPatternFails(t *testing.T) {
+	setS3Env(t)
+	_, server := newFakeS3(t, nil)
+
+	source, err := NewS3Source(map[string]interface{}{"path": "s3://out/*.json", "endpoint": server.URL}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create S3 source: %v", err)
+	}
+	if source.Capabilities().Write {
+		t.Errorf("Expected a key pattern not to be writable")
+	}
+	if err := source.Write(context.Background(), []Record{{"text": "a"}}); err == nil || !strings.Contains(err.Error(), "key pattern") {
+		t.Errorf("Expected writing to a key pattern to fail, got %v", err)
+	}
+}
+
+func TestNewS3Source_InvalidPaths(t *testing.T) {
+	setS3Env(t)
+
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr string
+	}{
+		{"not s3", map[string]interface{}{"path": "data/x.json"}, "s3://bucket/key"},
+		{"no key", map[string]interface{}{"path": "s3://bucket"}, "must name a bucket and key"},
+		{"no bucket", map[string]interface{}{"path": "s3:///x.json"}, "must name a bucket and key"},
+		{"empty segment", map[string]interface{}{"path": "s3://bucket/a//b.json"}, "invalid S3 key"},
+		{"split", map[string]interface{}{"path": "s3://bucket/out", "split": "per_record"}, "split is not supported"},
+		{"append", map[string]interface{}{"path": "s3://bucket/out.jsonl", "mode": "lines", "append": true}, "append is not supported"},
+		{"bad endpoint", map[string]interface{}{"path": "s3://bucket/x.json", "endpoint": "localhost"}, "invalid S3 endpoint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewS3Source(tt.cfg, config.SchemaConfig{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestS3Source_LargeWriteUploadsParts(t *testing.T) {
+	setS3Env(t)
+	fake, server := newFakeS3(t, nil)
+
+	source, err := NewS3Source(map[string]interface{}{"path": "s3://out/big.jsonl", "mode": "lines", "endpoint": server.URL}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create S3 source: %v", err)
+	}
+	text := strings.Repeat("x", 1<<20)
+	for i := 0; i < 6; i++ {
+		if err := source.Write(context.Background(), []Record{{"text": text}}); err != nil {
+			t.Fatalf("Failed to write records: %v", err)
+		}
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Failed to close source: %v", err)
+	}
+
+	var parts int
+	for _, request := range fake.requests {
+		if strings.HasPrefix(request, http.MethodPut) {
+			parts++
+		}
+	}
+	if parts != 2 {
+		t.Errorf("Expected the object uploaded in 2 parts, got %d", parts)
+	}
+	if lines := bytes.Count(fake.objects["out/big.jsonl"], []byte("\n")); lines != 6 {
+		t.Errorf("Expected 6 lines in the object, got %d", lines)
+	}
+}
@@ -2,6 +2,8 @@ package sources
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/adhaamehab/meval.ai/pkg/config"
 )
@@ -14,6 +16,32 @@ type Source interface {
 	Write(ctx context.Context, records []Record) error
 	// Close closes the source
 	Close() error
+	// Capabilities reports the optional features the source supports
+	Capabilities() Capabilities
+	// Schema returns the schema the source was configured with
+	Schema() config.SchemaConfig
+}
+
+// Capabilities describes the optional features a source supports
+type Capabilities struct {
+	Write       bool
+	Streaming   bool // implements StreamingSource
+	Count       bool
+	Compression bool
+	Append      bool // implements Appender
+}
+
+// BaseSource provides default capabilities for sources; embed it and override as needed
+type BaseSource struct{}
+
+// Capabilities reports no optional features
+func (BaseSource) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// Schema reports an empty schema
+func (BaseSource) Schema() config.SchemaConfig {
+	return config.SchemaConfig{}
 }
 
 // Record represents a single data record
@@ -23,3 +51,100 @@ type Record map[string]interface{}
 type Factory interface {
 	CreateSource(config map[string]interface{}, format string, schema config.SchemaConfig) (Source, error)
 }
+
+// CloseAll closes every source and joins all close errors so none are lost
+func CloseAll(srcs ...Source) error {
+	var errs []error
+	for i, src := range srcs {
+		if src == nil {
+			continue
+		}
+		if err := src.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("source %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StreamingSource is implemented by sources that can emit records as they are
+// decoded instead of loading them all. ReadStream closes the record channel
+// when the read ends; the error channel then delivers the error that ended it,
+// if any, and is closed.
+type StreamingSource interface {
+	ReadStream(ctx context.Context) (<-chan Record, <-chan error)
+}
+
+// ReadStream streams the records of src, falling back to a single Read for
+// sources that are not a StreamingSource
+func ReadStream(ctx context.Context, src Source) (<-chan Record, <-chan error) {
+	if streaming, ok := src.(StreamingSource); ok {
+		return streaming.ReadStream(ctx)
+	}
+
+	records := make(chan Record)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(records)
+
+		all, err := src.Read(ctx)
+		if err != nil {
+			errs <- err
+			return
+		}
+		for _, record := range all {
+			select {
+			case records <- record:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return records, errs
+}
+
+// ErrAppendUnsupported is returned by Append for sources that cannot append
+var ErrAppendUnsupported = errors.New("source does not support append")
+
+// Appender is implemented by sources that can add records to what is already
+// persisted. Unlike repeated Write calls, which build one output that is only
+// complete after Close, every Append leaves a complete, readable output, so
+// results can be persisted incrementally during a run.
+type Appender interface {
+	Append(ctx context.Context, records []Record) error
+}
+
+// Append appends records to src, or returns ErrAppendUnsupported when src is not an Appender
+func Append(ctx context.Context, src Source, records []Record) error {
+	appender, ok := src.(Appender)
+	if !ok {
+		return ErrAppendUnsupported
+	}
+	return appender.Append(ctx, records)
+}
+
+// ContextCloser is implemented by sources that can bound their own Close by a context
+type ContextCloser interface {
+	CloseContext(ctx context.Context) error
+}
+
+// CloseContext closes src, returning ctx.Err() if the context ends first.
+// On timeout the close keeps running in the background so partial writes are still finalized.
+func CloseContext(ctx context.Context, src Source) error {
+	if closer, ok := src.(ContextCloser); ok {
+		return closer.CloseContext(ctx)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- src.Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("close did not finish: %w", ctx.Err())
+	}
+}
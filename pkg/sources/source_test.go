@@ -0,0 +1,135 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+type closeFailingSource struct {
+	BaseSource
+	err    error
+	closed bool
+}
+
+func (s *closeFailingSource) Read(ctx context.Context) ([]Record, error) { return nil, nil }
+
+func (s *closeFailingSource) Write(ctx context.Context, records []Record) error { return nil }
+
+func (s *closeFailingSource) Close() error {
+	s.closed = true
+	return s.err
+}
+
+func TestCloseAll_AggregatesErrors(t *testing.T) {
+	errFirst := errors.New("first close failed")
+	errSecond := errors.New("second close failed")
+
+	first := &closeFailingSource{err: errFirst}
+	second := &closeFailingSource{err: errSecond}
+
+	err := CloseAll(first, second)
+	if err == nil {
+		t.Fatal("Expected aggregated close error, got nil")
+	}
+
+	if !first.closed || !second.closed {
+		t.Errorf("Expected every source to be closed, got first=%v second=%v", first.closed, second.closed)
+	}
+
+	if !errors.Is(err, errFirst) {
+		t.Errorf("Expected error to wrap first close error, got %v", err)
+	}
+
+	if !errors.Is(err, errSecond) {
+		t.Errorf("Expected error to wrap second close error, got %v", err)
+	}
+}
+
+func TestCloseAll_NoErrors(t *testing.T) {
+	if err := CloseAll(&closeFailingSource{}, nil, &closeFailingSource{}); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+}
+
+type slowCloseSource struct {
+	BaseSource
+	delay  time.Duration
+	closed chan struct{}
+}
+
+func (s *slowCloseSource) Read(ctx context.Context) ([]Record, error) { return nil, nil }
+
+func (s *slowCloseSource) Write(ctx context.Context, records []Record) error { return nil }
+
+func (s *slowCloseSource) Close() error {
+	time.Sleep(s.delay)
+	close(s.closed)
+	return nil
+}
+
+func TestCloseContext_RespectsDeadline(t *testing.T) {
+	src := &slowCloseSource{delay: 200 * time.Millisecond, closed: make(chan struct{})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := CloseContext(ctx, src)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= src.delay {
+		t.Errorf("Expected CloseContext to return before the slow close, took %v", elapsed)
+	}
+
+	// The close must still be finalized in the background
+	select {
+	case <-src.closed:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected background close to finish")
+	}
+}
+
+func TestCloseContext_FastClose(t *testing.T) {
+	src := &slowCloseSource{closed: make(chan struct{})}
+
+	if err := CloseContext(context.Background(), src); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+}
+
+func TestSource_Schema(t *testing.T) {
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string"},
+			{Name: "label", Type: "string", Enum: []string{"positive", "negative"}},
+		},
+	}
+	cfg := map[string]interface{}{
+		"path":  filepath.Join(t.TempDir(), "data.json"),
+		"limit": 10,
+		"log":   true,
+	}
+
+	// The factory wraps the JSON source in middlewares, which must expose its schema
+	src, err := NewDefaultFactory().CreateSource(cfg, "json", schema)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	defer src.Close()
+
+	if got := src.Schema(); !reflect.DeepEqual(got, schema) {
+		t.Errorf("Expected schema %+v, got %+v", schema, got)
+	}
+
+	wrapped := Map(src, func(r Record) (Record, error) { return r, nil }, "")
+	if got := wrapped.Schema(); !reflect.DeepEqual(got, schema) {
+		t.Errorf("Expected mapped source schema %+v, got %+v", schema, got)
+	}
+}
@@ -0,0 +1,112 @@
+package sources
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SplitPerRecord is the split option value that writes each record to its own file
+const SplitPerRecord = "per_record"
+
+// DefaultFilenameTemplate names per-record files by their 1-based write index, e.g. 000001.json
+const DefaultFilenameTemplate = "{{index}}.json"
+
+// filenameVariablePattern matches filename template variables like {{id}}
+var filenameVariablePattern = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// unsafeFilenameChars matches characters replaced when a record value is used in a filename
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// recordFileWriter writes each record to a file under dir named by a template
+type recordFileWriter struct {
+	dir      string
+	template string
+	written  map[string]bool // files created so far, to reject templates that collide
+	fsync    bool            // sync each file to disk before closing it
+	encoder  EncoderFunc
+}
+
+// newRecordFileWriter validates the filename template and returns a writer for dir
+func newRecordFileWriter(dir, template string) (*recordFileWriter, error) {
+	if template == "" {
+		template = DefaultFilenameTemplate
+	}
+	if !filenameVariablePattern.MatchString(template) {
+		return nil, fmt.Errorf("filename template %q must reference a record field or {{index}}", template)
+	}
+	if filepath.IsAbs(template) {
+		return nil, fmt.Errorf("filename template %q must be relative to the output path", template)
+	}
+
+	return &recordFileWriter{dir: dir, template: template, written: make(map[string]bool), encoder: defaultEncoder}, nil
+}
+
+// filename renders the template for the record at 1-based index
+func (w *recordFileWriter) filename(record Record, index int) (string, error) {
+	var missing string
+	name := filenameVariablePattern.ReplaceAllStringFunc(w.template, func(match string) string {
+		variable := filenameVariablePattern.FindStringSubmatch(match)[1]
+		if variable == "index" {
+			return fmt.Sprintf("%06d", index)
+		}
+
+		value, ok := record[variable]
+		if !ok || value == nil {
+			missing = variable
+			return ""
+		}
+		return sanitizeFilename(fmt.Sprintf("%v", value))
+	})
+	if missing != "" {
+		return "", fmt.Errorf("filename template field %s is missing from record %d", missing, index)
+	}
+
+	name = filepath.Clean(filepath.FromSlash(name))
+	if name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("filename %q for record %d escapes the output directory", name, index)
+	}
+	return filepath.Join(w.dir, name), nil
+}
+
+// write encodes record as indented JSON into its own file, creating directories as needed
+func (w *recordFileWriter) write(record Record, index int) error {
+	path, err := w.filename(record, index)
+	if err != nil {
+		return err
+	}
+	if w.written[path] {
+		return fmt.Errorf("record %d would overwrite %s; make the filename template unique per record", index, path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := w.encoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(record); err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	if err := writeFile(path, buf.Bytes(), w.fsync); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	w.written[path] = true
+	return nil
+}
+
+// sanitizeFilename replaces path separators and other unsafe characters in a
+// record value, so a value cannot change the directory a file is written to
+func sanitizeFilename(value string) string {
+	value = unsafeFilenameChars.ReplaceAllString(value, "_")
+	if strings.Trim(value, ".") == "" {
+		// "", "." and ".." are not usable file names
+		return strings.Repeat("_", len(value)+1)
+	}
+	return value
+}
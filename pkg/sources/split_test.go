@@ -0,0 +1,131 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestJSONSource_SplitPerRecord(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "id", Type: "string"},
+		{Name: "label", Type: "string"},
+	}}
+	records := []Record{
+		{"id": "a1", "label": "positive"},
+		{"id": "b/2", "label": "negative"},
+		{"id": "c 3", "label": "positive"},
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		want     []string
+	}{
+		{"default index", "", []string{"000001.json", "000002.json", "000003.json"}},
+		{"record fields", "{{label}}/{{id}}.json", []string{"positive/a1.json", "negative/b_2.json", "positive/c_3.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "out")
+			cfg := map[string]interface{}{"path": dir, "split": "per_record"}
+			if tt.filename != "" {
+				cfg["filename"] = tt.filename
+			}
+			source, err := NewJSONSource(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+
+			if err := source.Write(context.Background(), records[:2]); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := source.Write(context.Background(), records[2:]); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := source.Close(); err != nil {
+				t.Fatalf("Failed to close: %v", err)
+			}
+
+			for i, name := range tt.want {
+				data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", name, err)
+				}
+				var got Record
+				if err := json.Unmarshal(data, &got); err != nil {
+					t.Fatalf("Failed to parse %s: %v", name, err)
+				}
+				if got["id"] != records[i]["id"] {
+					t.Errorf("Expected %s to hold record %v, got %v", name, records[i], got)
+				}
+			}
+
+			var files int
+			filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					files++
+				}
+				return nil
+			})
+			if files != len(records) {
+				t.Errorf("Expected %d files, got %d", len(records), files)
+			}
+		})
+	}
+}
+
+func TestJSONSource_SplitPerRecordErrors(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string"}}}
+
+	if _, err := NewJSONSource(map[string]interface{}{"path": t.TempDir(), "split": "per_record", "filename": "out.json"}, schema); err == nil {
+		t.Error("Expected error for a template without variables, got nil")
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		records  []Record
+	}{
+		{"missing field", "{{name}}.json", []Record{{"id": "1"}}},
+		{"collision", "{{id}}.json", []Record{{"id": "1"}, {"id": "1"}}},
+		{"escape", "../{{id}}.json", []Record{{"id": "1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source, err := NewJSONSource(map[string]interface{}{"path": dir, "split": "per_record", "filename": tt.filename}, schema)
+			if err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+			defer source.Close()
+
+			if err := source.Write(context.Background(), tt.records); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := map[string]string{
+		"a1":       "a1",
+		"b/2":      "b_2",
+		`..\etc`:   ".._etc",
+		"..":       "___",
+		"":         "_",
+		"Ünïcode!": "_n_code_",
+	}
+
+	for value, want := range tests {
+		if got := sanitizeFilename(value); got != want {
+			t.Errorf("Expected sanitizeFilename(%q) = %q, got %q", value, want, got)
+		}
+	}
+}
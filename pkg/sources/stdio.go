@@ -0,0 +1,77 @@
+package sources
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// StdioPath reads records from stdin when used as an input path and writes them
+// to stdout when used as an output path
+const StdioPath = "-"
+
+// The standard streams behind StdioPath; replaced in tests
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+// IsStdioPath reports whether path is StdioPath
+func IsStdioPath(path string) bool {
+	return path == StdioPath
+}
+
+// useStdio points a JSON source at the standard streams: reads decode stdin,
+// presented as a single file, and writes go to stdout, which Close leaves open
+func (j *JSONSource) useStdio() {
+	j.fsys = stdinFS{}
+	j.create = func(ctx context.Context) (io.WriteCloser, error) {
+		return stdoutWriter{stdout}, nil
+	}
+}
+
+// stdinFS presents stdin as a filesystem holding the single file "-"
+type stdinFS struct{}
+
+func (stdinFS) Open(name string) (fs.File, error) {
+	if name != StdioPath {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return stdinFile{}, nil
+}
+
+// Stat describes stdin without reading it; its size is unknown
+func (stdinFS) Stat(name string) (fs.FileInfo, error) {
+	switch name {
+	case ".":
+		return remoteFileInfo{name: ".", dir: true}, nil
+	case StdioPath:
+		return remoteFileInfo{name: StdioPath, size: -1}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// stdinFile reads stdin; closing it leaves stdin open for the process
+type stdinFile struct{}
+
+func (stdinFile) Read(p []byte) (int, error) {
+	return stdin.Read(p)
+}
+
+func (stdinFile) Stat() (fs.FileInfo, error) {
+	return remoteFileInfo{name: StdioPath, size: -1}, nil
+}
+
+func (stdinFile) Close() error {
+	return nil
+}
+
+// stdoutWriter writes to stdout; closing it leaves stdout open for the process
+type stdoutWriter struct {
+	io.Writer
+}
+
+func (stdoutWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,120 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// setStdio replaces the standard streams behind StdioPath for the test
+func setStdio(t *testing.T, in io.Reader, out io.Writer) {
+	t.Helper()
+
+	oldIn, oldOut := stdin, stdout
+	stdin, stdout = in, out
+	t.Cleanup(func() { stdin, stdout = oldIn, oldOut })
+}
+
+// closeRecorder is a stdout that notes whether it was closed
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestJSONSource_ReadStdin(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}}}
+
+	tests := []struct {
+		mode  string
+		input string
+	}{
+		{"array", `[{"text": "great"}, {"text": "awful"}]`},
+		{"lines", "{\"text\": \"great\"}\n{\"text\": \"awful\"}\n"},
+		{"auto", "{\"text\": \"great\"}\n{\"text\": \"awful\"}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			setStdio(t, strings.NewReader(tt.input), nil)
+
+			source, err := NewDefaultFactory().CreateSource(map[string]interface{}{"path": "-", "mode": tt.mode}, "json", schema)
+			if err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+			records, err := source.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to read stdin: %v", err)
+			}
+			if len(records) != 2 || records[0]["text"] != "great" || records[1]["text"] != "awful" {
+				t.Errorf("Expected the 2 records from stdin, got %v", records)
+			}
+		})
+	}
+}
+
+func TestJSONSource_WriteStdout(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"array", "[\n{\n  \"text\": \"great\"\n}\n,\n{\n  \"text\": \"awful\"\n}\n\n]"},
+		{"lines", "{\"text\":\"great\"}\n{\"text\":\"awful\"}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			out := &closeRecorder{}
+			setStdio(t, nil, out)
+
+			source, err := NewJSONSource(map[string]interface{}{"path": "-", "mode": tt.mode}, config.SchemaConfig{})
+			if err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+			if !source.Capabilities().Write {
+				t.Errorf("Expected stdout to be writable")
+			}
+			records := []Record{{"text": "great"}, {"text": "awful"}}
+			if err := source.Write(context.Background(), records); err != nil {
+				t.Fatalf("Failed to write stdout: %v", err)
+			}
+			if err := source.Close(); err != nil {
+				t.Fatalf("Failed to close source: %v", err)
+			}
+
+			if out.closed {
+				t.Errorf("Expected Close to leave stdout open")
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("Expected output %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONSource_StdioUnsupported(t *testing.T) {
+	setStdio(t, strings.NewReader(""), &closeRecorder{})
+
+	if _, err := NewJSONSource(map[string]interface{}{"path": "-", "split": SplitPerRecord}, config.SchemaConfig{}); err == nil {
+		t.Error("Expected error splitting stdout into files, got nil")
+	}
+
+	source, err := NewJSONSource(map[string]interface{}{"path": "-", "mode": "lines"}, config.SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if source.Capabilities().Append {
+		t.Errorf("Expected stdout not to support Append")
+	}
+	if err := source.Append(context.Background(), []Record{{"text": "great"}}); err == nil || !strings.Contains(err.Error(), "stdout") {
+		t.Errorf("Expected error appending to stdout, got %v", err)
+	}
+}
@@ -0,0 +1,411 @@
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// errMalformedRecord marks raw input that could not be decoded into a record
+var errMalformedRecord = errors.New("malformed record")
+
+// recordValidator applies the read-side schema semantics every source shares:
+// unflattening, normalization, type widening, schema validation, and strict checks
+type recordValidator struct {
+	schema       config.SchemaConfig
+	strictSchema bool // reject fields not declared in the schema
+	flatten      bool // expand flattened columns into nested maps
+	flattenSep   string
+	widenTypes   bool // coerce mixed scalar types to the declared schema type
+	types        *typeObserver
+	preview      recordPreview
+	defaults     []fieldDefault            // filled in for absent fields on read
+	patterns     map[string]*regexp.Regexp // compiled field patterns, by field name
+}
+
+// fieldDefault is a schema field's default value, encoded as JSON so each
+// record gets its own decoded copy with the types JSON decoding produces
+type fieldDefault struct {
+	name string
+	raw  []byte
+}
+
+// newRecordValidator reads the shared strict_schema, flatten, flatten_separator,
+// widen_types, error_preview, and preview_fields options from a source config
+func newRecordValidator(cfg map[string]interface{}, schema config.SchemaConfig) (*recordValidator, error) {
+	strictSchema, err := boolOption(cfg, "strict_schema")
+	if err != nil {
+		return nil, err
+	}
+
+	flatten, err := boolOption(cfg, "flatten")
+	if err != nil {
+		return nil, err
+	}
+
+	widenTypes, err := boolOption(cfg, "widen_types")
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := newRecordPreview(cfg, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	flattenSep, _ := cfg["flatten_separator"].(string)
+	if flattenSep == "" {
+		flattenSep = DefaultFlattenSeparator
+	}
+
+	patterns, err := compilePatterns(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := fieldDefaults(schema, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordValidator{
+		schema:       schema,
+		strictSchema: strictSchema,
+		flatten:      flatten,
+		flattenSep:   flattenSep,
+		widenTypes:   widenTypes,
+		types:        newTypeObserver(),
+		preview:      preview,
+		defaults:     defaults,
+		patterns:     patterns,
+	}, nil
+}
+
+// fieldDefaults encodes the schema's default values, checking each against its field type
+func fieldDefaults(schema config.SchemaConfig, patterns map[string]*regexp.Regexp) ([]fieldDefault, error) {
+	var defaults []fieldDefault
+	for _, field := range schema.Fields {
+		if field.Default == nil {
+			continue
+		}
+		raw, err := json.Marshal(field.Default)
+		if err != nil {
+			return nil, fmt.Errorf("field %s default: %w", field.Name, err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("field %s default: %w", field.Name, err)
+		}
+		if err := validateFieldType(value, field.Type); err != nil {
+			return nil, fmt.Errorf("field %s default: %w", field.Name, err)
+		}
+		if err := validateFieldConstraints(value, field, patterns[field.Name]); err != nil {
+			return nil, fmt.Errorf("field %s default: %w", field.Name, err)
+		}
+		defaults = append(defaults, fieldDefault{name: field.Name, raw: raw})
+	}
+	return defaults, nil
+}
+
+// applyDefaults sets the default of every absent field that has one. A dotted
+// field is set inside its parent objects, which are created as needed.
+func (v *recordValidator) applyDefaults(record Record) {
+	for _, d := range v.defaults {
+		if _, exists := LookupField(record, d.name); exists {
+			continue
+		}
+		var value interface{}
+		json.Unmarshal(d.raw, &value) // encoded by fieldDefaults
+		setField(record, d.name, value)
+	}
+}
+
+// setField sets the field at path, walking and creating nested objects for a
+// dotted path. A segment holding a non-object value is left unchanged.
+func setField(record Record, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	current := map[string]interface{}(record)
+	for _, key := range keys[:len(keys)-1] {
+		next, exists := current[key]
+		if !exists {
+			child := make(map[string]interface{})
+			current[key] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = child
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+// decodeAndValidate decodes a raw JSON object and validates it. Decoding
+// failures wrap errMalformedRecord.
+func (v *recordValidator) decodeAndValidate(raw []byte) (Record, error) {
+	var record Record
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, fmt.Errorf("%w: %w", errMalformedRecord, err)
+	}
+
+	record, err := v.validate(record)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return record, nil
+}
+
+// validateRecords validates records a source has already decoded
+func (v *recordValidator) validateRecords(records []Record) ([]Record, error) {
+	validated := make([]Record, 0, len(records))
+	for i, record := range records {
+		record, err := v.validate(record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d validation failed: %w", i, err)
+		}
+		validated = append(validated, record)
+	}
+	return validated, nil
+}
+
+// validate prepares a decoded record and checks it against the schema. With
+// error_preview enabled, failures include a preview of the record.
+func (v *recordValidator) validate(record Record) (Record, error) {
+	record = v.unflatten(record)
+	normalizeRecord(record, v.schema)
+	v.types.observe(record, v.schema)
+	v.applyDefaults(record)
+
+	if err := v.check(record); err != nil {
+		return nil, v.preview.wrap(err, record)
+	}
+	return record, nil
+}
+
+// check widens and validates a prepared record
+func (v *recordValidator) check(record Record) error {
+	if v.widenTypes {
+		if err := widenRecord(record, v.schema); err != nil {
+			return err
+		}
+	}
+
+	if err := v.validateSchemaFields(record); err != nil {
+		return err
+	}
+
+	if v.strictSchema {
+		if extra := extraFields(record, v.schema); len(extra) > 0 {
+			return fmt.Errorf("undeclared fields: %s", strings.Join(extra, ", "))
+		}
+	}
+
+	return nil
+}
+
+// unflatten expands flattened columns on read, keeping declared field names intact
+func (v *recordValidator) unflatten(record Record) Record {
+	if !v.flatten {
+		return record
+	}
+
+	declared := make(map[string]bool, len(v.schema.Fields))
+	for _, field := range v.schema.Fields {
+		declared[field.Name] = true
+	}
+	return UnflattenRecord(record, v.flattenSep, declared)
+}
+
+// LookupField returns the field at path, preferring a top-level field whose
+// name contains dots over walking nested objects, so a schema field named
+// meta.lang reads {"meta": {"lang": "en"}}
+func LookupField(record Record, path string) (interface{}, bool) {
+	if value, ok := record[path]; ok {
+		return value, true
+	}
+	if !strings.Contains(path, ".") {
+		return nil, false
+	}
+
+	var value interface{} = map[string]interface{}(record)
+	for _, key := range strings.Split(path, ".") {
+		var current map[string]interface{}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			current = v
+		case Record:
+			current = v
+		default:
+			return nil, false
+		}
+		var ok bool
+		if value, ok = current[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// validateSchemaFields checks that every schema field is present with the
+// declared type, except optional fields, which are only checked when present.
+// Dotted field names are checked at their nested leaf.
+func (v *recordValidator) validateSchemaFields(record Record) error {
+	for _, field := range v.schema.Fields {
+		value, exists := LookupField(record, field.Name)
+		if !exists {
+			if field.Optional {
+				continue
+			}
+			return fmt.Errorf("missing required field: %s", field.Name)
+		}
+
+		if err := v.checkField(value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkField type-checks a present field value, then checks its constraints;
+// nullable fields accept null
+func (v *recordValidator) checkField(value interface{}, field config.FieldConfig) error {
+	if value == nil && field.Nullable {
+		return nil
+	}
+	if err := validateFieldType(value, field.Type); err != nil {
+		return fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	if err := validateFieldConstraints(value, field, v.patterns[field.Name]); err != nil {
+		return fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	return nil
+}
+
+// validateFieldConstraints checks a value that has the field's type against
+// the field's length, pattern (compiled from field.Pattern), bounds, and enum.
+// String lengths count runes. Numbers match an enum entry with the same numeric
+// value, so 1 matches "1" and "1.0".
+func validateFieldConstraints(value interface{}, field config.FieldConfig, pattern *regexp.Regexp) error {
+	if s, ok := value.(string); ok && (field.MinLength != nil || field.MaxLength != nil) {
+		length := utf8.RuneCountInString(s)
+		if field.MinLength != nil && length < *field.MinLength {
+			return fmt.Errorf("length %d is below min_length %d", length, *field.MinLength)
+		}
+		if field.MaxLength != nil && length > *field.MaxLength {
+			return fmt.Errorf("length %d exceeds max_length %d", length, *field.MaxLength)
+		}
+	}
+
+	if number, ok := numberValue(value); ok {
+		if field.Min != nil && number < *field.Min {
+			return fmt.Errorf("%v is below min %v", number, *field.Min)
+		}
+		if field.Max != nil && number > *field.Max {
+			return fmt.Errorf("%v exceeds max %v", number, *field.Max)
+		}
+	}
+
+	if s, ok := value.(string); ok && pattern != nil && !pattern.MatchString(s) {
+		return fmt.Errorf("value '%s' does not match pattern %s", s, field.Pattern)
+	}
+
+	if len(field.Enum) == 0 {
+		return nil
+	}
+	number, isNumber := numberValue(value)
+	for _, entry := range field.Enum {
+		if isNumber {
+			if allowed, err := strconv.ParseFloat(entry, 64); err == nil && allowed == number {
+				return nil
+			}
+		} else if value == entry {
+			return nil
+		}
+	}
+	return fmt.Errorf("value '%v' not in enum %v", value, field.Enum)
+}
+
+// compilePatterns compiles the schema's field patterns by field name, once per
+// source, so a source with an invalid one fails when it is created
+func compilePatterns(schema config.SchemaConfig) (map[string]*regexp.Regexp, error) {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, field := range schema.Fields {
+		if field.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(field.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid pattern %s: %w", field.Name, field.Pattern, err)
+		}
+		patterns[field.Name] = re
+	}
+	return patterns, nil
+}
+
+// numberValue returns a numeric value as a float64
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// validateOutputFields checks a record being written against the schema. A
+// record holding an error in errorField is a failed evaluation without model
+// outputs, so only the schema fields it has are checked.
+func (v *recordValidator) validateOutputFields(record Record, errorField string) error {
+	if message, _ := record[errorField].(string); errorField == "" || message == "" {
+		return v.validateSchemaFields(record)
+	}
+	for _, field := range v.schema.Fields {
+		value, exists := LookupField(record, field.Name)
+		if !exists {
+			continue
+		}
+		if err := v.checkField(value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extraFields returns the sorted names of record fields not declared in the
+// schema. The object holding a dotted field, such as meta for meta.lang, counts
+// as declared.
+func extraFields(record Record, schema config.SchemaConfig) []string {
+	declared := make(map[string]bool, len(schema.Fields))
+	for _, field := range schema.Fields {
+		declared[field.Name] = true
+		if parent, _, nested := strings.Cut(field.Name, "."); nested {
+			declared[parent] = true
+		}
+	}
+
+	var extra []string
+	for name := range record {
+		if !declared[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	return extra
+}
@@ -0,0 +1,457 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// decodedSource stands in for sources like CSV that produce records without
+// JSON decoding and validate them with the shared helper
+type decodedSource struct {
+	staticSource
+	validator *recordValidator
+}
+
+func (d *decodedSource) Read(ctx context.Context) ([]Record, error) {
+	return d.validator.validateRecords(d.records)
+}
+
+// newTestValidator creates a record validator for schema without source options
+func newTestValidator(t *testing.T, schema config.SchemaConfig) *recordValidator {
+	t.Helper()
+
+	validator, err := newRecordValidator(nil, schema)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	return validator
+}
+
+func TestRecordValidator_SharedAcrossSources(t *testing.T) {
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "text", Type: "string", Normalize: []string{"trim"}},
+			{Name: "score", Type: "number"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		line    string
+		wantErr string
+	}{
+		{"valid", nil, `{"text": " hi ", "score": 1}`, ""},
+		{"missing field", nil, `{"text": "hi"}`, "missing required field: score"},
+		{"wrong type", nil, `{"text": "hi", "score": "high"}`, "field score: expected number"},
+		{"widened", map[string]interface{}{"widen_types": true}, `{"text": "hi", "score": "2"}`, ""},
+		{"strict", map[string]interface{}{"strict_schema": true}, `{"text": "hi", "score": 1, "extra": true}`, "undeclared fields: extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]interface{}{"mode": "lines"}
+			for k, v := range tt.cfg {
+				cfg[k] = v
+			}
+
+			fsys := fstest.MapFS{"data.jsonl": {Data: []byte(tt.line + "\n")}}
+			jsonSource, err := NewJSONSourceFromFS(fsys, "data.jsonl", cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create JSON source: %v", err)
+			}
+
+			var record Record
+			if err := json.Unmarshal([]byte(tt.line), &record); err != nil {
+				t.Fatalf("Failed to decode test record: %v", err)
+			}
+			validator, err := newRecordValidator(cfg, schema)
+			if err != nil {
+				t.Fatalf("Failed to create validator: %v", err)
+			}
+			other := &decodedSource{staticSource: staticSource{records: []Record{record}}, validator: validator}
+
+			for name, src := range map[string]Source{"json": jsonSource, "decoded": other} {
+				records, err := src.Read(context.Background())
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Errorf("%s: expected error containing %q, got %v", name, tt.wantErr, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: expected record to validate, got %v", name, err)
+				}
+				if records[0]["text"] != "hi" {
+					t.Errorf("%s: expected normalized text 'hi', got %q", name, records[0]["text"])
+				}
+				if _, ok := records[0]["score"].(float64); !ok {
+					t.Errorf("%s: expected number score, got %T", name, records[0]["score"])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateOutputFields(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "text", Type: "string"},
+		{Name: "label", Type: "string"},
+		{Name: "_error", Type: "string"},
+	}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name       string
+		record     Record
+		errorField string
+		wantErr    bool
+	}{
+		{"complete record", Record{"text": "a", "label": "positive", "_error": ""}, "_error", false},
+		{"success missing output", Record{"text": "a", "_error": ""}, "_error", true},
+		{"failure missing output", Record{"text": "a", "_error": "timeout"}, "_error", false},
+		{"failure with wrong type", Record{"text": 1.0, "_error": "timeout"}, "_error", true},
+		{"no error field configured", Record{"text": "a", "_error": "timeout"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateOutputFields(tt.record, tt.errorField)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaFields_DottedPaths(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "text", Type: "string"},
+		{Name: "meta.lang", Type: "string"},
+		{Name: "meta.scores.overall", Type: "number"},
+	}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name    string
+		record  Record
+		wantErr string
+	}{
+		{"nested", Record{"text": "hi", "meta": map[string]interface{}{"lang": "en", "scores": map[string]interface{}{"overall": 0.9}}}, ""},
+		{"top-level dotted key", Record{"text": "hi", "meta.lang": "en", "meta.scores.overall": 0.9}, ""},
+		{"missing leaf", Record{"text": "hi", "meta": map[string]interface{}{"scores": map[string]interface{}{"overall": 0.9}}}, "missing required field: meta.lang"},
+		{"missing parent", Record{"text": "hi"}, "missing required field: meta.lang"},
+		{"parent not an object", Record{"text": "hi", "meta": "en"}, "missing required field: meta.lang"},
+		{"wrong leaf type", Record{"text": "hi", "meta": map[string]interface{}{"lang": "en", "scores": map[string]interface{}{"overall": "high"}}}, "field meta.scores.overall: expected number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateSchemaFields(tt.record)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestJSONSource_DottedSchemaFields(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "text", Type: "string"}, {Name: "meta.lang", Type: "string"}}}
+	fsys := fstest.MapFS{
+		"ok.jsonl":      {Data: []byte("{\"text\": \"hi\", \"meta\": {\"lang\": \"en\", \"source\": \"web\"}}\n")},
+		"missing.jsonl": {Data: []byte("{\"text\": \"hi\", \"meta\": {\"source\": \"web\"}}\n")},
+	}
+
+	// strict_schema accepts the object holding a declared dotted field
+	source, err := NewJSONSourceFromFS(fsys, "ok.jsonl", map[string]interface{}{"mode": "lines", "strict_schema": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err != nil {
+		t.Errorf("Expected the nested field to validate, got %v", err)
+	}
+
+	source, err = NewJSONSourceFromFS(fsys, "missing.jsonl", map[string]interface{}{"mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := source.Read(context.Background()); err == nil || !strings.Contains(err.Error(), "missing required field: meta.lang") {
+		t.Errorf("Expected the missing nested field to fail the read, got %v", err)
+	}
+
+	// The write path checks the same nested leaf
+	output, err := NewJSONSource(map[string]interface{}{"path": t.TempDir() + "/out.json"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON output: %v", err)
+	}
+	defer output.Close()
+	err = output.Write(context.Background(), []Record{{"text": "hi", "meta": map[string]interface{}{"lang": 1.0}}})
+	if err == nil || !strings.Contains(err.Error(), "field meta.lang") {
+		t.Errorf("Expected the nested leaf type to fail the write, got %v", err)
+	}
+}
+
+func TestValidateSchemaFields_Optional(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "text", Type: "string"},
+		{Name: "annotator", Type: "string", Optional: true},
+		{Name: "meta.lang", Type: "string", Optional: true},
+	}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name    string
+		record  Record
+		wantErr string
+	}{
+		{"absent", Record{"text": "hi"}, ""},
+		{"present", Record{"text": "hi", "annotator": "a1", "meta": map[string]interface{}{"lang": "en"}}, ""},
+		{"present with wrong type", Record{"text": "hi", "annotator": 7.0}, "field annotator: expected string"},
+		{"nested wrong type", Record{"text": "hi", "meta": map[string]interface{}{"lang": true}}, "field meta.lang: expected string"},
+		{"required still required", Record{"annotator": "a1"}, "missing required field: text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateSchemaFields(tt.record)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaFields_Nullable(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "text", Type: "string"},
+		{Name: "label", Type: "string", Nullable: true},
+	}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name    string
+		record  Record
+		wantErr string
+	}{
+		{"nullable null", Record{"text": "hi", "label": nil}, ""},
+		{"nullable value", Record{"text": "hi", "label": "positive"}, ""},
+		{"nullable wrong type", Record{"text": "hi", "label": 1.0}, "field label: expected string, got float64"},
+		{"non-nullable null", Record{"text": nil, "label": nil}, "field text: expected string, got <nil>"},
+		{"nullable still required", Record{"text": "hi"}, "missing required field: label"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateSchemaFields(tt.record)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := validator.validateOutputFields(Record{"label": nil, "error": "timeout"}, "error"); err != nil {
+		t.Errorf("Expected a null nullable field on an error record to pass, got %v", err)
+	}
+}
+
+func TestValidateSchemaFields_Enum(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "predicted_sentiment", Type: "string", Enum: []string{"positive", "negative", "neutral"}},
+		{Name: "stars", Type: "number", Enum: []string{"1", "2", "3"}, Optional: true},
+	}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name    string
+		record  Record
+		wantErr string
+	}{
+		{"allowed string", Record{"predicted_sentiment": "neutral"}, ""},
+		{"allowed number", Record{"predicted_sentiment": "positive", "stars": 2.0}, ""},
+		{"allowed int", Record{"predicted_sentiment": "positive", "stars": 3}, ""},
+		{"string not in enum", Record{"predicted_sentiment": "happy"}, "field predicted_sentiment: value 'happy' not in enum [positive negative neutral]"},
+		{"number not in enum", Record{"predicted_sentiment": "positive", "stars": 2.5}, "field stars: value '2.5' not in enum [1 2 3]"},
+		{"type checked first", Record{"predicted_sentiment": 1.0}, "field predicted_sentiment: expected string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateSchemaFields(tt.record)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	invalid := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "label", Type: "string", Enum: []string{"yes", "no"}, Default: "maybe"}}}
+	if _, err := newRecordValidator(nil, invalid); err == nil || !strings.Contains(err.Error(), "field label default: value 'maybe' not in enum") {
+		t.Errorf("Expected a default outside the enum to be rejected, got %v", err)
+	}
+}
+
+func TestValidateSchemaFields_Pattern(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string", Pattern: "^[a-z0-9-]+$"}}}
+	validator := newTestValidator(t, schema)
+
+	if err := validator.validateSchemaFields(Record{"id": "review-42"}); err != nil {
+		t.Errorf("Expected a matching id to pass, got %v", err)
+	}
+	err := validator.validateSchemaFields(Record{"id": "Review_42"})
+	if err == nil || !strings.Contains(err.Error(), "field id: value 'Review_42' does not match pattern ^[a-z0-9-]+$") {
+		t.Errorf("Expected a pattern mismatch naming the field and value, got %v", err)
+	}
+
+	invalid := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "id", Type: "string", Pattern: "[a-z"}}}
+	if _, err := NewJSONSource(map[string]interface{}{"path": "data.json"}, invalid); err == nil || !strings.Contains(err.Error(), "field id: invalid pattern [a-z") {
+		t.Errorf("Expected an invalid pattern to fail source creation, got %v", err)
+	}
+}
+
+func TestValidateSchemaFields_Bounds(t *testing.T) {
+	zero, one, ten := 0.0, 1.0, 10.0
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "score", Type: "number", Min: &zero, Max: &one},
+		{Name: "votes", Type: "integer", Max: &ten, Optional: true},
+	}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name    string
+		record  Record
+		wantErr string
+	}{
+		{"in range", Record{"score": 0.5, "votes": 3.0}, ""},
+		{"at bounds", Record{"score": 1.0, "votes": 10}, ""},
+		{"above max", Record{"score": 1.5}, "field score: 1.5 exceeds max 1"},
+		{"below min", Record{"score": -0.2}, "field score: -0.2 is below min 0"},
+		{"integer above max", Record{"score": 0.0, "votes": int64(11)}, "field votes: 11 exceeds max 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateSchemaFields(tt.record)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaFields_Lengths(t *testing.T) {
+	two, five := 2, 5
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "summary", Type: "string", MinLength: &two, MaxLength: &five}}}
+
+	validator := newTestValidator(t, schema)
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"in range", "good", ""},
+		{"runes not bytes", "café!", ""},
+		{"too short", "a", "field summary: length 1 is below min_length 2"},
+		{"too long", "great!", "field summary: length 6 exceeds max_length 5"},
+		{"multibyte too long", "日本語のテキスト", "field summary: length 8 exceeds max_length 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateSchemaFields(Record{"summary": tt.value})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRecordValidator_Defaults(t *testing.T) {
+	schema := config.SchemaConfig{Fields: []config.FieldConfig{
+		{Name: "text", Type: "string"},
+		{Name: "dataset_version", Type: "string", Default: "v1"},
+		{Name: "weight", Type: "number", Default: 1},
+		{Name: "tags", Type: "array", Default: []interface{}{"legacy"}},
+		{Name: "meta.lang", Type: "string", Default: "en"},
+	}}
+	fsys := fstest.MapFS{
+		"data.jsonl": {Data: []byte("{\"text\": \"old\"}\n{\"text\": \"new\", \"dataset_version\": \"v2\", \"weight\": 3, \"meta\": {\"lang\": \"de\"}}\n{\"text\": \"odd\", \"meta\": {}}\n")},
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "data.jsonl", map[string]interface{}{"mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	old := records[0]
+	if old["dataset_version"] != "v1" || old["weight"] != 1.0 || old["meta"].(map[string]interface{})["lang"] != "en" {
+		t.Errorf("Expected the defaults filled in as JSON values, got %v", old)
+	}
+	if records[1]["dataset_version"] != "v2" || records[1]["weight"] != 3.0 || records[1]["meta"].(map[string]interface{})["lang"] != "de" {
+		t.Errorf("Expected present fields to keep their values, got %v", records[1])
+	}
+	if records[2]["meta"].(map[string]interface{})["lang"] != "en" {
+		t.Errorf("Expected the default set inside the existing parent, got %v", records[2])
+	}
+
+	// Every record gets its own copy of a default
+	old["tags"].([]interface{})[0] = "changed"
+	if records[2]["tags"].([]interface{})[0] != "legacy" {
+		t.Errorf("Expected defaults not to be shared between records, got %v", records[2]["tags"])
+	}
+	// Defaults were not seen in the data
+	if source.ObservedTypes()["dataset_version"]["string"] != 1 {
+		t.Errorf("Expected only the read dataset_version to be observed, got %v", source.ObservedTypes()["dataset_version"])
+	}
+
+	invalid := config.SchemaConfig{Fields: []config.FieldConfig{{Name: "weight", Type: "number", Default: "heavy"}}}
+	if _, err := newRecordValidator(nil, invalid); err == nil || !strings.Contains(err.Error(), "field weight default: expected number") {
+		t.Errorf("Expected a mistyped default to be rejected, got %v", err)
+	}
+}
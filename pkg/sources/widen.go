@@ -0,0 +1,103 @@
+package sources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+// typeObserver records which JSON types each schema field was seen with
+type typeObserver struct {
+	mu       sync.Mutex                // records may be observed from concurrent file reads
+	observed map[string]map[string]int // field -> JSON type -> count
+}
+
+func newTypeObserver() *typeObserver {
+	return &typeObserver{observed: make(map[string]map[string]int)}
+}
+
+// observe records the types of every declared field present in record
+func (o *typeObserver) observe(record Record, schema config.SchemaConfig) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, field := range schema.Fields {
+		value, exists := LookupField(record, field.Name)
+		if !exists {
+			continue
+		}
+		if o.observed[field.Name] == nil {
+			o.observed[field.Name] = make(map[string]int)
+		}
+		o.observed[field.Name][jsonTypeName(value)]++
+	}
+}
+
+// widenRecord coerces declared fields to their schema type in place
+func widenRecord(record Record, schema config.SchemaConfig) error {
+	for _, field := range schema.Fields {
+		value, exists := record[field.Name]
+		if !exists || value == nil {
+			continue
+		}
+
+		widened, err := widenValue(value, field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		record[field.Name] = widened
+	}
+	return nil
+}
+
+// widenValue converts scalar values between string, number and boolean
+func widenValue(value interface{}, targetType string) (interface{}, error) {
+	switch targetType {
+	case "string":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case "number", "integer":
+		if s, ok := value.(string); ok {
+			n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot widen %q to number", s)
+			}
+			return n, nil
+		}
+	case "boolean":
+		if s, ok := value.(string); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("cannot widen %q to boolean", s)
+			}
+			return b, nil
+		}
+	}
+	return value, nil
+}
+
+// jsonTypeName names the JSON type of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, float32, int, int32, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
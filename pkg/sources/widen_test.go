@@ -0,0 +1,80 @@
+package sources
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adhaamehab/meval.ai/pkg/config"
+)
+
+func TestJSONSource_WidenTypes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mixed.jsonl": {Data: []byte(`{"id": 42, "score": "0.5"}
+{"id": "43", "score": 0.75}
+{"id": 44, "score": " 1 "}
+`)},
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{
+			{Name: "id", Type: "string"},
+			{Name: "score", Type: "number"},
+		},
+	}
+
+	// Strict validation fails on the mixed types
+	strict, err := NewJSONSourceFromFS(fsys, "mixed.jsonl", map[string]interface{}{"mode": "lines"}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+	if _, err := strict.Read(context.Background()); err == nil {
+		t.Fatal("Expected mixed types to fail without widen_types, got nil")
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "mixed.jsonl", map[string]interface{}{"mode": "lines", "widen_types": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	records, err := source.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+
+	for i, want := range []string{"42", "43", "44"} {
+		if records[i]["id"] != want {
+			t.Errorf("Expected id %q at record %d, got %#v", want, i, records[i]["id"])
+		}
+	}
+
+	if records[0]["score"] != 0.5 || records[2]["score"] != 1.0 {
+		t.Errorf("Expected scores widened to numbers, got %#v and %#v", records[0]["score"], records[2]["score"])
+	}
+
+	observed := source.ObservedTypes()["id"]
+	if observed["number"] != 2 || observed["string"] != 1 {
+		t.Errorf("Expected id observed as 2 numbers and 1 string, got %v", observed)
+	}
+}
+
+func TestJSONSource_WidenTypesUnconvertible(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.jsonl": {Data: []byte(`{"score": "n/a"}`)},
+	}
+
+	schema := config.SchemaConfig{
+		Fields: []config.FieldConfig{{Name: "score", Type: "number"}},
+	}
+
+	source, err := NewJSONSourceFromFS(fsys, "bad.jsonl", map[string]interface{}{"mode": "lines", "widen_types": true}, schema)
+	if err != nil {
+		t.Fatalf("Failed to create JSON source: %v", err)
+	}
+
+	_, err = source.Read(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `"n/a"`) {
+		t.Errorf("Expected error reporting the unconvertible value, got %v", err)
+	}
+}